@@ -0,0 +1,404 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"time"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/config"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/database"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/repository"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/security"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/userdomain"
+	"gopkg.in/yaml.v3"
+)
+
+// app carries what the commands share
+type app struct {
+	cfg       *config.Config
+	db        *database.DB
+	users     *repository.UserRepository
+	tokens    *repository.RefreshTokenRepository
+	passwords *security.PasswordService
+	out       io.Writer
+	json      bool
+	now       func() time.Time
+}
+
+func (a *app) useDB(db *database.DB) {
+	a.db = db
+	a.users = repository.NewUserRepository(db)
+	a.tokens = repository.NewRefreshTokenRepository(db)
+}
+
+// command is one admin subcommand
+type command struct {
+	name    string
+	usage   string
+	summary string
+	needsDB bool
+	run     func(ctx context.Context, a *app, args []string) error
+}
+
+var commands = []command{
+	{"create-user", "create-user -email E -name N [-password P] [-admin]", "create an account; prints a generated password if none is given", true, createUser},
+	{"promote", "promote [-role admin|user] <email>", "change a user's role (admin by default)", true, promote},
+	{"reset-password", "reset-password [-password P] <email>", "set a new password and end every session", true, resetPassword},
+	{"revoke-sessions", "revoke-sessions <email>", "revoke every refresh token of a user", true, revokeSessions},
+	{"seed", "seed [-force]", "create demo accounts (refused in production without -force)", true, seed},
+	{"config", "config", "print the effective configuration with secrets redacted", false, printConfig},
+	{"purge-deleted", "purge-deleted [-older-than 720h]", "permanently remove users soft-deleted before the cutoff", true, purgeDeleted},
+	{"vacuum", "vacuum", "rebuild the SQLite database file to reclaim space", true, vacuum},
+}
+
+func lookup(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// flags returns a flag set for a command that also accepts -json
+func (a *app) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.BoolVar(&a.json, "json", a.json, "print results as JSON")
+	return fs
+}
+
+// print writes v as indented JSON with -json, otherwise the formatted text
+func (a *app) print(v any, format string, args ...any) error {
+	if a.json {
+		enc := json.NewEncoder(a.out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	_, err := fmt.Fprintf(a.out, format+"\n", args...)
+	return err
+}
+
+// userByEmail looks up a live user, with a readable error when there is none
+func (a *app) userByEmail(ctx context.Context, email string) (*userdomain.User, error) {
+	u, err := a.users.GetByEmail(ctx, email)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("no user with email %q", email)
+	}
+	return u, err
+}
+
+// oneArg parses fs and returns its single positional argument
+func oneArg(fs *flag.FlagSet, args []string, what string) (string, error) {
+	if err := fs.Parse(args); err != nil {
+		return "", err
+	}
+	if fs.NArg() != 1 {
+		return "", fmt.Errorf("usage: %s [flags] <%s>", fs.Name(), what)
+	}
+	return fs.Arg(0), nil
+}
+
+type userResult struct {
+	User *userdomain.User `json:"user"`
+	// Password is only set when it was generated
+	Password string `json:"password,omitempty"`
+}
+
+func createUser(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("create-user")
+	email := fs.String("email", "", "email address")
+	name := fs.String("name", "", "display name")
+	password := fs.String("password", "", "password; generated when empty")
+	admin := fs.Bool("admin", false, "give the user the admin role")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	res := userResult{}
+	if *password == "" {
+		generated, err := generatePassword()
+		if err != nil {
+			return err
+		}
+		*password, res.Password = generated, generated
+	}
+
+	u, err := userdomain.NewUser(*email, *name, *password)
+	if err != nil {
+		return err
+	}
+	if *admin {
+		u.Role = userdomain.RoleAdmin
+	}
+	if u.PasswordHash, err = a.passwords.HashPassword(*password); err != nil {
+		return err
+	}
+	if err := a.users.Create(ctx, u); err != nil {
+		if errors.Is(err, repository.ErrDuplicateEmail) {
+			return fmt.Errorf("a user with email %q already exists", u.Email)
+		}
+		return err
+	}
+
+	res.User = u
+	text := fmt.Sprintf("✅ Created user %d %s (%s)", u.ID, u.Email, u.Role)
+	if res.Password != "" {
+		text += "\n🔑 Generated password: " + res.Password
+	}
+	return a.print(res, "%s", text)
+}
+
+func promote(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("promote")
+	role := fs.String("role", userdomain.RoleAdmin, "role to give the user")
+	email, err := oneArg(fs, args, "email")
+	if err != nil {
+		return err
+	}
+	if err := userdomain.ValidateRole(*role); err != nil {
+		return err
+	}
+
+	u, err := a.userByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if err := a.users.UpdateRole(ctx, u.ID, *role); err != nil {
+		return err
+	}
+	u.Role = *role
+	// Access tokens carry roles, so the change applies once the user's current token expires
+	return a.print(userResult{User: u}, "✅ %s is now %s", u.Email, u.Role)
+}
+
+type resetResult struct {
+	UserID          int    `json:"user_id"`
+	Email           string `json:"email"`
+	Password        string `json:"password,omitempty"`
+	RevokedSessions int64  `json:"revoked_sessions"`
+}
+
+func resetPassword(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("reset-password")
+	password := fs.String("password", "", "new password; generated when empty")
+	email, err := oneArg(fs, args, "email")
+	if err != nil {
+		return err
+	}
+
+	u, err := a.userByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	res := resetResult{UserID: u.ID, Email: u.Email}
+	if *password == "" {
+		if *password, err = generatePassword(); err != nil {
+			return err
+		}
+		res.Password = *password
+	}
+	if err := userdomain.ValidatePassword(*password); err != nil {
+		return err
+	}
+
+	hash, err := a.passwords.HashPassword(*password)
+	if err != nil {
+		return err
+	}
+	if err := a.users.UpdatePassword(ctx, u.ID, hash); err != nil {
+		return err
+	}
+	if res.RevokedSessions, err = a.tokens.RevokeAllForUser(ctx, u.ID, a.now()); err != nil {
+		return err
+	}
+
+	text := fmt.Sprintf("✅ Password of %s reset, %d session(s) revoked", u.Email, res.RevokedSessions)
+	if res.Password != "" {
+		text += "\n🔑 Generated password: " + res.Password
+	}
+	return a.print(res, "%s", text)
+}
+
+type revokeResult struct {
+	UserID  int    `json:"user_id"`
+	Email   string `json:"email"`
+	Revoked int64  `json:"revoked"`
+}
+
+func revokeSessions(ctx context.Context, a *app, args []string) error {
+	email, err := oneArg(a.flags("revoke-sessions"), args, "email")
+	if err != nil {
+		return err
+	}
+	u, err := a.userByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	n, err := a.tokens.RevokeAllForUser(ctx, u.ID, a.now())
+	if err != nil {
+		return err
+	}
+	return a.print(revokeResult{UserID: u.ID, Email: u.Email, Revoked: n},
+		"✅ Revoked %d session(s) of %s", n, u.Email)
+}
+
+// demoPassword is the password of every seeded account
+const demoPassword = "Password123"
+
+var demoUsers = []struct {
+	email, name, role string
+}{
+	{"admin@example.com", "Admin", userdomain.RoleAdmin},
+	{"alice@example.com", "Alice", userdomain.RoleUser},
+	{"bob@example.com", "Bob", userdomain.RoleUser},
+}
+
+type seedResult struct {
+	Created  []string `json:"created"`
+	Existing []string `json:"existing"`
+	Password string   `json:"password"`
+}
+
+func seed(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("seed")
+	force := fs.Bool("force", false, "seed even when env is production")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if a.cfg.IsProduction() && !*force {
+		return errors.New("refusing to seed demo accounts with known passwords in production; pass -force to override")
+	}
+
+	hash, err := a.passwords.HashPassword(demoPassword)
+	if err != nil {
+		return err
+	}
+	res := seedResult{Created: []string{}, Existing: []string{}, Password: demoPassword}
+	for _, d := range demoUsers {
+		u, err := userdomain.NewUser(d.email, d.name, demoPassword)
+		if err != nil {
+			return err
+		}
+		u.Role, u.PasswordHash = d.role, hash
+		switch err := a.users.Create(ctx, u); {
+		case errors.Is(err, repository.ErrDuplicateEmail):
+			res.Existing = append(res.Existing, u.Email)
+		case err != nil:
+			return err
+		default:
+			res.Created = append(res.Created, u.Email)
+		}
+	}
+	return a.print(res, "✅ Seeded %d account(s), %d already existed; password for all: %s",
+		len(res.Created), len(res.Existing), demoPassword)
+}
+
+func printConfig(_ context.Context, a *app, args []string) error {
+	if err := a.flags("config").Parse(args); err != nil {
+		return err
+	}
+	// Go through YAML so JSON output uses the same keys as config files
+	raw, err := yaml.Marshal(a.cfg.Redacted())
+	if err != nil {
+		return err
+	}
+	if !a.json {
+		_, err := a.out.Write(raw)
+		return err
+	}
+	var doc map[string]any
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return err
+	}
+	return a.print(doc, "")
+}
+
+type purgeResult struct {
+	Purged int64     `json:"purged"`
+	Cutoff time.Time `json:"cutoff"`
+}
+
+func purgeDeleted(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("purge-deleted")
+	olderThan := fs.Duration("older-than", 30*24*time.Hour, "only users deleted at least this long ago")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *olderThan < 0 {
+		return errors.New("-older-than must not be negative")
+	}
+
+	cutoff := a.now().UTC().Add(-*olderThan)
+	n, err := a.users.PurgeDeleted(ctx, cutoff)
+	if err != nil {
+		return err
+	}
+	return a.print(purgeResult{Purged: n, Cutoff: cutoff},
+		"✅ Purged %d user(s) deleted before %s", n, cutoff.Format(time.RFC3339))
+}
+
+type vacuumResult struct {
+	BytesBefore int64 `json:"bytes_before"`
+	BytesAfter  int64 `json:"bytes_after"`
+}
+
+func vacuum(ctx context.Context, a *app, args []string) error {
+	if err := a.flags("vacuum").Parse(args); err != nil {
+		return err
+	}
+	if a.db.Dialect != database.SQLite {
+		return errors.New("vacuum only applies to SQLite; Postgres reclaims space with autovacuum")
+	}
+
+	before, err := sqliteSize(ctx, a.db)
+	if err != nil {
+		return err
+	}
+	if _, err := a.db.ExecContext(ctx, "VACUUM"); err != nil {
+		return fmt.Errorf("vacuum: %w", err)
+	}
+	after, err := sqliteSize(ctx, a.db)
+	if err != nil {
+		return err
+	}
+	return a.print(vacuumResult{BytesBefore: before, BytesAfter: after},
+		"✅ Vacuumed database: %d -> %d bytes", before, after)
+}
+
+func sqliteSize(ctx context.Context, db *database.DB) (int64, error) {
+	var pages, pageSize int64
+	if err := db.QueryRowContext(ctx, "PRAGMA page_count").Scan(&pages); err != nil {
+		return 0, fmt.Errorf("page count: %w", err)
+	}
+	if err := db.QueryRowContext(ctx, "PRAGMA page_size").Scan(&pageSize); err != nil {
+		return 0, fmt.Errorf("page size: %w", err)
+	}
+	return pages * pageSize, nil
+}
+
+const passwordAlphabet = "abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// generatePassword returns a random 16-character password that passes
+// userdomain.ValidatePassword
+func generatePassword() (string, error) {
+	max := big.NewInt(int64(len(passwordAlphabet)))
+	for {
+		b := make([]byte, 16)
+		for i := range b {
+			n, err := rand.Int(rand.Reader, max)
+			if err != nil {
+				return "", fmt.Errorf("generate password: %w", err)
+			}
+			b[i] = passwordAlphabet[n.Int64()]
+		}
+		if userdomain.ValidatePassword(string(b)) == nil {
+			return string(b), nil
+		}
+	}
+}
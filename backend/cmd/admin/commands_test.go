@@ -0,0 +1,326 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/config"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/database"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/database/dbtest"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/repository"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/security"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/userdomain"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var testNow = time.Date(2025, 8, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestApp(t *testing.T) (*app, *bytes.Buffer) {
+	t.Helper()
+	db := dbtest.Open(t)
+
+	out := &bytes.Buffer{}
+	a := &app{
+		cfg:       config.Default(),
+		passwords: security.NewPasswordServiceWithCost(bcrypt.MinCost),
+		out:       out,
+		now:       func() time.Time { return testNow },
+	}
+	a.useDB(db)
+	return a, out
+}
+
+// runJSON runs a command with -json and decodes its output into v
+func runJSON(t *testing.T, a *app, out *bytes.Buffer, v any, args ...string) {
+	t.Helper()
+	out.Reset()
+	cmd, ok := lookup(args[0])
+	if !ok {
+		t.Fatalf("Unknown command %q", args[0])
+	}
+	if err := cmd.run(context.Background(), a, append([]string{"-json"}, args[1:]...)); err != nil {
+		t.Fatalf("%s error = %v", args[0], err)
+	}
+	if err := json.Unmarshal(out.Bytes(), v); err != nil {
+		t.Fatalf("Expected JSON output, got %q: %v", out.String(), err)
+	}
+}
+
+func runErr(a *app, args ...string) error {
+	cmd, _ := lookup(args[0])
+	return cmd.run(context.Background(), a, args[1:])
+}
+
+func addSession(t *testing.T, a *app, id string, userID int) {
+	t.Helper()
+	err := a.tokens.Create(context.Background(), &repository.RefreshToken{ID: id, UserID: userID, ExpiresAt: testNow.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("Create token error = %v", err)
+	}
+}
+
+func TestCreateUser(t *testing.T) {
+	a, out := newTestApp(t)
+	ctx := context.Background()
+
+	var res struct {
+		User     userdomain.User `json:"user"`
+		Password string          `json:"password"`
+	}
+	runJSON(t, a, out, &res, "create-user", "-email", "Root@Example.com", "-name", "Root", "-admin")
+	if res.User.Email != "root@example.com" || res.User.Role != userdomain.RoleAdmin {
+		t.Errorf("Expected admin root@example.com, got %+v", res.User)
+	}
+	if userdomain.ValidatePassword(res.Password) != nil {
+		t.Errorf("Expected a valid generated password, got %q", res.Password)
+	}
+	stored, err := a.users.GetByEmail(ctx, "root@example.com")
+	if err != nil {
+		t.Fatalf("GetByEmail() error = %v", err)
+	}
+	if !a.passwords.VerifyPassword(res.Password, stored.PasswordHash) {
+		t.Error("Expected the generated password to be stored")
+	}
+
+	res.Password = ""
+	runJSON(t, a, out, &res, "create-user", "-email", "user@example.com", "-name", "User", "-password", "Password123")
+	if res.Password != "" || res.User.Role != userdomain.RoleUser {
+		t.Errorf("Expected a plain user without a printed password, got %+v / %q", res.User, res.Password)
+	}
+
+	if err := runErr(a, "create-user", "-email", "root@example.com", "-name", "Again"); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected duplicate email error, got %v", err)
+	}
+	if err := runErr(a, "create-user", "-email", "bad", "-name", "Bad", "-password", "weak"); err == nil {
+		t.Error("Expected validation error")
+	}
+
+	a.json = false
+	out.Reset()
+	if err := runErr(a, "create-user", "-email", "text@example.com", "-name", "Text", "-password", "Password123"); err != nil {
+		t.Fatalf("create-user error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Created user") || strings.Contains(out.String(), "Generated password") {
+		t.Errorf("Expected human-readable output, got %q", out.String())
+	}
+}
+
+func TestPromote(t *testing.T) {
+	a, out := newTestApp(t)
+	var created userResult
+	runJSON(t, a, out, &created, "create-user", "-email", "alice@example.com", "-name", "Alice", "-password", "Password123")
+
+	var res userResult
+	runJSON(t, a, out, &res, "promote", "alice@example.com")
+	if res.User.Role != userdomain.RoleAdmin {
+		t.Errorf("Expected admin role, got %q", res.User.Role)
+	}
+	stored, _ := a.users.GetByEmail(context.Background(), "alice@example.com")
+	if stored.Role != userdomain.RoleAdmin {
+		t.Errorf("Expected stored admin role, got %q", stored.Role)
+	}
+
+	runJSON(t, a, out, &res, "promote", "-role", "user", "alice@example.com")
+	if res.User.Role != userdomain.RoleUser {
+		t.Errorf("Expected user role, got %q", res.User.Role)
+	}
+
+	if err := runErr(a, "promote", "-role", "owner", "alice@example.com"); err == nil {
+		t.Error("Expected error for unknown role")
+	}
+	if err := runErr(a, "promote", "nobody@example.com"); err == nil || !strings.Contains(err.Error(), "no user") {
+		t.Errorf("Expected unknown user error, got %v", err)
+	}
+	if err := runErr(a, "promote"); err == nil {
+		t.Error("Expected usage error without email")
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	a, out := newTestApp(t)
+	var created userResult
+	runJSON(t, a, out, &created, "create-user", "-email", "alice@example.com", "-name", "Alice", "-password", "Password123")
+	addSession(t, a, "t1", created.User.ID)
+	addSession(t, a, "t2", created.User.ID)
+
+	var res resetResult
+	runJSON(t, a, out, &res, "reset-password", "-password", "NewPassword1", "alice@example.com")
+	if res.RevokedSessions != 2 || res.Password != "" {
+		t.Errorf("Expected 2 revoked sessions and no printed password, got %+v", res)
+	}
+	stored, _ := a.users.GetByEmail(context.Background(), "alice@example.com")
+	if !a.passwords.VerifyPassword("NewPassword1", stored.PasswordHash) {
+		t.Error("Expected the new password to be stored")
+	}
+	if a.passwords.VerifyPassword("Password123", stored.PasswordHash) {
+		t.Error("Expected the old password to stop working")
+	}
+
+	runJSON(t, a, out, &res, "reset-password", "alice@example.com")
+	stored, _ = a.users.GetByEmail(context.Background(), "alice@example.com")
+	if res.Password == "" || !a.passwords.VerifyPassword(res.Password, stored.PasswordHash) {
+		t.Errorf("Expected a stored generated password, got %q", res.Password)
+	}
+
+	if err := runErr(a, "reset-password", "-password", "short", "alice@example.com"); err == nil {
+		t.Error("Expected weak password to be rejected")
+	}
+}
+
+func TestRevokeSessions(t *testing.T) {
+	a, out := newTestApp(t)
+	var created userResult
+	runJSON(t, a, out, &created, "create-user", "-email", "alice@example.com", "-name", "Alice", "-password", "Password123")
+	addSession(t, a, "t1", created.User.ID)
+
+	var res revokeResult
+	runJSON(t, a, out, &res, "revoke-sessions", "alice@example.com")
+	if res.Revoked != 1 || res.UserID != created.User.ID {
+		t.Errorf("Expected 1 revoked session, got %+v", res)
+	}
+	token, err := a.tokens.Get(context.Background(), "t1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if token.Active(testNow) {
+		t.Error("Expected the session to be revoked")
+	}
+
+	runJSON(t, a, out, &res, "revoke-sessions", "alice@example.com")
+	if res.Revoked != 0 {
+		t.Errorf("Expected nothing left to revoke, got %d", res.Revoked)
+	}
+}
+
+func TestSeed(t *testing.T) {
+	a, out := newTestApp(t)
+
+	var res seedResult
+	runJSON(t, a, out, &res, "seed")
+	if len(res.Created) != len(demoUsers) || len(res.Existing) != 0 {
+		t.Errorf("Expected %d created accounts, got %+v", len(demoUsers), res)
+	}
+	admin, err := a.users.GetByEmail(context.Background(), "admin@example.com")
+	if err != nil {
+		t.Fatalf("GetByEmail() error = %v", err)
+	}
+	if admin.Role != userdomain.RoleAdmin || !a.passwords.VerifyPassword(demoPassword, admin.PasswordHash) {
+		t.Errorf("Expected seeded admin with the demo password, got %+v", admin)
+	}
+
+	runJSON(t, a, out, &res, "seed")
+	if len(res.Created) != 0 || len(res.Existing) != len(demoUsers) {
+		t.Errorf("Expected seeding to be idempotent, got %+v", res)
+	}
+
+	a.cfg.Env = "production"
+	if err := runErr(a, "seed"); err == nil {
+		t.Error("Expected seeding to be refused in production")
+	}
+	if err := runErr(a, "seed", "-force", "-json"); err != nil {
+		t.Errorf("Expected -force to allow seeding, got %v", err)
+	}
+}
+
+func TestPrintConfig(t *testing.T) {
+	a, out := newTestApp(t)
+	a.cfg.DatabaseURL = "postgres://app:s3cret@db:5432/app"
+	a.cfg.JWTSecret = "very-secret"
+
+	var res map[string]any
+	runJSON(t, a, out, &res, "config")
+	if res["jwt_secret"] != "REDACTED" || res["database_url"] != "postgres://app:REDACTED@db:5432/app" {
+		t.Errorf("Expected redacted secrets, got %v / %v", res["jwt_secret"], res["database_url"])
+	}
+	if _, ok := res["server"].(map[string]any); !ok {
+		t.Errorf("Expected nested sections keyed like config files, got %v", res)
+	}
+
+	a.json = false
+	out.Reset()
+	if err := runErr(a, "config"); err != nil {
+		t.Fatalf("config error = %v", err)
+	}
+	if strings.Contains(out.String(), "s3cret") || strings.Contains(out.String(), "very-secret") {
+		t.Errorf("Expected no secrets in YAML output, got %q", out.String())
+	}
+	if !strings.Contains(out.String(), "jwt_secret: REDACTED") {
+		t.Errorf("Expected YAML output, got %q", out.String())
+	}
+}
+
+func TestPurgeDeleted(t *testing.T) {
+	a, out := newTestApp(t)
+	ctx := context.Background()
+	var old, recent, live userResult
+	runJSON(t, a, out, &old, "create-user", "-email", "old@example.com", "-name", "Old", "-password", "Password123")
+	runJSON(t, a, out, &recent, "create-user", "-email", "recent@example.com", "-name", "Recent", "-password", "Password123")
+	runJSON(t, a, out, &live, "create-user", "-email", "live@example.com", "-name", "Live", "-password", "Password123")
+
+	softDelete := func(id int, at time.Time) {
+		if _, err := a.db.ExecContext(ctx, a.db.Rebind("UPDATE users SET deleted_at = ? WHERE id = ?"), at, id); err != nil {
+			t.Fatalf("Soft delete error = %v", err)
+		}
+	}
+	softDelete(old.User.ID, testNow.Add(-60*24*time.Hour))
+	softDelete(recent.User.ID, testNow.Add(-time.Hour))
+
+	var res purgeResult
+	runJSON(t, a, out, &res, "purge-deleted")
+	if res.Purged != 1 || !res.Cutoff.Equal(testNow.Add(-30*24*time.Hour)) {
+		t.Errorf("Expected 1 user purged before the default cutoff, got %+v", res)
+	}
+
+	runJSON(t, a, out, &res, "purge-deleted", "-older-than", "0s")
+	if res.Purged != 1 {
+		t.Errorf("Expected the recently deleted user to be purged, got %d", res.Purged)
+	}
+	var count int
+	if err := a.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users").Scan(&count); err != nil {
+		t.Fatalf("Count error = %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected only the live user to remain, got %d rows", count)
+	}
+
+	if err := runErr(a, "purge-deleted", "-older-than", "-1h"); err == nil {
+		t.Error("Expected negative duration to be rejected")
+	}
+}
+
+func TestVacuum(t *testing.T) {
+	a, out := newTestApp(t)
+
+	var res vacuumResult
+	runJSON(t, a, out, &res, "vacuum")
+	if res.BytesBefore <= 0 || res.BytesAfter <= 0 || res.BytesAfter > res.BytesBefore {
+		t.Errorf("Expected sizes to be reported and not grow, got %+v", res)
+	}
+
+	a.db.Dialect = database.Postgres
+	if err := runErr(a, "vacuum"); err == nil {
+		t.Error("Expected vacuum to be refused on Postgres")
+	}
+	a.db.Dialect = database.SQLite
+}
+
+func TestGeneratePassword(t *testing.T) {
+	seen := map[string]bool{}
+	for i := 0; i < 20; i++ {
+		pw, err := generatePassword()
+		if err != nil {
+			t.Fatalf("generatePassword() error = %v", err)
+		}
+		if len(pw) != 16 || userdomain.ValidatePassword(pw) != nil {
+			t.Errorf("Expected a valid 16-character password, got %q", pw)
+		}
+		if seen[pw] {
+			t.Errorf("Expected unique passwords, got %q twice", pw)
+		}
+		seen[pw] = true
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/config"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/database"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/security"
+)
+
+const usageHeader = `Usage: go run ./cmd/admin [flags] <command> [command flags] [args]
+
+Commands:
+`
+
+func main() {
+	configPath := flag.String("config", "", "path to YAML/TOML config file (defaults to $CONFIG_FILE)")
+	jsonOut := flag.Bool("json", false, "print results as JSON for scripting")
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usageHeader)
+		for _, cmd := range commands {
+			fmt.Fprintf(os.Stderr, "  %-52s %s\n", cmd.usage, cmd.summary)
+		}
+		fmt.Fprint(os.Stderr, "\nFlags:\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	cmd, ok := lookup(args[0])
+	if !ok {
+		log.Fatalf("Unknown command %q, see -h", args[0])
+	}
+
+	cfg, err := config.LoadFile(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	a := &app{
+		cfg:       cfg,
+		passwords: security.NewPasswordService(),
+		out:       os.Stdout,
+		json:      *jsonOut,
+		now:       time.Now,
+	}
+	if cmd.needsDB {
+		db, err := database.Open(cfg)
+		if err != nil {
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
+		defer db.Close()
+		a.useDB(db)
+	}
+
+	if err := cmd.run(context.Background(), a, args[1:]); err != nil {
+		if a.db != nil {
+			a.db.Close()
+		}
+		log.Fatal(err)
+	}
+}
@@ -1,41 +1,182 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/config"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/database"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/migrate"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/migrations"
 )
 
+const usage = `Usage: go run cmd/migrate/main.go [flags] <command> [args]
+
+Commands:
+  up               apply all pending migrations
+  down [n]         roll back the last n migrations (default 1)
+  to <version>     migrate up or down to exactly <version> (0 rolls back everything)
+  status           list migrations and whether they are applied
+  redo             roll back and re-apply the last migration
+  create <name>    write a new empty migration into -dir
+  force-unlock     clear a SQLite migration lock left by a crashed run
+
+Flags:
+`
+
 func main() {
-	if len(os.Args) < 2 {
-		log.Fatal("Usage: go run cmd/migrate/main.go [up|down]")
+	configPath := flag.String("config", "", "path to YAML/TOML config file (defaults to $CONFIG_FILE)")
+	dir := flag.String("dir", "migrations", "directory for new migration files (create only)")
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// create only touches the filesystem
+	if args[0] == "create" {
+		if len(args) < 2 {
+			log.Fatal("Usage: create <name>")
+		}
+		path, err := migrate.Create(*dir, args[1], time.Now())
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("✅ Created %s\n", path)
+		return
+	}
+
+	cfg, err := config.LoadFile(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	cfg := config.Load()
-	direction := os.Args[1]
+	db, err := database.Open(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	migrator, err := migrate.New(db, migrations.FS)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
 
-	switch direction {
+	if err := run(context.Background(), migrator, args); err != nil {
+		db.Close()
+		log.Fatal(err)
+	}
+}
+
+func run(ctx context.Context, migrator *migrate.Migrator, args []string) error {
+	switch args[0] {
 	case "up":
-		runMigrationsUp(cfg)
+		fmt.Println("🔄 Running migrations UP")
+		applied, err := migrator.Up(ctx)
+		printMigrations("⬆️ ", applied)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Migrations completed successfully (%d applied)\n", len(applied))
+
 	case "down":
-		runMigrationsDown(cfg)
+		n := 1
+		if len(args) > 1 {
+			var err error
+			if n, err = strconv.Atoi(args[1]); err != nil || n < 1 {
+				return fmt.Errorf("invalid count %q", args[1])
+			}
+		}
+		fmt.Printf("🔄 Rolling back %d migration(s)\n", n)
+		reverted, err := migrator.Down(ctx, n)
+		printMigrations("⬇️ ", reverted)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Migrations rollback completed successfully (%d reverted)\n", len(reverted))
+
+	case "to":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: to <version>")
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		fmt.Printf("🔄 Migrating to version %d\n", version)
+		changed, err := migrator.To(ctx, version)
+		printMigrations("🔁", changed)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Now at version %d\n", version)
+
+	case "redo":
+		redone, err := migrator.Redo(ctx)
+		if err != nil {
+			return err
+		}
+		if redone == nil {
+			fmt.Println("ℹ️  Nothing to redo")
+			return nil
+		}
+		fmt.Printf("✅ Redone %d_%s\n", redone.Version, redone.Name)
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		printStatus(statuses)
+
+	case "force-unlock":
+		cleared, err := migrator.ForceUnlock(ctx)
+		if err != nil {
+			return err
+		}
+		if !cleared {
+			fmt.Println("ℹ️  No migration lock to clear")
+			return nil
+		}
+		fmt.Println("✅ Migration lock cleared")
+
 	default:
-		log.Fatal("Invalid direction. Use 'up' or 'down'")
+		return fmt.Errorf("unknown command %q, see -h", args[0])
 	}
+	return nil
 }
 
-func runMigrationsUp(cfg *config.Config) {
-	fmt.Printf("🔄 Running migrations UP against %s\n", cfg.DatabaseURL)
-	// TODO: Implement actual migration logic
-	// This would typically use a migration library like golang-migrate
-	fmt.Println("✅ Migrations completed successfully")
+func printMigrations(prefix string, migrations []migrate.Migration) {
+	for _, m := range migrations {
+		fmt.Printf("  %s %d_%s\n", prefix, m.Version, m.Name)
+	}
 }
 
-func runMigrationsDown(cfg *config.Config) {
-	fmt.Printf("🔄 Running migrations DOWN against %s\n", cfg.DatabaseURL)
-	// TODO: Implement actual migration logic
-	// This would typically use a migration library like golang-migrate
-	fmt.Println("✅ Migrations rollback completed successfully")
+func printStatus(statuses []migrate.Status) {
+	fmt.Printf("%-16s %-10s %-20s %s\n", "VERSION", "STATE", "APPLIED AT", "NAME")
+	for _, st := range statuses {
+		state, appliedAt := "pending", "-"
+		if st.Applied {
+			state = "applied"
+			appliedAt = st.AppliedAt.Local().Format("2006-01-02 15:04:05")
+		}
+		switch {
+		case st.Missing:
+			state = "missing"
+		case st.Modified:
+			state = "modified"
+		}
+		fmt.Printf("%-16d %-10s %-20s %s\n", st.Version, state, appliedAt, st.Name)
+	}
 }
@@ -2,72 +2,432 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"log"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/apierror"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/audit"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/auth"
 	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/config"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/database"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/featureflags"
 	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/handlers"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/health"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/httpserver"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/idempotency"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/jobs"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/jwtservice"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/lifecycle"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/logging"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/metrics"
 	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/middleware"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/migrate"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/openapi"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/ratelimit"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/repository"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/security"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/version"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/migrations"
 )
 
 func main() {
+	configPath := flag.String("config", "", "path to YAML/TOML config file (defaults to $CONFIG_FILE)")
+	flag.Parse()
+
 	// Load configuration
-	cfg := config.Load()
+	cfg, err := config.LoadFile(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Refusing to start: %v", err)
+	}
+
+	// Structured logging; the standard log package goes through it as well
+	logger, err := logging.New(cfg.Log, os.Stdout)
+	if err != nil {
+		log.Fatalf("Failed to set up logging: %v", err)
+	}
+	slog.SetDefault(logger)
+
+	// Connect to the database
+	db, err := database.Open(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	// Subsystems register start/stop hooks; shutdown runs them in reverse
+	lc := lifecycle.New(lifecycle.Options{
+		DrainPeriod: cfg.Server.DrainPeriod,
+		StopTimeout: cfg.Server.ShutdownTimeout,
+		Logger:      logger,
+	})
+	lc.Append(lifecycle.Hook{
+		Name:     "database",
+		Priority: lifecycle.PriorityStorage,
+		Stop:     func(context.Context) error { return db.Close() },
+	})
+
+	migrator, err := migrate.New(db, migrations.FS)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	// Readiness checks
+	healthRegistry := health.NewRegistry()
+	healthRegistry.Register("database", 2*time.Second, health.DatabasePing(db))
+	healthRegistry.Register("migrations", 2*time.Second, health.MigrationVersion(migrator))
+	healthRegistry.Register("disk", time.Second, health.DiskSpace(os.TempDir(), 100<<20))
+	healthHandler := handlers.NewHealthHandler(healthRegistry)
+	lc.OnShutdown(func() { healthRegistry.SetReady(false) })
+
+	// Prometheus metrics
+	appMetrics := metrics.New()
+	appMetrics.RegisterDB("main", db.DB)
+
+	// Token service for authentication
+	jwtService, err := jwtservice.NewJWTService(cfg.JWTSecret, jwtservice.Options{
+		Issuer:          cfg.JWT.Issuer,
+		AccessTokenTTL:  cfg.JWT.AccessTokenTTL,
+		RefreshTokenTTL: cfg.JWT.RefreshTokenTTL,
+	})
+	if err != nil {
+		log.Fatalf("Failed to set up JWT: %v", err)
+	}
+
+	// Accounts and sessions
+	authHandler := handlers.NewAuthHandler(auth.NewService(
+		repository.NewUserRepository(db),
+		repository.NewRefreshTokenRepository(db),
+		security.NewPasswordService(),
+		jwtService,
+	))
+
+	// Feature flags from the config, with runtime overrides shared through the database
+	flags := featureFlags(cfg, db, lc, logger)
+	healthRegistry.RegisterInfo("feature_flags", func() any { return flags.Status() })
 
 	// Initialize Gin router
-	if cfg.Env == "production" {
+	if cfg.IsProduction() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	router := gin.New()
+	router, err := newRouter(cfg)
+	if err != nil {
+		log.Fatalf("Failed to configure router: %v", err)
+	}
 
 	// Add middleware
-	router.Use(gin.Logger())
-	router.Use(gin.Recovery())
-	router.Use(middleware.CORS())
+	router.Use(middleware.RequestID())
+	router.Use(middleware.RequestLogger(logger))
+	router.Use(appMetrics.Middleware())
+	router.Use(apierror.Recovery())
+	router.Use(apierror.Middleware())
+	router.Use(middleware.CORS(middleware.CORSFromConfig(cfg)))
+
+	// Unknown routes get the same problem+json errors as everything else
+	router.NoRoute(apierror.NotFoundHandler)
+
+	// Health check endpoints; /health is kept for the Docker HEALTHCHECK
+	router.GET("/health", healthHandler.Live)
+	router.GET("/health/live", healthHandler.Live)
+	router.GET("/health/ready", healthHandler.Ready)
 
-	// Health check endpoint
-	router.GET("/health", handlers.HealthCheck)
+	// Metrics endpoint, either on the public router or on its own admin listener
+	var adminServer *http.Server
+	if cfg.Metrics.Enabled {
+		if cfg.Metrics.AdminPort == "" {
+			router.GET(cfg.Metrics.Path, gin.WrapH(appMetrics.Handler()))
+		} else {
+			adminMux := http.NewServeMux()
+			adminMux.Handle(cfg.Metrics.Path, appMetrics.Handler())
+			adminServer = &http.Server{
+				Addr:              ":" + cfg.Metrics.AdminPort,
+				Handler:           adminMux,
+				ReadHeaderTimeout: 5 * time.Second,
+			}
+		}
+	}
 
-	// API routes
+	// Rate limiting; buckets for every route group share one store
+	rateLimitStore := ratelimit.NewMemoryStore(time.Minute)
+	lc.Append(lifecycle.Hook{
+		Name:     "rate-limit-store",
+		Priority: lifecycle.PriorityServices,
+		Stop:     func(context.Context) error { rateLimitStore.Close(); return nil },
+	})
+
+	// API routes. OptionalAuth runs before rate limiting so authenticated
+	// callers are limited per user; protected groups add RequireAuth/RequireRole.
 	api := router.Group("/api/v1")
-	{
-		api.GET("/ping", handlers.Ping)
-		// Add more routes as needed
+
+	// Compression wraps everything below it, so conditional GET sees the
+	// uncompressed body; idempotency stores the handler's headers from
+	// before compression and replays are compressed again on the way out
+	if cfg.Compression.Enabled {
+		compress := middleware.DefaultCompressOptions()
+		compress.MinSize = cfg.Compression.MinSize
+		compress.Brotli = cfg.Compression.Brotli
+		api.Use(middleware.Compress(compress))
+	}
+	api.Use(middleware.ConditionalGET())
+	if cfg.Server.MaxBodyBytes > 0 {
+		api.Use(middleware.MaxBodySize(int64(cfg.Server.MaxBodyBytes)))
+	}
+	api.Use(middleware.OptionalAuth(jwtService))
+	api.Use(middleware.FeatureFlags(flags))
+	if cfg.RateLimit.Enabled {
+		api.Use(middleware.RateLimit(middleware.RateLimitOptions{
+			Name:  "api",
+			Limit: ratelimit.PerMinute(cfg.RateLimit.API.RequestsPerMinute, cfg.RateLimit.API.Burst),
+			Store: rateLimitStore,
+		}))
 	}
 
-	// Create HTTP server
-	server := &http.Server{
-		Addr:    ":" + cfg.Port,
-		Handler: router,
+	// Idempotency-Key replay for unsafe methods; after auth so keys are scoped per user
+	if cfg.Idempotency.Enabled {
+		api.Use(middleware.Idempotency(middleware.IdempotencyOptions{
+			Store: idempotencyStore(cfg, db, lc),
+			TTL:   cfg.Idempotency.TTL,
+		}))
 	}
 
-	// Start server in a goroutine
-	go func() {
-		log.Printf("🚀 Server starting on port %s", cfg.Port)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start server: %v", err)
-		}
-	}()
+	// Audit log of mutating requests; innermost, so idempotent replays and
+	// requests rejected by the rate limiter are not recorded
+	if cfg.Audit.Enabled {
+		api.Use(middleware.Audit(auditRecorder(cfg, db, lc, appMetrics, logger)))
+	}
 
-	// Wait for interrupt signal to gracefully shutdown the server
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-	log.Println("🛑 Shutting down server...")
+	deps := apiDeps{jwt: jwtService, auth: authHandler, flags: handlers.NewFlagsHandler(flags)}
+	if cfg.Audit.Enabled {
+		deps.audit = handlers.NewAuditHandler(audit.NewStore(db))
+	}
+	if cfg.Jobs.Enabled {
+		deps.jobs = handlers.NewJobsHandler(jobScheduler(cfg, db, lc, logger))
+	}
+	if cfg.RateLimit.Enabled {
+		deps.authRateLimit = middleware.RateLimit(middleware.RateLimitOptions{
+			Name:  "auth",
+			Limit: ratelimit.PerMinute(cfg.RateLimit.Auth.RequestsPerMinute, cfg.RateLimit.Auth.Burst),
+			Store: rateLimitStore,
+			Key:   middleware.KeyByIP,
+		})
+	}
 
-	// Give outstanding requests 10 seconds to complete
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	// Every /api/v1 route is registered through the spec so the docs stay complete
+	spec := openapi.NewSpec("Course Backend API", version.Version, api.BasePath())
+	registerAPIRoutes(spec.Group(api), deps)
+	registerDocs(api, spec)
 
-	if err := server.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+	// Create HTTP server; TLS, HTTP/2 and h2c come from the server config
+	server, certs, err := httpserver.New(":"+cfg.Port, cfg.Server, router)
+	if err != nil {
+		log.Fatalf("Failed to set up HTTP server: %v", err)
+	}
+	lc.Append(serverHook("http", server))
+	if certs != nil {
+		lc.Append(certWatchHook(certs, cfg.Server.TLS.ReloadInterval, logger))
+		if cfg.Server.TLS.RedirectPort != "" {
+			lc.Append(serverHook("https-redirect", httpserver.NewRedirect(":"+cfg.Server.TLS.RedirectPort, cfg.Port)))
+		}
+	}
+	if adminServer != nil {
+		lc.Append(serverHook("metrics-http", adminServer))
 	}
 
+	log.Printf("🚀 Server %s (%s) starting on port %s", version.Version, version.Commit, cfg.Port)
+	if err := lc.Run(context.Background()); err != nil {
+		log.Fatalf("Server stopped with errors: %v", err)
+	}
 	log.Println("✅ Server exited")
 }
+
+// newRouter creates the engine. Only the configured proxies may set the
+// client IP through X-Forwarded-For; with none, ClientIP is the peer address.
+func newRouter(cfg *config.Config) (*gin.Engine, error) {
+	router := gin.New()
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("trusted proxies: %w", err)
+	}
+	return router, nil
+}
+
+// serverHook binds srv's address on start, so a taken port fails startup,
+// and shuts it down gracefully on stop
+func serverHook(name string, srv *http.Server) lifecycle.Hook {
+	return lifecycle.Hook{
+		Name:     name,
+		Priority: lifecycle.PriorityServers,
+		Start: func(context.Context) error {
+			ln, err := net.Listen("tcp", srv.Addr)
+			if err != nil {
+				return err
+			}
+			go func() {
+				if err := httpserver.Serve(srv, ln); err != nil && err != http.ErrServerClosed {
+					log.Fatalf("Server %s failed: %v", name, err)
+				}
+			}()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			log.Printf("🛑 Stopping %s listener...", name)
+			return srv.Shutdown(ctx)
+		},
+	}
+}
+
+// certWatchHook reloads renewed TLS certificates from disk while the server runs
+func certWatchHook(certs *httpserver.CertReloader, interval time.Duration, logger *slog.Logger) lifecycle.Hook {
+	ctx, cancel := context.WithCancel(context.Background())
+	return lifecycle.Hook{
+		Name:     "tls-cert-watch",
+		Priority: lifecycle.PriorityServices,
+		Start: func(context.Context) error {
+			go certs.Watch(ctx, interval, func(changed bool, err error) {
+				if err != nil {
+					logger.Error("TLS certificate reload failed, keeping the current one", slog.Any("error", err))
+					return
+				}
+				logger.Info("TLS certificate reloaded")
+			})
+			return nil
+		},
+		Stop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	}
+}
+
+// idempotencyStore builds the configured store and registers its cleanup with lc
+func idempotencyStore(cfg *config.Config, db *database.DB, lc *lifecycle.Manager) idempotency.Store {
+	if cfg.Idempotency.Store == "memory" {
+		store := idempotency.NewMemoryStore(time.Minute)
+		lc.Append(lifecycle.Hook{
+			Name:     "idempotency-store",
+			Priority: lifecycle.PriorityServices,
+			Stop:     func(context.Context) error { store.Close(); return nil },
+		})
+		return store
+	}
+
+	store := idempotency.NewSQLStore(db)
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(lifecycle.Hook{
+		Name:     "idempotency-cleanup",
+		Priority: lifecycle.PriorityServices,
+		Start: func(context.Context) error {
+			go func() {
+				ticker := time.NewTicker(time.Hour)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case now := <-ticker.C:
+						if _, err := store.DeleteExpired(ctx, now); err != nil {
+							slog.Error("idempotency cleanup failed", slog.Any("error", err))
+						}
+					}
+				}
+			}()
+			return nil
+		},
+		Stop: func(context.Context) error { cancel(); return nil },
+	})
+	return store
+}
+
+// jobScheduler starts the background job workers with the lifecycle, so
+// shutdown waits for running jobs, and registers the built-in periodic jobs
+func jobScheduler(cfg *config.Config, db *database.DB, lc *lifecycle.Manager, logger *slog.Logger) *jobs.Store {
+	store := jobs.NewStore(db)
+	scheduler := jobs.New(store, jobs.Options{
+		Workers:      cfg.Jobs.Workers,
+		PollInterval: cfg.Jobs.PollInterval,
+		MaxAttempts:  cfg.Jobs.MaxAttempts,
+		BackoffBase:  cfg.Jobs.BackoffBase,
+		BackoffMax:   cfg.Jobs.BackoffMax,
+		LockTimeout:  cfg.Jobs.LockTimeout,
+		Logger:       logger,
+	})
+
+	// Completed jobs would otherwise pile up; dead ones are kept for inspection
+	scheduler.Register("jobs.prune", func(ctx context.Context, _ *jobs.Job) error {
+		n, err := store.DeleteFinished(ctx, time.Now().Add(-cfg.Jobs.Retention))
+		if err == nil && n > 0 {
+			logger.Info("pruned completed jobs", slog.Int64("count", n))
+		}
+		return err
+	})
+	if err := scheduler.Cron("jobs.prune", "@daily", "jobs.prune", nil); err != nil {
+		log.Fatalf("Failed to schedule job pruning: %v", err)
+	}
+
+	lc.Append(lifecycle.Hook{
+		Name:     "jobs",
+		Priority: lifecycle.PriorityServices,
+		Start:    scheduler.Start,
+		Stop:     scheduler.Stop,
+	})
+	return store
+}
+
+// auditRecorder starts the asynchronous audit writer with the lifecycle, so
+// buffered entries are flushed on shutdown, and counts dropped entries
+func auditRecorder(cfg *config.Config, db *database.DB, lc *lifecycle.Manager, m *metrics.Metrics, logger *slog.Logger) *audit.Recorder {
+	dropped := m.NewCounter("audit_entries_dropped_total",
+		"Audit entries lost because the buffer was full or the write failed", "reason")
+	recorder := audit.NewRecorder(audit.NewStore(db), audit.Options{
+		BufferSize:    cfg.Audit.BufferSize,
+		BatchSize:     cfg.Audit.BatchSize,
+		FlushInterval: cfg.Audit.FlushInterval,
+		Logger:        logger,
+		OnDrop:        func(reason string) { dropped.WithLabelValues(reason).Inc() },
+	})
+	lc.Append(lifecycle.Hook{
+		Name:     "audit",
+		Priority: lifecycle.PriorityServices,
+		Start:    recorder.Start,
+		Stop:     recorder.Stop,
+	})
+	return recorder
+}
+
+// featureFlags loads the configured flags and keeps runtime overrides in
+// sync with the database while the server runs
+func featureFlags(cfg *config.Config, db *database.DB, lc *lifecycle.Manager, logger *slog.Logger) *featureflags.Set {
+	flags, err := featureflags.New(featureflags.FromConfig(cfg.FeatureFlags), featureflags.NewSQLStore(db))
+	if err != nil {
+		log.Fatalf("Failed to load feature flags: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(lifecycle.Hook{
+		Name:     "feature-flags",
+		Priority: lifecycle.PriorityServices,
+		Start: func(startCtx context.Context) error {
+			// Without overrides the configured flags still apply, so this is not fatal
+			if err := flags.Refresh(startCtx); err != nil {
+				logger.Error("loading feature flag overrides failed, using configured flags", slog.Any("error", err))
+			}
+			go flags.Watch(ctx, cfg.FeatureFlags.RefreshInterval, func(err error) {
+				logger.Error("feature flag refresh failed, keeping the current overrides", slog.Any("error", err))
+			})
+			return nil
+		},
+		Stop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+	return flags
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/config"
+)
+
+// TestClientIPIgnoresSpoofedForwardedFor checks that X-Forwarded-For only
+// counts when it comes from a configured proxy
+func TestClientIPIgnoresSpoofedForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		proxies []string
+		want    string
+	}{
+		{"no trusted proxies", nil, "203.0.113.7"},
+		{"peer is not a trusted proxy", []string{"10.0.0.0/8"}, "203.0.113.7"},
+		{"peer is a trusted proxy", []string{"203.0.113.0/24"}, "198.51.100.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Server.TrustedProxies = tt.proxies
+			router, err := newRouter(cfg)
+			if err != nil {
+				t.Fatalf("newRouter() error = %v", err)
+			}
+			router.GET("/ip", func(c *gin.Context) { c.String(http.StatusOK, c.ClientIP()) })
+
+			req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+			req.RemoteAddr = "203.0.113.7:4321"
+			req.Header.Set("X-Forwarded-For", "198.51.100.1")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
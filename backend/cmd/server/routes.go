@@ -0,0 +1,176 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/apierror"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/featureflags"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/handlers"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/jwtservice"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/middleware"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/openapi"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/userdomain"
+)
+
+// Request body limits of routes that need less than server.max_body_bytes
+const (
+	authBodyLimit = 16 << 10
+	flagBodyLimit = 64 << 10
+)
+
+// apiDeps holds what the /api/v1 handlers need
+type apiDeps struct {
+	jwt  *jwtservice.JWTService
+	auth *handlers.AuthHandler
+	// authRateLimit guards the credential endpoints; nil disables it
+	authRateLimit gin.HandlerFunc
+	// jobs serves the job queue overview; nil when jobs are disabled
+	jobs *handlers.JobsHandler
+	// audit serves the audit log; nil when auditing is disabled
+	audit *handlers.AuditHandler
+	// flags serves flag evaluation and the flag admin endpoints
+	flags *handlers.FlagsHandler
+}
+
+// registerAPIRoutes adds every /api/v1 endpoint. Register through api (not the
+// raw gin group) so each route lands in the OpenAPI document.
+func registerAPIRoutes(api *openapi.Group, deps apiDeps) {
+	system := api.Group("").Tags("system")
+	system.GET("/ping", openapi.Operation{
+		Summary:   "Check that the API answers",
+		Responses: map[int]any{http.StatusOK: handlers.PingResponse{}},
+	}, handlers.Ping)
+
+	api.Group("").Tags("flags").GET("/flags", openapi.Operation{
+		Summary:     "Evaluate feature flags for the caller",
+		Description: "Anonymous callers only see flags rolled out to 100 percent. Send a bearer token to get the signed-in user's values.",
+		Responses:   map[int]any{http.StatusOK: handlers.FlagValuesResponse{}},
+	}, deps.flags.Values)
+
+	authGroup := api.Group("/auth").Tags("auth")
+	if deps.authRateLimit != nil {
+		authGroup.Use(deps.authRateLimit)
+	}
+	authGroup.Use(middleware.MaxBodySize(authBodyLimit))
+	authErrors := map[int]any{
+		http.StatusBadRequest:            apierror.Problem{},
+		http.StatusRequestEntityTooLarge: apierror.Problem{},
+		http.StatusTooManyRequests:       apierror.Problem{},
+	}
+	authGroup.POST("/register", openapi.Operation{
+		Summary:     "Create an account",
+		Description: "Returns 409 when the email is already registered and 400 with field errors for invalid input.",
+		Request:     handlers.RegisterRequest{},
+		Responses: withResponses(authErrors, map[int]any{
+			http.StatusCreated:  handlers.TokenResponse{},
+			http.StatusConflict: apierror.Problem{},
+		}),
+	}, deps.auth.Register)
+	authGroup.POST("/login", openapi.Operation{
+		Summary: "Exchange email and password for tokens",
+		Request: handlers.LoginRequest{},
+		Responses: withResponses(authErrors, map[int]any{
+			http.StatusOK:           handlers.TokenResponse{},
+			http.StatusUnauthorized: apierror.Problem{},
+		}),
+	}, deps.auth.Login)
+	authGroup.POST("/refresh", openapi.Operation{
+		Summary:     "Rotate a refresh token",
+		Description: "The presented refresh token is revoked. Reusing a revoked token revokes every session of the user.",
+		Request:     handlers.RefreshRequest{},
+		Responses: withResponses(authErrors, map[int]any{
+			http.StatusOK:           handlers.TokenResponse{},
+			http.StatusUnauthorized: apierror.Problem{},
+		}),
+	}, deps.auth.Refresh)
+	authGroup.POST("/logout", openapi.Operation{
+		Summary: "Revoke a refresh token",
+		Request: handlers.RefreshRequest{},
+		Responses: withResponses(authErrors, map[int]any{
+			http.StatusNoContent:    nil,
+			http.StatusUnauthorized: apierror.Problem{},
+		}),
+	}, deps.auth.Logout)
+
+	me := api.Group("/auth").Tags("auth").Secured()
+	me.GET("/me", openapi.Operation{
+		Summary: "Return the authenticated user",
+		Responses: map[int]any{
+			http.StatusOK:           userdomain.User{},
+			http.StatusUnauthorized: apierror.Problem{},
+		},
+	}, middleware.RequireAuth(deps.jwt), deps.auth.Me)
+
+	admin := api.Group("/admin").Tags("admin").Secured()
+	admin.Use(middleware.RequireAuth(deps.jwt), middleware.RequireRole(userdomain.RoleAdmin))
+	adminErrors := map[int]any{
+		http.StatusUnauthorized: apierror.Problem{},
+		http.StatusForbidden:    apierror.Problem{},
+	}
+	if deps.jobs != nil {
+		admin.GET("/jobs", openapi.Operation{
+			Summary:     "List background jobs",
+			Description: "Returns the number of jobs in each state and the most recently updated jobs. Filter by status=dead for the dead-letter list.",
+			Query:       handlers.JobsQuery{},
+			Responses: withResponses(adminErrors, map[int]any{
+				http.StatusOK:         handlers.JobsResponse{},
+				http.StatusBadRequest: apierror.Problem{},
+			}),
+		}, deps.jobs.List)
+	}
+	if deps.audit != nil {
+		admin.GET("/audit", openapi.Operation{
+			Summary:     "Query the audit log",
+			Description: "Returns mutating API requests newest first. Pass next_cursor as cursor to fetch the next page.",
+			Query:       handlers.AuditQuery{},
+			Responses: withResponses(adminErrors, map[int]any{
+				http.StatusOK:         handlers.AuditLogResponse{},
+				http.StatusBadRequest: apierror.Problem{},
+			}),
+		}, deps.audit.List)
+	}
+
+	admin.GET("/flags", openapi.Operation{
+		Summary:     "List feature flags",
+		Description: "Returns every flag with its source: config for the config file definition, override for a runtime change.",
+		Responses:   withResponses(adminErrors, map[int]any{http.StatusOK: handlers.FlagsResponse{}}),
+	}, deps.flags.List)
+	admin.PUT("/flags/:name", openapi.Operation{
+		Summary:     "Override a feature flag",
+		Description: "Replaces the flag's definition on every replica without a restart. Unknown names create a new flag.",
+		Request:     handlers.FlagRequest{},
+		Responses: withResponses(adminErrors, map[int]any{
+			http.StatusOK:                    featureflags.State{},
+			http.StatusBadRequest:            apierror.Problem{},
+			http.StatusRequestEntityTooLarge: apierror.Problem{},
+		}),
+	}, middleware.MaxBodySize(flagBodyLimit), deps.flags.Put)
+	admin.DELETE("/flags/:name", openapi.Operation{
+		Summary:     "Remove a feature flag override",
+		Description: "Goes back to the config file definition. Returns 404 when the flag has no override.",
+		Responses: withResponses(adminErrors, map[int]any{
+			http.StatusNoContent: nil,
+			http.StatusNotFound:  apierror.Problem{},
+		}),
+	}, deps.flags.Delete)
+}
+
+// withResponses merges shared error responses into an operation's own
+func withResponses(shared, own map[int]any) map[int]any {
+	merged := make(map[int]any, len(shared)+len(own))
+	for status, body := range shared {
+		merged[status] = body
+	}
+	for status, body := range own {
+		merged[status] = body
+	}
+	return merged
+}
+
+// registerDocs serves the OpenAPI document and Swagger UI next to the API
+func registerDocs(api *gin.RouterGroup, spec *openapi.Spec) {
+	api.GET("/openapi.json", spec.JSONHandler())
+	api.GET("/docs", spec.UIHandler(api.BasePath()+"/openapi.json", api.BasePath()+"/docs/assets"))
+	api.GET("/docs/assets/*file", openapi.AssetsHandler())
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/handlers"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/jwtservice"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/openapi"
+)
+
+// TestEveryAPIRouteIsDocumented fails when a route under /api/v1 is missing from the OpenAPI spec
+func TestEveryAPIRouteIsDocumented(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	jwt, err := jwtservice.NewJWTService("test-secret", jwtservice.DefaultOptions())
+	if err != nil {
+		t.Fatalf("NewJWTService() error = %v", err)
+	}
+
+	router := gin.New()
+	api := router.Group("/api/v1")
+	spec := openapi.NewSpec("test", "test", api.BasePath())
+	registerAPIRoutes(spec.Group(api), apiDeps{
+		jwt:   jwt,
+		auth:  handlers.NewAuthHandler(nil),
+		jobs:  handlers.NewJobsHandler(nil),
+		audit: handlers.NewAuditHandler(nil),
+		flags: handlers.NewFlagsHandler(nil),
+	})
+	registerDocs(api, spec)
+
+	docs := map[string]bool{"/openapi.json": true, "/docs": true, "/docs/assets/*file": true}
+	checked := 0
+	for _, route := range router.Routes() {
+		path, ok := strings.CutPrefix(route.Path, api.BasePath())
+		if !ok || docs[path] {
+			continue
+		}
+		checked++
+		if !spec.Has(route.Method, path) {
+			t.Errorf("%s %s is registered but missing from the OpenAPI spec", route.Method, route.Path)
+		}
+	}
+	if checked == 0 {
+		t.Fatal("Expected at least one API route")
+	}
+}
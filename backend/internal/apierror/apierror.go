@@ -0,0 +1,220 @@
+// Package apierror defines the API's error responses. Every error is
+// rendered as an RFC 7807 problem document with content type
+// application/problem+json:
+//
+//	{
+//	  "type": "/problems/not-found",
+//	  "title": "Not Found",
+//	  "status": 404,
+//	  "detail": "user not found",
+//	  "instance": "<request ID>",
+//	  "code": "not_found",
+//	  "errors": [{"field": "email", "message": "is required"}]
+//	}
+//
+// Handlers return or abort with an *Error; anything else is mapped by From,
+// which knows sql.ErrNoRows, gorm.ErrRecordNotFound and the sentinels
+// registered with Register, and hides unknown errors behind a 500.
+package apierror
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// ContentType is the media type of problem documents
+const ContentType = "application/problem+json"
+
+// TypeBase prefixes the problem type URIs; the code with dashes follows it
+const TypeBase = "/problems/"
+
+// Stable codes used by the constructors
+const (
+	CodeBadRequest   = "bad_request"
+	CodeValidation   = "validation_failed"
+	CodeUnauthorized = "unauthorized"
+	CodeForbidden    = "forbidden"
+	CodeNotFound     = "not_found"
+	CodeConflict     = "conflict"
+	CodeRateLimited  = "rate_limited"
+	CodeInternal     = "internal"
+)
+
+// FieldError explains why one request field was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Problem is the RFC 7807 document written to clients. Code and Errors are
+// extension members: a stable machine-readable code and per-field errors.
+type Problem struct {
+	Type     string       `json:"type" example:"/problems/not-found"`
+	Title    string       `json:"title" example:"Not Found"`
+	Status   int          `json:"status" example:"404"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code" example:"not_found"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// ContentType lets the OpenAPI generator document problems with the right media type
+func (Problem) ContentType() string {
+	return ContentType
+}
+
+// Error is an error that knows how to present itself to API clients
+type Error struct {
+	Status int
+	Code   string
+	Detail string
+	Fields []FieldError
+	// Err is the underlying cause; it is logged, never shown
+	Err error
+}
+
+// New creates an Error with an explicit status and code
+func New(status int, code, detail string) *Error {
+	return &Error{Status: status, Code: code, Detail: detail}
+}
+
+// BadRequest is a malformed request, e.g. a body that is not JSON
+func BadRequest(detail string) *Error {
+	return New(http.StatusBadRequest, CodeBadRequest, detail)
+}
+
+// Validation reports rejected fields
+func Validation(detail string, fields ...FieldError) *Error {
+	return New(http.StatusBadRequest, CodeValidation, detail).WithFields(fields...)
+}
+
+// Unauthorized means missing or invalid credentials
+func Unauthorized(detail string) *Error {
+	return New(http.StatusUnauthorized, CodeUnauthorized, detail)
+}
+
+// Forbidden means the caller is known but not allowed
+func Forbidden(detail string) *Error {
+	return New(http.StatusForbidden, CodeForbidden, detail)
+}
+
+// NotFound means the resource does not exist
+func NotFound(detail string) *Error {
+	return New(http.StatusNotFound, CodeNotFound, detail)
+}
+
+// Conflict means the request clashes with the current state, e.g. a duplicate
+func Conflict(detail string) *Error {
+	return New(http.StatusConflict, CodeConflict, detail)
+}
+
+// RateLimited means the caller exceeded its budget
+func RateLimited(detail string) *Error {
+	return New(http.StatusTooManyRequests, CodeRateLimited, detail)
+}
+
+// Internal hides err behind a generic 500
+func Internal(err error) *Error {
+	e := New(http.StatusInternalServerError, CodeInternal, "internal server error")
+	e.Err = err
+	return e
+}
+
+// WithCode replaces the stable code, e.g. "token_expired" for an Unauthorized
+func (e *Error) WithCode(code string) *Error {
+	e.Code = code
+	return e
+}
+
+// WithFields attaches field errors, e.g. to a Conflict on a unique field
+func (e *Error) WithFields(fields ...FieldError) *Error {
+	e.Fields = append(e.Fields, fields...)
+	return e
+}
+
+// Wrap records the underlying cause
+func (e *Error) Wrap(err error) *Error {
+	e.Err = err
+	return e
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("%d %s: %s", e.Status, e.Code, e.Detail)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Problem renders e as a problem document for the request identified by instance
+func (e *Error) Problem(instance string) Problem {
+	return Problem{
+		Type:     TypeBase + strings.ReplaceAll(e.Code, "_", "-"),
+		Title:    http.StatusText(e.Status),
+		Status:   e.Status,
+		Detail:   e.Detail,
+		Instance: instance,
+		Code:     e.Code,
+		Errors:   e.Fields,
+	}
+}
+
+type mapping struct {
+	target error
+	build  func(err error) *Error
+}
+
+var (
+	mappingsMu sync.RWMutex
+	mappings   = []mapping{
+		{sql.ErrNoRows, func(err error) *Error { return NotFound("resource not found").Wrap(err) }},
+		{gorm.ErrRecordNotFound, func(err error) *Error { return NotFound("resource not found").Wrap(err) }},
+		{context.DeadlineExceeded, func(err error) *Error {
+			return New(http.StatusGatewayTimeout, "timeout", "the request took too long").Wrap(err)
+		}},
+	}
+)
+
+// Register teaches From to turn errors matching target (per errors.Is) into
+// the *Error built by build. Packages call it from init for their sentinels.
+func Register(target error, build func(err error) *Error) {
+	mappingsMu.Lock()
+	defer mappingsMu.Unlock()
+	mappings = append(mappings, mapping{target: target, build: build})
+}
+
+// From converts any error into an *Error: an *Error in the chain is used
+// as is, registered sentinels are mapped, and everything else becomes Internal
+func From(err error) *Error {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	var maxBytes *http.MaxBytesError
+	if errors.As(err, &maxBytes) {
+		return New(http.StatusRequestEntityTooLarge, "body_too_large",
+			fmt.Sprintf("request body exceeds %d bytes", maxBytes.Limit)).Wrap(err)
+	}
+
+	mappingsMu.RLock()
+	defer mappingsMu.RUnlock()
+	// Later registrations win so packages can refine the defaults
+	for i := len(mappings) - 1; i >= 0; i-- {
+		if errors.Is(err, mappings[i].target) {
+			return mappings[i].build(err)
+		}
+	}
+	return Internal(err)
+}
@@ -0,0 +1,130 @@
+package apierror
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/logging"
+	"gorm.io/gorm"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+var errCustom = errors.New("custom sentinel")
+
+func TestFrom(t *testing.T) {
+	Register(errCustom, func(err error) *Error { return Conflict("custom").Wrap(err) })
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"api error", NotFound("user not found"), http.StatusNotFound, CodeNotFound},
+		{"wrapped api error", fmt.Errorf("handler: %w", Forbidden("no")), http.StatusForbidden, CodeForbidden},
+		{"sql no rows", fmt.Errorf("repo: %w", sql.ErrNoRows), http.StatusNotFound, CodeNotFound},
+		{"gorm not found", gorm.ErrRecordNotFound, http.StatusNotFound, CodeNotFound},
+		{"registered sentinel", fmt.Errorf("svc: %w", errCustom), http.StatusConflict, CodeConflict},
+		{"body too large", &http.MaxBytesError{Limit: 10}, http.StatusRequestEntityTooLarge, "body_too_large"},
+		{"unknown error", errors.New("disk on fire"), http.StatusInternalServerError, CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := From(tt.err)
+			if got.Status != tt.wantStatus || got.Code != tt.wantCode {
+				t.Errorf("Expected %d %s, got %d %s", tt.wantStatus, tt.wantCode, got.Status, got.Code)
+			}
+		})
+	}
+}
+
+func TestProblem(t *testing.T) {
+	p := Validation("bad input", FieldError{Field: "email", Message: "is required"}).Problem("req-1")
+
+	if p.Type != "/problems/validation-failed" || p.Title != "Bad Request" || p.Status != 400 {
+		t.Errorf("Unexpected problem header fields: %+v", p)
+	}
+	if p.Instance != "req-1" || len(p.Errors) != 1 || p.Errors[0].Field != "email" {
+		t.Errorf("Unexpected problem body: %+v", p)
+	}
+}
+
+func serve(handlers ...gin.HandlerFunc) *httptest.ResponseRecorder {
+	router := gin.New()
+	router.Use(Recovery(), Middleware())
+	router.NoRoute(NotFoundHandler)
+	router.GET("/", handlers...)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req = req.WithContext(logging.WithRequestID(req.Context(), "abc123"))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func decode(t *testing.T, w *httptest.ResponseRecorder) Problem {
+	t.Helper()
+	if ct := w.Header().Get("Content-Type"); ct != ContentType {
+		t.Errorf("Expected content type %q, got %q", ContentType, ct)
+	}
+	var p Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatalf("Failed to decode problem: %v (%s)", err, w.Body.String())
+	}
+	return p
+}
+
+func TestAbortHidesInternalDetails(t *testing.T) {
+	w := serve(func(c *gin.Context) {
+		Abort(c, errors.New("password=hunter2 leaked"))
+	})
+
+	p := decode(t, w)
+	if w.Code != http.StatusInternalServerError || p.Detail != "internal server error" {
+		t.Errorf("Expected generic 500, got %d %+v", w.Code, p)
+	}
+	if p.Instance != "abc123" {
+		t.Errorf("Expected instance to be the request ID, got %q", p.Instance)
+	}
+}
+
+func TestMiddlewareRendersContextErrors(t *testing.T) {
+	w := serve(func(c *gin.Context) {
+		c.Error(fmt.Errorf("lookup: %w", sql.ErrNoRows))
+	})
+
+	if p := decode(t, w); w.Code != http.StatusNotFound || p.Code != CodeNotFound {
+		t.Errorf("Expected 404 not_found, got %d %+v", w.Code, p)
+	}
+}
+
+func TestMiddlewareLeavesWrittenResponses(t *testing.T) {
+	w := serve(func(c *gin.Context) {
+		c.String(http.StatusOK, "fine")
+		c.Error(errors.New("logged only"))
+	})
+
+	if w.Code != http.StatusOK || w.Body.String() != "fine" {
+		t.Errorf("Expected the handler's response to stand, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	w := serve(func(c *gin.Context) {
+		panic("boom")
+	})
+
+	if p := decode(t, w); w.Code != http.StatusInternalServerError || p.Code != CodeInternal {
+		t.Errorf("Expected 500 problem after panic, got %d %+v", w.Code, p)
+	}
+}
@@ -0,0 +1,56 @@
+package apierror
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/logging"
+)
+
+// Abort stops the chain and writes err as a problem document. Server errors
+// are logged with their cause; clients only see the generic detail.
+func Abort(c *gin.Context, err error) {
+	apiErr := From(err)
+	ctx := c.Request.Context()
+
+	if apiErr.Status >= http.StatusInternalServerError {
+		logging.FromContext(ctx).Error("request failed",
+			slog.Int("status", apiErr.Status), slog.Any("error", apiErr.Err))
+	}
+
+	c.Abort()
+	if c.Writer.Written() {
+		return
+	}
+	// Set before rendering so render.JSON keeps it
+	c.Header("Content-Type", ContentType)
+	c.Render(apiErr.Status, render.JSON{Data: apiErr.Problem(logging.RequestID(ctx))})
+}
+
+// Middleware renders the last error attached with c.Error when the handler
+// did not write a response itself, so handlers can simply
+// `c.Error(err); return`
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		Abort(c, c.Errors.Last().Err)
+	}
+}
+
+// NotFoundHandler answers unknown routes with a 404 problem
+func NotFoundHandler(c *gin.Context) {
+	Abort(c, NotFound("no route for "+c.Request.Method+" "+c.Request.URL.Path))
+}
+
+// Recovery turns panics into a 500 problem and logs the panic value
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, recovered any) {
+		Abort(c, Internal(fmt.Errorf("panic: %v", recovered)))
+	})
+}
@@ -0,0 +1,138 @@
+// Package audit records who changed what through the API.
+//
+// Entries are built by the Audit middleware for every mutating request,
+// buffered by a Recorder and written in batches to the append-only
+// audit_log table, so a slow database never holds up a response.
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Outcome summarises the response status of an audited request
+type Outcome string
+
+// Outcomes; failure covers both rejected (4xx) and broken (5xx) requests
+const (
+	OutcomeSuccess Outcome = "success"
+	OutcomeFailure Outcome = "failure"
+)
+
+// OutcomeOf maps an HTTP status to an Outcome
+func OutcomeOf(status int) Outcome {
+	if status >= http.StatusBadRequest {
+		return OutcomeFailure
+	}
+	return OutcomeSuccess
+}
+
+// Entry is one audited request
+type Entry struct {
+	ID         int64             `json:"id"`
+	OccurredAt time.Time         `json:"occurred_at"`
+	ActorID    *int              `json:"actor_id"`
+	Method     string            `json:"method" example:"PATCH"`
+	Route      string            `json:"route" example:"/api/v1/users/:id"`
+	Path       string            `json:"path" example:"/api/v1/users/42"`
+	ResourceID string            `json:"resource_id,omitempty" example:"42"`
+	Status     int               `json:"status" example:"200"`
+	Outcome    Outcome           `json:"outcome" example:"success"`
+	Changes    map[string]Change `json:"changes,omitempty"`
+	IP         string            `json:"ip"`
+	UserAgent  string            `json:"user_agent"`
+	RequestID  string            `json:"request_id"`
+}
+
+// Widths of the audit_log text columns, in characters
+const (
+	maxRouteLen     = 255
+	maxPathLen      = 2048
+	maxResourceLen  = 255
+	maxIPLen        = 64
+	maxUserAgentLen = 512
+	maxRequestIDLen = 128
+)
+
+// Truncate cuts the client-controlled fields down to their column widths,
+// so an oversized path or User-Agent cannot make the insert fail
+func (e *Entry) Truncate() {
+	e.Route = truncate(e.Route, maxRouteLen)
+	e.Path = truncate(e.Path, maxPathLen)
+	e.ResourceID = truncate(e.ResourceID, maxResourceLen)
+	e.IP = truncate(e.IP, maxIPLen)
+	e.UserAgent = truncate(e.UserAgent, maxUserAgentLen)
+	e.RequestID = truncate(e.RequestID, maxRequestIDLen)
+}
+
+// truncate keeps the first n characters of s
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	i := 0
+	for pos := range s {
+		if i == n {
+			return s[:pos]
+		}
+		i++
+	}
+	return s
+}
+
+// Change is the old and new JSON value of one field; Before is absent for
+// created fields and After for removed ones
+type Change struct {
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// Diff compares the JSON encodings of before and after field by field and
+// returns the fields that differ. Either side may be nil, for a created or
+// deleted resource. Fields hidden from JSON (json:"-") never appear, so
+// secrets such as password hashes stay out of the log.
+func Diff(before, after any) (map[string]Change, error) {
+	b, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]Change)
+	for name, old := range b {
+		if now, ok := a[name]; !ok || !bytes.Equal(old, now) {
+			changes[name] = Change{Before: old, After: a[name]}
+		}
+	}
+	for name, now := range a {
+		if _, ok := b[name]; !ok {
+			changes[name] = Change{After: now}
+		}
+	}
+	return changes, nil
+}
+
+// fields encodes v and splits the resulting JSON object into its members
+func fields(v any) (map[string]json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("audit: encode: %w", err)
+	}
+	if string(raw) == "null" {
+		return nil, nil
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, fmt.Errorf("audit: %T does not encode to a JSON object", v)
+	}
+	return m, nil
+}
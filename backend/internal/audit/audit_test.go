@@ -0,0 +1,78 @@
+package audit
+
+import (
+	"net/http"
+	"testing"
+)
+
+type profile struct {
+	Name     string   `json:"name"`
+	Email    string   `json:"email"`
+	Tags     []string `json:"tags"`
+	Password string   `json:"-"`
+}
+
+func TestDiff(t *testing.T) {
+	old := profile{Name: "Alice", Email: "alice@example.com", Tags: []string{"a"}, Password: "x"}
+	renamed := old
+	renamed.Name, renamed.Password = "Alicia", "y"
+
+	tests := []struct {
+		name          string
+		before, after any
+		want          map[string]Change
+	}{
+		{"update", old, renamed, map[string]Change{
+			"name": {Before: []byte(`"Alice"`), After: []byte(`"Alicia"`)},
+		}},
+		{"no change", old, old, map[string]Change{}},
+		{"create", nil, &old, map[string]Change{
+			"name":  {After: []byte(`"Alice"`)},
+			"email": {After: []byte(`"alice@example.com"`)},
+			"tags":  {After: []byte(`["a"]`)},
+		}},
+		{"delete", map[string]int{"count": 1}, nil, map[string]Change{
+			"count": {Before: []byte(`1`)},
+		}},
+		{"nil pointer", (*profile)(nil), map[string]int{"n": 2}, map[string]Change{
+			"n": {After: []byte(`2`)},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Diff(tt.before, tt.after)
+			if err != nil {
+				t.Fatalf("Diff() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %d changes, got %d: %v", len(tt.want), len(got), got)
+			}
+			for field, want := range tt.want {
+				c := got[field]
+				if string(c.Before) != string(want.Before) || string(c.After) != string(want.After) {
+					t.Errorf("%s: expected %s -> %s, got %s -> %s", field, want.Before, want.After, c.Before, c.After)
+				}
+			}
+		})
+	}
+
+	if _, err := Diff([]int{1}, nil); err == nil {
+		t.Error("Expected error for a value that is not a JSON object")
+	}
+}
+
+func TestOutcomeOf(t *testing.T) {
+	tests := map[int]Outcome{
+		http.StatusOK:                  OutcomeSuccess,
+		http.StatusNoContent:           OutcomeSuccess,
+		http.StatusBadRequest:          OutcomeFailure,
+		http.StatusForbidden:           OutcomeFailure,
+		http.StatusInternalServerError: OutcomeFailure,
+	}
+	for status, want := range tests {
+		if got := OutcomeOf(status); got != want {
+			t.Errorf("OutcomeOf(%d): expected %s, got %s", status, want, got)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Writer persists a batch of entries; *Store implements it
+type Writer interface {
+	Insert(ctx context.Context, entries []*Entry) error
+}
+
+// Drop reasons passed to Options.OnDrop
+const (
+	DropBufferFull  = "buffer_full"
+	DropWriteFailed = "write_failed"
+)
+
+// writeTimeout bounds one batch insert
+const writeTimeout = 10 * time.Second
+
+// Options configures a Recorder; zero fields take the defaults below
+type Options struct {
+	// BufferSize is how many entries may wait for the writer (1024)
+	BufferSize int
+	// BatchSize is the most entries written in one transaction (100); a
+	// batch that fails is retried entry by entry
+	BatchSize int
+	// FlushInterval is the longest an entry waits before being written (1s)
+	FlushInterval time.Duration
+	Logger        *slog.Logger
+	// OnDrop is called for every lost entry, e.g. to count it in metrics
+	OnDrop func(reason string)
+}
+
+// Recorder writes entries asynchronously through a bounded buffer. When
+// the buffer is full Record drops the entry instead of blocking the
+// request; drops are counted, passed to OnDrop and logged once per flush.
+type Recorder struct {
+	w    Writer
+	opts Options
+
+	mu      sync.RWMutex
+	closed  bool
+	started bool
+	entries chan *Entry
+	done    chan struct{}
+
+	dropped  atomic.Uint64
+	reported uint64
+}
+
+// NewRecorder creates a Recorder writing to w; call Start to begin writing
+func NewRecorder(w Writer, opts Options) *Recorder {
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 1024
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = time.Second
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	return &Recorder{
+		w:       w,
+		opts:    opts,
+		entries: make(chan *Entry, opts.BufferSize),
+		done:    make(chan struct{}),
+	}
+}
+
+// Record queues e without blocking. It reports false if e was dropped
+// because the buffer is full or the recorder is stopped.
+func (r *Recorder) Record(e *Entry) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if !r.closed {
+		select {
+		case r.entries <- e:
+			return true
+		default:
+		}
+	}
+	r.drop(DropBufferFull, 1)
+	return false
+}
+
+// Dropped returns how many entries were lost since the recorder was created
+func (r *Recorder) Dropped() uint64 {
+	return r.dropped.Load()
+}
+
+func (r *Recorder) drop(reason string, n int) {
+	r.dropped.Add(uint64(n))
+	if r.opts.OnDrop != nil {
+		for i := 0; i < n; i++ {
+			r.opts.OnDrop(reason)
+		}
+	}
+}
+
+// Start launches the background writer
+func (r *Recorder) Start(context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.started {
+		return errors.New("audit: recorder already started")
+	}
+	r.started = true
+	go r.run()
+	return nil
+}
+
+// Stop refuses new entries and waits until the buffered ones are written
+func (r *Recorder) Stop(ctx context.Context) error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	close(r.entries)
+	started := r.started
+	r.mu.Unlock()
+
+	if !started {
+		r.drop(DropWriteFailed, len(r.entries))
+		return nil
+	}
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("audit: %d buffered entries not written: %w", len(r.entries), ctx.Err())
+	}
+}
+
+func (r *Recorder) run() {
+	defer close(r.done)
+	ticker := time.NewTicker(r.opts.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Entry, 0, r.opts.BatchSize)
+	for {
+		select {
+		case e, ok := <-r.entries:
+			if !ok {
+				r.flush(batch)
+				r.reportDrops()
+				return
+			}
+			batch = append(batch, e)
+			if len(batch) >= r.opts.BatchSize {
+				r.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			r.flush(batch)
+			batch = batch[:0]
+			r.reportDrops()
+		}
+	}
+}
+
+func (r *Recorder) flush(batch []*Entry) {
+	if len(batch) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+	err := r.w.Insert(ctx, batch)
+	if err == nil {
+		return
+	}
+	lost := len(batch)
+	if lost > 1 {
+		// One bad row must not cost everyone else's records: retry them one by one
+		lost = 0
+		for _, e := range batch {
+			if rowErr := r.w.Insert(ctx, []*Entry{e}); rowErr != nil {
+				lost++
+				err = rowErr
+			}
+		}
+	}
+	if lost > 0 {
+		r.drop(DropWriteFailed, lost)
+		r.opts.Logger.Error("audit write failed, entries lost", slog.Int("count", lost), slog.Any("error", err))
+	}
+}
+
+// reportDrops logs drops since the last report; only the writer goroutine calls it
+func (r *Recorder) reportDrops() {
+	total := r.dropped.Load()
+	if total == r.reported {
+		return
+	}
+	r.opts.Logger.Warn("audit entries dropped",
+		slog.Uint64("dropped", total-r.reported),
+		slog.Uint64("total_dropped", total),
+	)
+	r.reported = total
+}
@@ -0,0 +1,156 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeWriter collects batches; when block is set Insert waits for it to
+// close, and batches holding an entry with the reject method fail whole
+type fakeWriter struct {
+	mu      sync.Mutex
+	batches [][]*Entry
+	block   chan struct{}
+	err     error
+	reject  string
+}
+
+func (w *fakeWriter) Insert(_ context.Context, entries []*Entry) error {
+	if w.block != nil {
+		<-w.block
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, e := range entries {
+		if w.reject != "" && e.Method == w.reject {
+			return errors.New("value too long for type character varying(512)")
+		}
+	}
+	w.batches = append(w.batches, append([]*Entry(nil), entries...))
+	return w.err
+}
+
+func (w *fakeWriter) count() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	n := 0
+	for _, b := range w.batches {
+		n += len(b)
+	}
+	return n
+}
+
+func TestRecorderBatchesAndDrainsOnStop(t *testing.T) {
+	w := &fakeWriter{}
+	r := NewRecorder(w, Options{BatchSize: 3, FlushInterval: time.Hour})
+	r.Start(context.Background())
+
+	for i := 0; i < 7; i++ {
+		if !r.Record(&Entry{Method: "POST"}) {
+			t.Fatalf("Record(%d) dropped", i)
+		}
+	}
+	if err := r.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	if w.count() != 7 {
+		t.Errorf("Expected 7 written entries, got %d", w.count())
+	}
+	for _, b := range w.batches {
+		if len(b) > 3 {
+			t.Errorf("Expected batches of at most 3, got %d", len(b))
+		}
+	}
+	if r.Record(&Entry{}) {
+		t.Error("Expected Record after Stop to drop")
+	}
+}
+
+func TestRecorderFlushesOnInterval(t *testing.T) {
+	w := &fakeWriter{}
+	r := NewRecorder(w, Options{FlushInterval: 10 * time.Millisecond})
+	r.Start(context.Background())
+	defer r.Stop(context.Background())
+
+	r.Record(&Entry{})
+	deadline := time.Now().Add(2 * time.Second)
+	for w.count() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the entry to be written without reaching the batch size")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestRecorderDropsWhenFull(t *testing.T) {
+	w := &fakeWriter{block: make(chan struct{})}
+	var (
+		mu      sync.Mutex
+		reasons = map[string]int{}
+	)
+	r := NewRecorder(w, Options{
+		BufferSize: 2,
+		BatchSize:  1,
+		OnDrop: func(reason string) {
+			mu.Lock()
+			reasons[reason]++
+			mu.Unlock()
+		},
+	})
+	r.Start(context.Background())
+
+	// The writer holds one entry; two more fill the buffer
+	accepted := 0
+	for i := 0; i < 10; i++ {
+		if r.Record(&Entry{}) {
+			accepted++
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(w.block)
+	r.Stop(context.Background())
+
+	if accepted < 2 || accepted > 3 {
+		t.Errorf("Expected 2-3 accepted entries, got %d", accepted)
+	}
+	if got := r.Dropped(); got != uint64(10-accepted) {
+		t.Errorf("Expected %d dropped, got %d", 10-accepted, got)
+	}
+	if reasons[DropBufferFull] != 10-accepted {
+		t.Errorf("Expected OnDrop for every dropped entry, got %v", reasons)
+	}
+}
+
+func TestRecorderCountsFailedWrites(t *testing.T) {
+	w := &fakeWriter{err: errors.New("database is locked")}
+	r := NewRecorder(w, Options{})
+	r.Start(context.Background())
+	r.Record(&Entry{})
+	r.Record(&Entry{})
+	r.Stop(context.Background())
+
+	if r.Dropped() != 2 {
+		t.Errorf("Expected failed writes to count as dropped, got %d", r.Dropped())
+	}
+}
+
+func TestRecorderIsolatesFailingEntry(t *testing.T) {
+	w := &fakeWriter{reject: "BAD"}
+	r := NewRecorder(w, Options{BatchSize: 5, FlushInterval: time.Hour})
+	r.Start(context.Background())
+	for _, method := range []string{"POST", "PUT", "BAD", "PATCH", "DELETE"} {
+		r.Record(&Entry{Method: method})
+	}
+	r.Stop(context.Background())
+
+	if w.count() != 4 {
+		t.Errorf("Expected the 4 good entries to be written, got %d", w.count())
+	}
+	if r.Dropped() != 1 {
+		t.Errorf("Expected only the failing entry to be dropped, got %d", r.Dropped())
+	}
+}
@@ -0,0 +1,166 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/database"
+)
+
+// Store reads and appends audit_log rows. It has no update or delete:
+// the table rejects both.
+type Store struct {
+	db *database.DB
+}
+
+// NewStore creates a Store on db; the audit_log migration must be applied
+func NewStore(db *database.DB) *Store {
+	return &Store{db: db}
+}
+
+// Insert appends entries in one transaction and fills in their IDs
+func (s *Store) Insert(ctx context.Context, entries []*Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("audit: begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, s.db.Rebind(`INSERT INTO audit_log
+		(occurred_at, actor_id, method, route, path, resource_id, status, outcome, changes, ip, user_agent, request_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`))
+	if err != nil {
+		return fmt.Errorf("audit: prepare insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, e := range entries {
+		var changes sql.NullString
+		if len(e.Changes) > 0 {
+			raw, err := json.Marshal(e.Changes)
+			if err != nil {
+				return fmt.Errorf("audit: encode changes: %w", err)
+			}
+			changes = sql.NullString{String: string(raw), Valid: true}
+		}
+		var actor sql.NullInt64
+		if e.ActorID != nil {
+			actor = sql.NullInt64{Int64: int64(*e.ActorID), Valid: true}
+		}
+		resource := sql.NullString{String: e.ResourceID, Valid: e.ResourceID != ""}
+
+		err := stmt.QueryRowContext(ctx, e.OccurredAt.UTC(), actor, e.Method, e.Route, e.Path, resource,
+			e.Status, e.Outcome, changes, e.IP, e.UserAgent, e.RequestID).Scan(&e.ID)
+		if err != nil {
+			return fmt.Errorf("audit: insert: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("audit: commit: %w", err)
+	}
+	return nil
+}
+
+// Filter narrows List; zero values match everything. Results are newest
+// first; pass the ID of the last entry of a page as BeforeID for the next.
+type Filter struct {
+	ActorID    int
+	ResourceID string
+	Route      string
+	Method     string
+	Outcome    Outcome
+	Since      time.Time
+	Until      time.Time
+	BeforeID   int64
+	Limit      int
+}
+
+// List returns entries matching f
+func (s *Store) List(ctx context.Context, f Filter) ([]*Entry, error) {
+	var (
+		where []string
+		args  []any
+	)
+	add := func(cond string, arg any) {
+		where = append(where, cond)
+		args = append(args, arg)
+	}
+	if f.ActorID != 0 {
+		add("actor_id = ?", f.ActorID)
+	}
+	if f.ResourceID != "" {
+		add("resource_id = ?", f.ResourceID)
+	}
+	if f.Route != "" {
+		add("route = ?", f.Route)
+	}
+	if f.Method != "" {
+		add("method = ?", f.Method)
+	}
+	if f.Outcome != "" {
+		add("outcome = ?", f.Outcome)
+	}
+	if !f.Since.IsZero() {
+		add("occurred_at >= ?", f.Since.UTC())
+	}
+	if !f.Until.IsZero() {
+		add("occurred_at < ?", f.Until.UTC())
+	}
+	if f.BeforeID > 0 {
+		add("id < ?", f.BeforeID)
+	}
+
+	query := `SELECT id, occurred_at, actor_id, method, route, path, resource_id, status, outcome,
+		changes, ip, user_agent, request_id FROM audit_log`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY id DESC"
+	if f.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, f.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, s.db.Rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("audit: list: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*Entry
+	for rows.Next() {
+		var (
+			e                 Entry
+			actor             sql.NullInt64
+			resource, changes sql.NullString
+		)
+		err := rows.Scan(&e.ID, &e.OccurredAt, &actor, &e.Method, &e.Route, &e.Path, &resource, &e.Status,
+			&e.Outcome, &changes, &e.IP, &e.UserAgent, &e.RequestID)
+		if err != nil {
+			return nil, fmt.Errorf("audit: scan: %w", err)
+		}
+		if actor.Valid {
+			id := int(actor.Int64)
+			e.ActorID = &id
+		}
+		e.ResourceID = resource.String
+		if changes.Valid {
+			if err := json.Unmarshal([]byte(changes.String), &e.Changes); err != nil {
+				return nil, fmt.Errorf("audit: decode changes of entry %d: %w", e.ID, err)
+			}
+		}
+		entries = append(entries, &e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("audit: list: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,119 @@
+package audit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/database"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/database/dbtest"
+)
+
+func openTestStore(t *testing.T) (*Store, *database.DB) {
+	t.Helper()
+	db := dbtest.Open(t)
+	return NewStore(db), db
+}
+
+func newEntry(actor int, method, resource string, status int, at time.Time) *Entry {
+	e := &Entry{
+		OccurredAt: at,
+		Method:     method,
+		Route:      "/api/v1/records/:id",
+		Path:       "/api/v1/records/" + resource,
+		ResourceID: resource,
+		Status:     status,
+		Outcome:    OutcomeOf(status),
+		IP:         "203.0.113.7",
+		UserAgent:  "test",
+		RequestID:  "req-" + resource,
+	}
+	if actor != 0 {
+		e.ActorID = &actor
+	}
+	return e
+}
+
+func TestStoreInsertAndList(t *testing.T) {
+	store, _ := openTestStore(t)
+	ctx := context.Background()
+	base := time.Date(2025, 8, 1, 12, 0, 0, 0, time.UTC)
+
+	changed := newEntry(1, "PATCH", "10", 200, base)
+	changed.Changes = map[string]Change{"weight": {Before: []byte(`70`), After: []byte(`72`)}}
+	entries := []*Entry{
+		changed,
+		newEntry(2, "DELETE", "11", 204, base.Add(time.Minute)),
+		newEntry(1, "POST", "12", 400, base.Add(2*time.Minute)),
+		newEntry(0, "POST", "", 401, base.Add(3*time.Minute)),
+	}
+	if err := store.Insert(ctx, entries); err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+	if entries[0].ID == 0 || entries[3].ID <= entries[0].ID {
+		t.Fatalf("Expected increasing IDs, got %d and %d", entries[0].ID, entries[3].ID)
+	}
+
+	all, err := store.List(ctx, Filter{})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(all) != 4 || all[0].ID != entries[3].ID {
+		t.Fatalf("Expected 4 entries newest first, got %d", len(all))
+	}
+	if all[0].ActorID != nil || all[0].ResourceID != "" {
+		t.Errorf("Expected anonymous entry without resource, got %+v", all[0])
+	}
+	got := all[3]
+	if got.ActorID == nil || *got.ActorID != 1 || !got.OccurredAt.Equal(base) || got.RequestID != "req-10" {
+		t.Errorf("Unexpected entry %+v", got)
+	}
+	if c := got.Changes["weight"]; string(c.Before) != "70" || string(c.After) != "72" {
+		t.Errorf("Expected weight change to round-trip, got %+v", got.Changes)
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   int
+	}{
+		{"actor", Filter{ActorID: 1}, 2},
+		{"resource", Filter{ResourceID: "11"}, 1},
+		{"method", Filter{Method: "POST"}, 2},
+		{"outcome", Filter{Outcome: OutcomeFailure}, 2},
+		{"route", Filter{Route: "/api/v1/records/:id"}, 4},
+		{"time window", Filter{Since: base.Add(time.Minute), Until: base.Add(3 * time.Minute)}, 2},
+		{"combined", Filter{ActorID: 1, Outcome: OutcomeSuccess}, 1},
+		{"limit", Filter{Limit: 3}, 3},
+		{"next page", Filter{BeforeID: entries[1].ID}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := store.List(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if len(got) != tt.want {
+				t.Errorf("Expected %d entries, got %d", tt.want, len(got))
+			}
+		})
+	}
+}
+
+func TestAuditLogIsAppendOnly(t *testing.T) {
+	store, db := openTestStore(t)
+	ctx := context.Background()
+	if err := store.Insert(ctx, []*Entry{newEntry(1, "POST", "1", 201, time.Now())}); err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+
+	if _, err := db.ExecContext(ctx, "UPDATE audit_log SET status = 500"); err == nil {
+		t.Error("Expected UPDATE to be rejected")
+	}
+	if _, err := db.ExecContext(ctx, "DELETE FROM audit_log"); err == nil {
+		t.Error("Expected DELETE to be rejected")
+	}
+	if got, _ := store.List(ctx, Filter{}); len(got) != 1 || got[0].Status != 201 {
+		t.Errorf("Expected the entry to be unchanged, got %+v", got)
+	}
+}
@@ -0,0 +1,186 @@
+// Package auth implements registration, login and refresh-token rotation on
+// top of the user and refresh-token repositories.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/jwtservice"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/repository"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/security"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/userdomain"
+)
+
+var (
+	// ErrEmailTaken is returned by Register when the email is already registered
+	ErrEmailTaken = errors.New("auth: email already registered")
+	// ErrInvalidCredentials is returned by Login for an unknown email or a wrong password alike
+	ErrInvalidCredentials = errors.New("auth: invalid email or password")
+	// ErrInvalidRefreshToken is returned for malformed, expired, revoked or reused refresh tokens
+	ErrInvalidRefreshToken = errors.New("auth: invalid refresh token")
+	// ErrUserNotFound is returned when the token's user no longer exists
+	ErrUserNotFound = errors.New("auth: user not found")
+)
+
+// TokenPair is what a successful login or refresh hands out
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    time.Duration
+}
+
+// Service ties users, passwords and tokens together
+type Service struct {
+	users     *repository.UserRepository
+	tokens    *repository.RefreshTokenRepository
+	passwords *security.PasswordService
+	jwt       *jwtservice.JWTService
+	now       func() time.Time
+}
+
+// NewService creates an auth Service
+func NewService(users *repository.UserRepository, tokens *repository.RefreshTokenRepository,
+	passwords *security.PasswordService, jwt *jwtservice.JWTService) *Service {
+	return &Service{
+		users:     users,
+		tokens:    tokens,
+		passwords: passwords,
+		jwt:       jwt,
+		now:       time.Now,
+	}
+}
+
+// Register creates a user and signs them in. Invalid input gives a
+// *userdomain.ValidationError, a taken email ErrEmailTaken.
+func (s *Service) Register(ctx context.Context, email, name, password string) (*userdomain.User, *TokenPair, error) {
+	user, err := userdomain.NewUser(email, name, password)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if user.PasswordHash, err = s.passwords.HashPassword(password); err != nil {
+		return nil, nil, fmt.Errorf("auth: hash password: %w", err)
+	}
+
+	if err := s.users.Create(ctx, user); err != nil {
+		if errors.Is(err, repository.ErrDuplicateEmail) {
+			return nil, nil, ErrEmailTaken
+		}
+		return nil, nil, err
+	}
+
+	pair, err := s.issue(ctx, user)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, pair, nil
+}
+
+// Login checks the credentials and issues a token pair. An unknown email
+// still costs one bcrypt comparison so response times do not reveal which
+// emails are registered.
+func (s *Service) Login(ctx context.Context, email, password string) (*userdomain.User, *TokenPair, error) {
+	user, err := s.users.GetByEmail(ctx, email)
+	if errors.Is(err, repository.ErrNotFound) {
+		s.passwords.VerifyDummy(password)
+		return nil, nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !s.passwords.VerifyPassword(password, user.PasswordHash) {
+		return nil, nil, ErrInvalidCredentials
+	}
+
+	pair, err := s.issue(ctx, user)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, pair, nil
+}
+
+// Refresh rotates a refresh token: the presented token is revoked and a new
+// pair is issued. Presenting an already revoked token is treated as theft
+// and revokes every session of the user.
+func (s *Service) Refresh(ctx context.Context, refreshToken string) (*userdomain.User, *TokenPair, error) {
+	claims, err := s.jwt.ValidateRefreshToken(refreshToken)
+	if err != nil {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+
+	now := s.now()
+	revoked, err := s.tokens.Revoke(ctx, claims.ID, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !revoked {
+		if _, err := s.tokens.RevokeAllForUser(ctx, claims.UserID, now); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.users.GetByID(ctx, claims.UserID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pair, err := s.issue(ctx, user)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, pair, nil
+}
+
+// Logout revokes the refresh token. Revoking an already revoked token is not an error.
+func (s *Service) Logout(ctx context.Context, refreshToken string) error {
+	claims, err := s.jwt.ValidateRefreshToken(refreshToken)
+	if err != nil {
+		return ErrInvalidRefreshToken
+	}
+	_, err = s.tokens.Revoke(ctx, claims.ID, s.now())
+	return err
+}
+
+// User returns the user with id
+func (s *Service) User(ctx context.Context, id int) (*userdomain.User, error) {
+	user, err := s.users.GetByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrUserNotFound
+	}
+	return user, err
+}
+
+// issue signs a token pair for user and records the refresh token
+func (s *Service) issue(ctx context.Context, user *userdomain.User) (*TokenPair, error) {
+	access, err := s.jwt.GenerateToken(user.ID, user.Email, user.Roles()...)
+	if err != nil {
+		return nil, fmt.Errorf("auth: sign access token: %w", err)
+	}
+	refresh, claims, err := s.jwt.GenerateRefreshToken(user.ID, user.Email, user.Roles()...)
+	if err != nil {
+		return nil, fmt.Errorf("auth: sign refresh token: %w", err)
+	}
+
+	err = s.tokens.Create(ctx, &repository.RefreshToken{
+		ID:        claims.ID,
+		UserID:    user.ID,
+		ExpiresAt: claims.ExpiresAt.Time,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		ExpiresIn:    s.jwt.AccessTokenTTL(),
+	}, nil
+}
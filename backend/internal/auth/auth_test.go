@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/database/dbtest"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/jwtservice"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/repository"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/security"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/userdomain"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+	db := dbtest.Open(t)
+
+	jwt, err := jwtservice.NewJWTService("test-secret", jwtservice.DefaultOptions())
+	if err != nil {
+		t.Fatalf("NewJWTService() error = %v", err)
+	}
+	return NewService(
+		repository.NewUserRepository(db),
+		repository.NewRefreshTokenRepository(db),
+		security.NewPasswordServiceWithCost(bcrypt.MinCost),
+		jwt,
+	)
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+
+	user, pair, err := svc.Register(ctx, "alice@example.com", "Alice", "Password123")
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if user.ID == 0 || pair.AccessToken == "" || pair.RefreshToken == "" {
+		t.Fatalf("Expected user and tokens, got %+v %+v", user, pair)
+	}
+	if user.PasswordHash == "Password123" {
+		t.Error("Expected password to be hashed")
+	}
+
+	if _, _, err := svc.Register(ctx, "ALICE@example.com", "Alice", "Password123"); !errors.Is(err, ErrEmailTaken) {
+		t.Errorf("Expected ErrEmailTaken, got %v", err)
+	}
+
+	var verr *userdomain.ValidationError
+	if _, _, err := svc.Register(ctx, "bad", "A", "short"); !errors.As(err, &verr) || len(verr.Fields) != 3 {
+		t.Errorf("Expected three field errors, got %v", err)
+	}
+
+	if _, _, err := svc.Login(ctx, "alice@example.com", "Password123"); err != nil {
+		t.Errorf("Login() error = %v", err)
+	}
+	if _, _, err := svc.Login(ctx, "alice@example.com", "Wrong123"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected ErrInvalidCredentials for wrong password, got %v", err)
+	}
+	if _, _, err := svc.Login(ctx, "nobody@example.com", "Password123"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected ErrInvalidCredentials for unknown email, got %v", err)
+	}
+}
+
+func TestRefreshRotatesAndDetectsReuse(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+
+	_, first, err := svc.Register(ctx, "bob@example.com", "Bob", "Password123")
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	_, second, err := svc.Refresh(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Error("Expected a new refresh token")
+	}
+
+	// Replaying the rotated token revokes the whole family
+	if _, _, err := svc.Refresh(ctx, first.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Expected ErrInvalidRefreshToken on reuse, got %v", err)
+	}
+	if _, _, err := svc.Refresh(ctx, second.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Expected reuse to revoke the newer token too, got %v", err)
+	}
+
+	if _, _, err := svc.Refresh(ctx, first.AccessToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Expected access token to be rejected, got %v", err)
+	}
+}
+
+func TestLogout(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+
+	_, pair, err := svc.Register(ctx, "carol@example.com", "Carol", "Password123")
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	if err := svc.Logout(ctx, pair.RefreshToken); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+	if err := svc.Logout(ctx, pair.RefreshToken); err != nil {
+		t.Errorf("Expected second Logout to succeed, got %v", err)
+	}
+	if _, _, err := svc.Refresh(ctx, pair.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Expected logged out token to be rejected, got %v", err)
+	}
+	if err := svc.Logout(ctx, "garbage"); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Expected ErrInvalidRefreshToken, got %v", err)
+	}
+}
@@ -1,8 +1,13 @@
 package config
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestLoad(t *testing.T) {
@@ -83,3 +88,246 @@ func TestGetEnvAsBool(t *testing.T) {
 		t.Errorf("Expected fallback value false, got %v", result)
 	}
 }
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestLoadFileLayers(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "config.yaml", `
+env: staging
+port: "9100"
+server:
+  read_timeout: 5s
+database:
+  max_open_conns: 50
+`)
+	writeFile(t, dir, "config.staging.yaml", `
+port: "9200"
+jwt:
+  access_token_ttl: 30m
+`)
+	t.Setenv("DB_MAX_OPEN_CONNS", "7")
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile returned error: %v", err)
+	}
+
+	if cfg.Env != "staging" {
+		t.Errorf("Expected env 'staging', got '%s'", cfg.Env)
+	}
+	if cfg.Port != "9200" {
+		t.Errorf("Expected overlay port '9200', got '%s'", cfg.Port)
+	}
+	if cfg.Server.ReadTimeout != 5*time.Second {
+		t.Errorf("Expected read timeout 5s, got %v", cfg.Server.ReadTimeout)
+	}
+	if cfg.Server.WriteTimeout != Default().Server.WriteTimeout {
+		t.Errorf("Expected default write timeout, got %v", cfg.Server.WriteTimeout)
+	}
+	if cfg.JWT.AccessTokenTTL != 30*time.Minute {
+		t.Errorf("Expected access TTL 30m, got %v", cfg.JWT.AccessTokenTTL)
+	}
+	if cfg.Database.MaxOpenConns != 7 {
+		t.Errorf("Expected env override 7 for max open conns, got %d", cfg.Database.MaxOpenConns)
+	}
+}
+
+func TestLoadFileTOML(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "config.toml", `
+port = "9300"
+
+[server]
+idle_timeout = "2m"
+`)
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile returned error: %v", err)
+	}
+	if cfg.Port != "9300" {
+		t.Errorf("Expected port '9300', got '%s'", cfg.Port)
+	}
+	if cfg.Server.IdleTimeout != 2*time.Minute {
+		t.Errorf("Expected idle timeout 2m, got %v", cfg.Server.IdleTimeout)
+	}
+}
+
+func TestLoadFileFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("CONFIG_FILE", writeFile(t, dir, "app.yml", "port: \"9400\"\n"))
+
+	cfg, err := LoadFile("")
+	if err != nil {
+		t.Fatalf("LoadFile returned error: %v", err)
+	}
+	if cfg.Port != "9400" {
+		t.Errorf("Expected port '9400', got '%s'", cfg.Port)
+	}
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := LoadFile(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("Expected error for missing config file")
+	}
+	if _, err := LoadFile(writeFile(t, dir, "config.json", "{}")); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+	if _, err := LoadFile(writeFile(t, dir, "bad.yaml", "port: [")); err == nil {
+		t.Error("Expected error for malformed YAML")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Default().Validate(); err != nil {
+		t.Errorf("Expected defaults to be valid in development, got %v", err)
+	}
+
+	cfg := Default()
+	cfg.Env = EnvProduction
+	cfg.DatabaseURL = ""
+	cfg.Port = "http"
+	cfg.Database.MaxIdleConns = 100
+
+	err := cfg.Validate()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Expected *ValidationError, got %v", err)
+	}
+	if len(verr.Problems) != 4 {
+		t.Errorf("Expected 4 problems, got %d: %v", len(verr.Problems), verr.Problems)
+	}
+	if !strings.Contains(err.Error(), "jwt_secret must not use the default value") {
+		t.Errorf("Expected default secret to be reported, got %v", err)
+	}
+}
+
+func TestTrustedProxies(t *testing.T) {
+	t.Setenv("SERVER_TRUSTED_PROXIES", "10.0.0.0/8, 192.168.1.10")
+	cfg := Load()
+	if want := []string{"10.0.0.0/8", "192.168.1.10"}; !reflect.DeepEqual(cfg.Server.TrustedProxies, want) {
+		t.Errorf("TrustedProxies = %v, want %v", cfg.Server.TrustedProxies, want)
+	}
+
+	cfg.Server.TrustedProxies = append(cfg.Server.TrustedProxies, "proxy.internal")
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), `"proxy.internal"`) {
+		t.Errorf("Expected invalid proxy to be reported, got %v", err)
+	}
+}
+
+func TestValidateTLS(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr string
+	}{
+		{"cert without key", func(c *Config) { c.Server.TLS.CertFile = "cert.pem" }, "needs both cert_file and key_file"},
+		{"h2c with tls", func(c *Config) {
+			c.Server.TLS.CertFile, c.Server.TLS.KeyFile = "cert.pem", "key.pem"
+			c.Server.H2C = true
+		}, "h2c cannot be combined"},
+		{"redirect on same port", func(c *Config) {
+			c.Server.TLS.CertFile, c.Server.TLS.KeyFile = "cert.pem", "key.pem"
+			c.Server.TLS.RedirectPort = c.Port
+		}, "redirect_port"},
+		{"redirect without tls", func(c *Config) { c.Server.TLS.RedirectPort = "8081" }, "requires cert_file"},
+		{"valid tls", func(c *Config) {
+			c.Server.TLS.CertFile, c.Server.TLS.KeyFile = "cert.pem", "key.pem"
+			c.Server.TLS.RedirectPort = "8081"
+		}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected valid config, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"postgres://app:s3cret@db:5432/app?sslmode=disable", "postgres://app:REDACTED@db:5432/app?sslmode=disable"},
+		{"postgres://db/app?password=s3cret&user=app", "postgres://db/app?password=REDACTED&user=app"},
+		{"postgres://app@db/app", "postgres://app@db/app"},
+		{"sqlite:///var/lib/app.db", "sqlite:///var/lib/app.db"},
+	}
+
+	for _, tt := range tests {
+		cfg := Default()
+		cfg.DatabaseURL = tt.url
+		cfg.JWTSecret = "very-secret"
+
+		r := cfg.Redacted()
+		if r.DatabaseURL != tt.want {
+			t.Errorf("Expected %q, got %q", tt.want, r.DatabaseURL)
+		}
+		if r.JWTSecret != "REDACTED" {
+			t.Errorf("Expected JWT secret to be redacted, got %q", r.JWTSecret)
+		}
+		if cfg.JWTSecret != "very-secret" || cfg.DatabaseURL != tt.url {
+			t.Error("Expected the original config to be unchanged")
+		}
+	}
+}
+
+func TestLoadFileFeatureFlags(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "config.yaml", `
+feature_flags:
+  refresh_interval: 30s
+  flags:
+    search:
+      enabled: true
+    new-dashboard:
+      enabled: true
+      percentage: 10
+      users: [7]
+      roles: [admin]
+`)
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if cfg.FeatureFlags.RefreshInterval != 30*time.Second {
+		t.Errorf("Expected 30s refresh interval, got %v", cfg.FeatureFlags.RefreshInterval)
+	}
+	if p := cfg.FeatureFlags.Flags["search"].Percentage; p != nil {
+		t.Errorf("Expected percentage to be unset, got %d", *p)
+	}
+	dashboard := cfg.FeatureFlags.Flags["new-dashboard"]
+	if dashboard.Percentage == nil || *dashboard.Percentage != 10 || len(dashboard.Users) != 1 || len(dashboard.Roles) != 1 {
+		t.Errorf("Expected the dashboard rollout to be loaded, got %+v", dashboard)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid config, got %v", err)
+	}
+
+	tooMuch := 150
+	cfg.FeatureFlags.Flags["search"] = FlagConfig{Enabled: true, Percentage: &tooMuch}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "feature_flags.flags.search.percentage") {
+		t.Errorf("Expected percentage to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,200 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ValidationError lists every problem found in a Config
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid config: " + strings.Join(e.Problems, "; ")
+}
+
+// Validate checks the config and returns a *ValidationError describing
+// every problem, or nil when the config is usable. Production refuses
+// default secrets and an empty database URL.
+func (c *Config) Validate() error {
+	var problems []string
+	addf := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	switch c.Env {
+	case EnvDevelopment, EnvStaging, EnvProduction, "test":
+	default:
+		addf("env %q is not one of development, staging, production, test", c.Env)
+	}
+
+	if !validPort(c.Port) {
+		addf("port %q is not a valid TCP port", c.Port)
+	}
+
+	if c.JWTSecret == "" {
+		addf("jwt_secret must not be empty")
+	}
+
+	if c.Server.ReadHeaderTimeout < 0 || c.Server.ReadTimeout < 0 || c.Server.WriteTimeout < 0 || c.Server.IdleTimeout < 0 {
+		addf("server timeouts must not be negative")
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		addf("server.shutdown_timeout must be positive")
+	}
+	if c.Server.DrainPeriod < 0 {
+		addf("server.drain_period must not be negative")
+	}
+	if c.Server.MaxHeaderBytes < 0 {
+		addf("server.max_header_bytes must not be negative")
+	}
+	if c.Server.MaxBodyBytes < 0 {
+		addf("server.max_body_bytes must not be negative")
+	}
+	for _, proxy := range c.Server.TrustedProxies {
+		if !validIPOrCIDR(proxy) {
+			addf("server.trusted_proxies entry %q is not an IP address or CIDR", proxy)
+		}
+	}
+	if tls := c.Server.TLS; tls.Enabled() {
+		if tls.CertFile == "" || tls.KeyFile == "" {
+			addf("server.tls needs both cert_file and key_file")
+		}
+		if tls.ReloadInterval <= 0 {
+			addf("server.tls.reload_interval must be positive")
+		}
+		if c.Server.H2C {
+			addf("server.h2c cannot be combined with TLS")
+		}
+		if tls.RedirectPort != "" && (!validPort(tls.RedirectPort) || tls.RedirectPort == c.Port) {
+			addf("server.tls.redirect_port %q must be a valid TCP port other than port", tls.RedirectPort)
+		}
+	} else if c.Server.TLS.RedirectPort != "" {
+		addf("server.tls.redirect_port requires cert_file and key_file")
+	}
+
+	if c.Database.MaxOpenConns < 0 || c.Database.MaxIdleConns < 0 {
+		addf("database pool sizes must not be negative")
+	}
+	if c.Database.MaxOpenConns > 0 && c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		addf("database.max_idle_conns (%d) exceeds max_open_conns (%d)", c.Database.MaxIdleConns, c.Database.MaxOpenConns)
+	}
+
+	if c.JWT.AccessTokenTTL <= 0 {
+		addf("jwt.access_token_ttl must be positive")
+	}
+	if c.JWT.RefreshTokenTTL < c.JWT.AccessTokenTTL {
+		addf("jwt.refresh_token_ttl must not be shorter than access_token_ttl")
+	}
+
+	switch strings.ToLower(c.Log.Level) {
+	case "debug", "info", "warn", "error":
+	default:
+		addf("log.level %q is not one of debug, info, warn, error", c.Log.Level)
+	}
+	switch strings.ToLower(c.Log.Format) {
+	case "json", "text":
+	default:
+		addf("log.format %q is not one of json, text", c.Log.Format)
+	}
+
+	if c.Metrics.Enabled {
+		if !strings.HasPrefix(c.Metrics.Path, "/") {
+			addf("metrics.path %q must start with /", c.Metrics.Path)
+		}
+		if c.Metrics.AdminPort != "" && !validPort(c.Metrics.AdminPort) {
+			addf("metrics.admin_port %q is not a valid TCP port", c.Metrics.AdminPort)
+		}
+		if c.Metrics.AdminPort == c.Port {
+			addf("metrics.admin_port must differ from port")
+		}
+	}
+
+	if c.RateLimit.Enabled {
+		if b := c.RateLimit.API; b.RequestsPerMinute <= 0 || b.Burst <= 0 {
+			addf("rate_limit.api requests_per_minute and burst must be positive")
+		}
+		if b := c.RateLimit.Auth; b.RequestsPerMinute <= 0 || b.Burst <= 0 {
+			addf("rate_limit.auth requests_per_minute and burst must be positive")
+		}
+	}
+
+	if c.Idempotency.Enabled {
+		if c.Idempotency.Store != "sql" && c.Idempotency.Store != "memory" {
+			addf("idempotency.store %q is not one of sql, memory", c.Idempotency.Store)
+		}
+		if c.Idempotency.TTL <= 0 {
+			addf("idempotency.ttl must be positive")
+		}
+	}
+
+	if c.Compression.Enabled && c.Compression.MinSize < 0 {
+		addf("compression.min_size must not be negative")
+	}
+
+	if j := c.Jobs; j.Enabled {
+		if j.Workers <= 0 || j.MaxAttempts <= 0 {
+			addf("jobs.workers and jobs.max_attempts must be positive")
+		}
+		if j.PollInterval <= 0 || j.LockTimeout <= 0 || j.Retention <= 0 {
+			addf("jobs.poll_interval, jobs.lock_timeout and jobs.retention must be positive")
+		}
+		if j.BackoffBase <= 0 || j.BackoffMax < j.BackoffBase {
+			addf("jobs.backoff_base must be positive and not above jobs.backoff_max")
+		}
+	}
+
+	if a := c.Audit; a.Enabled {
+		if a.BufferSize <= 0 || a.BatchSize <= 0 || a.FlushInterval <= 0 {
+			addf("audit.buffer_size, audit.batch_size and audit.flush_interval must be positive")
+		}
+	}
+
+	if c.FeatureFlags.RefreshInterval <= 0 {
+		addf("feature_flags.refresh_interval must be positive")
+	}
+	for _, name := range slices.Sorted(maps.Keys(c.FeatureFlags.Flags)) {
+		if p := c.FeatureFlags.Flags[name].Percentage; p != nil && (*p < 0 || *p > 100) {
+			addf("feature_flags.flags.%s.percentage must be between 0 and 100", name)
+		}
+	}
+
+	if c.IsProduction() {
+		if c.DatabaseURL == "" {
+			addf("database_url must be set in production")
+		} else if c.DatabaseURL == defaultDatabaseURL {
+			addf("database_url must not use the development default in production")
+		}
+		if c.JWTSecret == defaultJWTSecret {
+			addf("jwt_secret must not use the default value in production")
+		}
+		for _, origin := range c.AllowedOrigins() {
+			if origin == "*" && c.CORS.AllowCredentials {
+				addf("cors_origins must not be \"*\" when credentials are allowed in production")
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+func validIPOrCIDR(s string) bool {
+	if _, err := netip.ParsePrefix(s); err == nil {
+		return true
+	}
+	_, err := netip.ParseAddr(s)
+	return err == nil
+}
+
+func validPort(s string) bool {
+	port, err := strconv.Atoi(s)
+	return err == nil && port >= 1 && port <= 65535
+}
@@ -0,0 +1,159 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/config"
+)
+
+// Dialect identifies the SQL flavour of a connection
+type Dialect string
+
+// Supported dialects; the values double as database/sql driver names
+const (
+	Postgres Dialect = "postgres"
+	SQLite   Dialect = "sqlite3"
+)
+
+// DB wraps *sql.DB together with its dialect
+type DB struct {
+	*sql.DB
+	Dialect Dialect
+}
+
+// Open connects to Config.DatabaseURL and applies the pool settings from the database section
+func Open(cfg *config.Config) (*DB, error) {
+	db, err := OpenURL(cfg.DatabaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// An in-memory SQLite database only exists on its one connection
+	if !isMemory(cfg.DatabaseURL) {
+		db.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+		db.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+	}
+	db.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.Database.ConnMaxIdleTime)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("database: ping: %w", err)
+	}
+	return db, nil
+}
+
+// OpenURL opens a connection without pinging it or tuning the pool.
+// Postgres URLs start with postgres:// or postgresql://; SQLite URLs are
+// sqlite://path, sqlite3://path or file:path.
+func OpenURL(url string) (*DB, error) {
+	dialect, dsn, err := ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := sql.Open(string(dialect), dsn)
+	if err != nil {
+		return nil, fmt.Errorf("database: open: %w", err)
+	}
+	if isMemory(url) {
+		sqlDB.SetMaxOpenConns(1)
+	}
+	return &DB{DB: sqlDB, Dialect: dialect}, nil
+}
+
+// sqliteDefaults are added to every SQLite DSN unless the URL sets them;
+// each entry lists the driver's aliases for the same option
+var sqliteDefaults = []struct {
+	keys  []string
+	value string
+}{
+	{[]string{"_foreign_keys", "_fk"}, "on"},
+	{[]string{"_busy_timeout", "_timeout"}, "5000"},
+}
+
+// ParseURL returns the dialect and driver-specific DSN for a database URL
+func ParseURL(dbURL string) (Dialect, string, error) {
+	switch {
+	case dbURL == "":
+		return "", "", fmt.Errorf("database: empty database URL")
+	case strings.HasPrefix(dbURL, "postgres://"), strings.HasPrefix(dbURL, "postgresql://"):
+		return Postgres, dbURL, nil
+	case strings.HasPrefix(dbURL, "sqlite://"), strings.HasPrefix(dbURL, "sqlite3://"), strings.HasPrefix(dbURL, "file:"):
+		dsn := dbURL
+		if _, rest, ok := strings.Cut(dbURL, "://"); ok {
+			dsn = rest
+		}
+		path, rawQuery, _ := strings.Cut(dsn, "?")
+		query, err := url.ParseQuery(rawQuery)
+		if err != nil {
+			return "", "", fmt.Errorf("database: invalid SQLite options in %q: %w", dbURL, err)
+		}
+		for _, d := range sqliteDefaults {
+			if !hasAny(query, d.keys) {
+				query.Set(d.keys[0], d.value)
+			}
+		}
+		return SQLite, path + "?" + query.Encode(), nil
+	default:
+		return "", "", fmt.Errorf("database: unsupported database URL scheme in %q", dbURL)
+	}
+}
+
+func hasAny(query url.Values, keys []string) bool {
+	for _, k := range keys {
+		if query.Has(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// Rebind converts "?" placeholders to the dialect's style ($1, $2, ... for Postgres)
+func (d Dialect) Rebind(query string) string {
+	if d != Postgres {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Rebind converts "?" placeholders for the connection's dialect
+func (db *DB) Rebind(query string) string {
+	return db.Dialect.Rebind(query)
+}
+
+func isMemory(url string) bool {
+	return strings.Contains(url, ":memory:") || strings.Contains(url, "mode=memory")
+}
+
+// IsUniqueViolation reports whether err comes from a UNIQUE or PRIMARY KEY constraint
+func IsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23505"
+	}
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique ||
+			sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey
+	}
+	return false
+}
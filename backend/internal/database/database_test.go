@@ -0,0 +1,49 @@
+package database
+
+import "testing"
+
+func TestParseURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		dialect Dialect
+		dsn     string
+	}{
+		{"postgres://app@db/app?sslmode=disable", Postgres, "postgres://app@db/app?sslmode=disable"},
+		{"sqlite://app.db", SQLite, "app.db?_busy_timeout=5000&_foreign_keys=on"},
+		{"sqlite://app.db?cache=shared", SQLite, "app.db?_busy_timeout=5000&_foreign_keys=on&cache=shared"},
+		{"sqlite3://app.db?_fk=off&_timeout=100", SQLite, "app.db?_fk=off&_timeout=100"},
+		{"file::memory:?cache=shared", SQLite, "file::memory:?_busy_timeout=5000&_foreign_keys=on&cache=shared"},
+	}
+
+	for _, tt := range tests {
+		dialect, dsn, err := ParseURL(tt.url)
+		if err != nil {
+			t.Errorf("ParseURL(%q) error = %v", tt.url, err)
+			continue
+		}
+		if dialect != tt.dialect || dsn != tt.dsn {
+			t.Errorf("ParseURL(%q) = %s, %q, want %s, %q", tt.url, dialect, dsn, tt.dialect, tt.dsn)
+		}
+	}
+
+	if _, _, err := ParseURL("mysql://db/app"); err == nil {
+		t.Error("Expected an error for an unsupported scheme")
+	}
+}
+
+// Foreign keys must be enforced even when the URL carries other options
+func TestSQLiteForeignKeysWithOptions(t *testing.T) {
+	db, err := OpenURL("sqlite://" + t.TempDir() + "/fk.db?cache=shared")
+	if err != nil {
+		t.Fatalf("OpenURL() error = %v", err)
+	}
+	defer db.Close()
+
+	var on int
+	if err := db.QueryRow("PRAGMA foreign_keys").Scan(&on); err != nil {
+		t.Fatalf("PRAGMA foreign_keys error = %v", err)
+	}
+	if on != 1 {
+		t.Error("Expected foreign keys to be on")
+	}
+}
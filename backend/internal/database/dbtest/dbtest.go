@@ -0,0 +1,33 @@
+// Package dbtest opens throwaway databases for tests. It lives apart from
+// database so production builds never link the testing package.
+package dbtest
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/database"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/migrate"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/migrations"
+)
+
+// Open returns a SQLite database in a temporary directory with every
+// migration applied. It is closed when the test finishes.
+func Open(t testing.TB) *database.DB {
+	t.Helper()
+	db, err := database.OpenURL("sqlite://" + filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	m, err := migrate.New(db, migrations.FS)
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+	if _, err := m.Up(context.Background()); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	return db
+}
@@ -0,0 +1,114 @@
+package featureflags
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/config"
+)
+
+func TestFlagEvaluate(t *testing.T) {
+	rollout := Flag{Name: "beta", Enabled: true, Percentage: 0, Users: []int{7}, Roles: []string{"admin"}}
+
+	tests := []struct {
+		name string
+		flag Flag
+		subj Subject
+		want bool
+	}{
+		{"disabled", Flag{Name: "off", Percentage: 100}, Subject{UserID: 1}, false},
+		{"disabled ignores allowlist", Flag{Name: "off", Users: []int{1}}, Subject{UserID: 1}, false},
+		{"everyone", Flag{Name: "on", Enabled: true, Percentage: 100}, Subject{}, true},
+		{"allowlisted user", rollout, Subject{UserID: 7}, true},
+		{"allowlisted role", rollout, Subject{UserID: 8, Roles: []string{"user", "admin"}}, true},
+		{"outside rollout", rollout, Subject{UserID: 8, Roles: []string{"user"}}, false},
+		{"anonymous below 100", Flag{Name: "half", Enabled: true, Percentage: 99}, Subject{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.flag.Evaluate(tt.subj); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPercentageRollout(t *testing.T) {
+	flag := Flag{Name: "new-dashboard", Enabled: true, Percentage: 25}
+	on := map[int]bool{}
+	for id := 1; id <= 4000; id++ {
+		on[id] = flag.Evaluate(Subject{UserID: id})
+	}
+
+	count := 0
+	for _, v := range on {
+		if v {
+			count++
+		}
+	}
+	if count < 800 || count > 1200 {
+		t.Errorf("Expected about 1000 of 4000 users at 25%%, got %d", count)
+	}
+
+	// Raising the percentage only adds users
+	flag.Percentage = 50
+	for id, was := range on {
+		if was && !flag.Evaluate(Subject{UserID: id}) {
+			t.Fatalf("Expected user %d to keep the flag when the rollout grows", id)
+		}
+	}
+
+	if Bucket("a", 42) != Bucket("a", 42) {
+		t.Error("Expected buckets to be stable")
+	}
+	differs := false
+	for id := 1; id <= 20 && !differs; id++ {
+		differs = Bucket("a", id) != Bucket("b", id)
+	}
+	if !differs {
+		t.Error("Expected flags to bucket users differently")
+	}
+}
+
+func TestFlagValidate(t *testing.T) {
+	tests := []struct {
+		flag    Flag
+		wantErr bool
+	}{
+		{Flag{Name: "new-dashboard.v2_beta", Percentage: 50}, false},
+		{Flag{Name: "", Percentage: 50}, true},
+		{Flag{Name: "New Dashboard"}, true},
+		{Flag{Name: "-leading"}, true},
+		{Flag{Name: "ok", Percentage: 101}, true},
+		{Flag{Name: "ok", Percentage: -1}, true},
+	}
+
+	for _, tt := range tests {
+		err := tt.flag.Validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%+v): expected error %v, got %v", tt.flag, tt.wantErr, err)
+		}
+		if err != nil && !errors.Is(err, ErrInvalid) {
+			t.Errorf("Expected ErrInvalid, got %v", err)
+		}
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	ten := 10
+	flags := FromConfig(config.FeatureFlagsConfig{Flags: map[string]config.FlagConfig{
+		"search":    {Enabled: true},
+		"dashboard": {Enabled: true, Percentage: &ten, Roles: []string{"admin"}},
+	}})
+
+	if len(flags) != 2 || flags[0].Name != "dashboard" || flags[1].Name != "search" {
+		t.Fatalf("Expected flags sorted by name, got %+v", flags)
+	}
+	if flags[0].Percentage != 10 || flags[0].Roles[0] != "admin" {
+		t.Errorf("Expected the configured rollout, got %+v", flags[0])
+	}
+	if flags[1].Percentage != 100 {
+		t.Errorf("Expected unset percentage to mean 100, got %d", flags[1].Percentage)
+	}
+}
@@ -0,0 +1,233 @@
+package featureflags
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Source tells where a flag's current definition comes from
+type Source string
+
+// Sources of a flag definition
+const (
+	SourceConfig   Source = "config"
+	SourceOverride Source = "override"
+)
+
+// State is a flag's current definition and where it came from
+type State struct {
+	Flag
+	Source    Source     `json:"source"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	UpdatedBy *int       `json:"updated_by,omitempty"`
+}
+
+// Status summarises the flags for diagnostics. It is served without
+// authentication, so it leaves out allowlists, editors and error text;
+// List has the full definitions.
+type Status struct {
+	Flags       []Summary  `json:"flags"`
+	RefreshedAt *time.Time `json:"refreshed_at,omitempty"`
+	// RefreshFailed is set when the last load of overrides failed;
+	// evaluation keeps using the overrides loaded before it
+	RefreshFailed bool `json:"refresh_failed"`
+}
+
+// Summary is the public part of a flag's State
+type Summary struct {
+	Name       string `json:"name"`
+	Enabled    bool   `json:"enabled"`
+	Percentage int    `json:"percentage"`
+	Source     Source `json:"source"`
+}
+
+// Set evaluates flags: the configured defaults with the store's overrides
+// on top. Overrides made through this Set apply immediately; those made by
+// other replicas arrive with the next Refresh.
+type Set struct {
+	store    Store
+	defaults map[string]Flag
+	now      func() time.Time
+
+	mu          sync.RWMutex
+	overrides   map[string]Override
+	refreshedAt time.Time
+	refreshErr  error
+}
+
+// New creates a Set from the configured flags. A nil store keeps overrides
+// in memory. Call Refresh to load existing overrides.
+func New(defaults []Flag, store Store) (*Set, error) {
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	s := &Set{
+		store:     store,
+		defaults:  make(map[string]Flag, len(defaults)),
+		now:       time.Now,
+		overrides: map[string]Override{},
+	}
+	for _, f := range defaults {
+		if err := f.Validate(); err != nil {
+			return nil, err
+		}
+		if _, dup := s.defaults[f.Name]; dup {
+			return nil, fmt.Errorf("%w: %s is defined twice", ErrInvalid, f.Name)
+		}
+		s.defaults[f.Name] = f
+	}
+	return s, nil
+}
+
+// Enabled reports whether the named flag is on for subj; unknown flags are off
+func (s *Set) Enabled(name string, subj Subject) bool {
+	st, ok := s.Get(name)
+	return ok && st.Evaluate(subj)
+}
+
+// Evaluate returns every flag's value for subj
+func (s *Set) Evaluate(subj Subject) map[string]bool {
+	states := s.List()
+	values := make(map[string]bool, len(states))
+	for _, st := range states {
+		values[st.Name] = st.Evaluate(subj)
+	}
+	return values
+}
+
+// Get returns the current definition of name
+func (s *Set) Get(name string) (State, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.state(name)
+}
+
+func (s *Set) state(name string) (State, bool) {
+	if o, ok := s.overrides[name]; ok {
+		at := o.UpdatedAt
+		return State{Flag: o.Flag, Source: SourceOverride, UpdatedAt: &at, UpdatedBy: o.UpdatedBy}, true
+	}
+	if f, ok := s.defaults[name]; ok {
+		return State{Flag: f, Source: SourceConfig}, true
+	}
+	return State{}, false
+}
+
+// List returns every flag sorted by name
+func (s *Set) List() []State {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.defaults)+len(s.overrides))
+	for name := range s.defaults {
+		names = append(names, name)
+	}
+	for name := range s.overrides {
+		if _, ok := s.defaults[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	states := make([]State, len(names))
+	for i, name := range names {
+		states[i], _ = s.state(name)
+	}
+	return states
+}
+
+// Override stores f as the definition of f.Name, replacing the configured
+// one. actor is the admin making the change, nil for scripts.
+func (s *Set) Override(ctx context.Context, f Flag, actor *int) (State, error) {
+	if err := f.Validate(); err != nil {
+		return State{}, err
+	}
+	o := Override{Flag: f, UpdatedAt: s.now().UTC(), UpdatedBy: actor}
+	if err := s.store.Put(ctx, o); err != nil {
+		return State{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.overrides[f.Name] = o
+	st, _ := s.state(f.Name)
+	return st, nil
+}
+
+// Reset removes the override of name, going back to the configured
+// definition, and reports whether there was one
+func (s *Set) Reset(ctx context.Context, name string) (bool, error) {
+	existed, err := s.store.Delete(ctx, name)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.overrides[name]; ok {
+		existed = true
+	}
+	delete(s.overrides, name)
+	return existed, nil
+}
+
+// Refresh reloads the overrides from the store. On failure the previous
+// overrides stay in effect.
+func (s *Set) Refresh(ctx context.Context) error {
+	list, err := s.store.List(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refreshErr = err
+	if err != nil {
+		return err
+	}
+	overrides := make(map[string]Override, len(list))
+	for _, o := range list {
+		// A row this version cannot use must not break the others
+		if o.Flag.Validate() == nil {
+			overrides[o.Flag.Name] = o
+		}
+	}
+	s.overrides = overrides
+	s.refreshedAt = s.now().UTC()
+	return nil
+}
+
+// Watch calls Refresh every interval until ctx is done. onError, if not
+// nil, is told about every failure.
+func (s *Set) Watch(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Refresh(ctx); err != nil && onError != nil && ctx.Err() == nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// Status returns every flag and the outcome of the last refresh
+func (s *Set) Status() Status {
+	states := s.List()
+	st := Status{Flags: make([]Summary, len(states))}
+	for i, f := range states {
+		st.Flags[i] = Summary{Name: f.Name, Enabled: f.Enabled, Percentage: f.Percentage, Source: f.Source}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if !s.refreshedAt.IsZero() {
+		at := s.refreshedAt
+		st.RefreshedAt = &at
+	}
+	st.RefreshFailed = s.refreshErr != nil
+	return st
+}
@@ -0,0 +1,174 @@
+package featureflags
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/database/dbtest"
+)
+
+var testDefaults = []Flag{
+	{Name: "search", Enabled: true, Percentage: 100},
+	{Name: "dashboard", Enabled: false, Percentage: 100},
+}
+
+func TestNewRejectsInvalidDefaults(t *testing.T) {
+	if _, err := New([]Flag{{Name: "Bad Name"}}, nil); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Expected ErrInvalid for a bad name, got %v", err)
+	}
+	if _, err := New([]Flag{{Name: "a"}, {Name: "a"}}, nil); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Expected ErrInvalid for a duplicate, got %v", err)
+	}
+}
+
+func TestSetOverrideAndReset(t *testing.T) {
+	ctx := context.Background()
+	s, err := New(testDefaults, nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	user := Subject{UserID: 1}
+
+	if !s.Enabled("search", user) || s.Enabled("dashboard", user) || s.Enabled("unknown", user) {
+		t.Fatalf("Expected configured values, got %v", s.Evaluate(user))
+	}
+
+	admin := 9
+	st, err := s.Override(ctx, Flag{Name: "dashboard", Enabled: true, Percentage: 100}, &admin)
+	if err != nil {
+		t.Fatalf("Override() error = %v", err)
+	}
+	if st.Source != SourceOverride || st.UpdatedBy == nil || *st.UpdatedBy != admin || st.UpdatedAt == nil {
+		t.Errorf("Expected override state with actor, got %+v", st)
+	}
+	if !s.Enabled("dashboard", user) {
+		t.Error("Expected the override to apply immediately")
+	}
+
+	if _, err := s.Override(ctx, Flag{Name: "experiment", Enabled: true, Percentage: 100}, nil); err != nil {
+		t.Fatalf("Override() error = %v", err)
+	}
+	list := s.List()
+	if len(list) != 3 || list[0].Name != "dashboard" || list[1].Name != "experiment" || list[2].Name != "search" {
+		t.Errorf("Expected three flags sorted by name, got %+v", list)
+	}
+
+	if _, err := s.Override(ctx, Flag{Name: "search", Percentage: 200}, nil); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Expected ErrInvalid, got %v", err)
+	}
+
+	existed, err := s.Reset(ctx, "dashboard")
+	if err != nil || !existed {
+		t.Fatalf("Reset() = %v, %v", existed, err)
+	}
+	if st, _ := s.Get("dashboard"); st.Source != SourceConfig || s.Enabled("dashboard", user) {
+		t.Errorf("Expected the configured definition back, got %+v", st)
+	}
+	if existed, _ := s.Reset(ctx, "search"); existed {
+		t.Error("Expected no override to reset for a configured flag")
+	}
+}
+
+func TestSetSharesOverridesThroughSQLStore(t *testing.T) {
+	ctx := context.Background()
+	db := dbtest.Open(t)
+	replicaA, _ := New(testDefaults, NewSQLStore(db))
+	replicaB, _ := New(testDefaults, NewSQLStore(db))
+	user := Subject{UserID: 1}
+
+	admin := 9
+	if _, err := replicaA.Override(ctx, Flag{Name: "dashboard", Enabled: true, Percentage: 100, Roles: []string{"admin"}}, &admin); err != nil {
+		t.Fatalf("Override() error = %v", err)
+	}
+	if replicaB.Enabled("dashboard", user) {
+		t.Fatal("Expected the other replica to see the change only after a refresh")
+	}
+	if err := replicaB.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	st, _ := replicaB.Get("dashboard")
+	if !replicaB.Enabled("dashboard", user) || st.UpdatedBy == nil || *st.UpdatedBy != admin || len(st.Roles) != 1 {
+		t.Errorf("Expected the override to be loaded, got %+v", st)
+	}
+
+	// Updating an existing override replaces it
+	if _, err := replicaA.Override(ctx, Flag{Name: "dashboard", Percentage: 100}, nil); err != nil {
+		t.Fatalf("Override() error = %v", err)
+	}
+	replicaB.Refresh(ctx)
+	if st, _ := replicaB.Get("dashboard"); st.Enabled || st.UpdatedBy != nil {
+		t.Errorf("Expected the replaced override, got %+v", st)
+	}
+
+	if existed, err := replicaA.Reset(ctx, "dashboard"); err != nil || !existed {
+		t.Fatalf("Reset() = %v, %v", existed, err)
+	}
+	replicaB.Refresh(ctx)
+	if st, _ := replicaB.Get("dashboard"); st.Source != SourceConfig {
+		t.Errorf("Expected the reset to reach the other replica, got %+v", st)
+	}
+}
+
+type failingStore struct {
+	*MemoryStore
+	err error
+}
+
+func (s *failingStore) List(ctx context.Context) ([]Override, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.MemoryStore.List(ctx)
+}
+
+func TestRefreshFailureKeepsOverrides(t *testing.T) {
+	ctx := context.Background()
+	store := &failingStore{MemoryStore: NewMemoryStore()}
+	s, _ := New(testDefaults, store)
+
+	s.Override(ctx, Flag{Name: "dashboard", Enabled: true, Percentage: 100}, nil)
+	if err := s.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if status := s.Status(); status.RefreshedAt == nil || status.RefreshFailed || len(status.Flags) != 2 {
+		t.Errorf("Expected a successful refresh in the status, got %+v", status)
+	}
+
+	store.err = errors.New("database down")
+	if err := s.Refresh(ctx); err == nil {
+		t.Fatal("Expected Refresh to fail")
+	}
+	if !s.Enabled("dashboard", Subject{UserID: 1}) {
+		t.Error("Expected the last loaded overrides to stay in effect")
+	}
+	if status := s.Status(); !status.RefreshFailed {
+		t.Errorf("Expected the failure in the status, got %+v", status)
+	}
+}
+
+// Status is served on the unauthenticated readiness probe
+func TestStatusHidesPrivateFields(t *testing.T) {
+	ctx := context.Background()
+	store := &failingStore{MemoryStore: NewMemoryStore()}
+	s, _ := New(testDefaults, store)
+	admin := 9
+	s.Override(ctx, Flag{Name: "dashboard", Enabled: true, Percentage: 10, Users: []int{4242}, Roles: []string{"beta"}}, &admin)
+	store.err = errors.New("dial tcp 10.0.0.5:5432: connection refused")
+	s.Refresh(ctx)
+
+	raw, err := json.Marshal(s.Status())
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	for _, secret := range []string{"4242", "beta", "updated_by", "10.0.0.5"} {
+		if strings.Contains(string(raw), secret) {
+			t.Errorf("Expected status to leave out %q, got %s", secret, raw)
+		}
+	}
+	if !strings.Contains(string(raw), `"refresh_failed":true`) || !strings.Contains(string(raw), `"percentage":10`) {
+		t.Errorf("Expected the public summary, got %s", raw)
+	}
+}
@@ -0,0 +1,137 @@
+package featureflags
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/database"
+)
+
+// Override replaces the configured definition of a flag
+type Override struct {
+	Flag      Flag
+	UpdatedAt time.Time
+	// UpdatedBy is the admin who made the change, nil for scripts
+	UpdatedBy *int
+}
+
+// Store keeps runtime overrides
+type Store interface {
+	List(ctx context.Context) ([]Override, error)
+	Put(ctx context.Context, o Override) error
+	// Delete reports whether an override existed
+	Delete(ctx context.Context, name string) (bool, error)
+}
+
+// MemoryStore keeps overrides in process; they are lost on restart and not
+// shared between replicas
+type MemoryStore struct {
+	mu        sync.Mutex
+	overrides map[string]Override
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{overrides: map[string]Override{}}
+}
+
+// List returns every override
+func (s *MemoryStore) List(context.Context) ([]Override, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := make([]Override, 0, len(s.overrides))
+	for _, o := range s.overrides {
+		list = append(list, o)
+	}
+	return list, nil
+}
+
+// Put creates or replaces the override of o.Flag.Name
+func (s *MemoryStore) Put(_ context.Context, o Override) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.overrides[o.Flag.Name] = o
+	return nil
+}
+
+// Delete removes the override of name
+func (s *MemoryStore) Delete(_ context.Context, name string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.overrides[name]
+	delete(s.overrides, name)
+	return ok, nil
+}
+
+// SQLStore keeps overrides in the feature_flags table so every replica sees them
+type SQLStore struct {
+	db *database.DB
+}
+
+// NewSQLStore creates a store on db; the feature_flags migration must be applied
+func NewSQLStore(db *database.DB) *SQLStore {
+	return &SQLStore{db: db}
+}
+
+// List returns every override
+func (s *SQLStore) List(ctx context.Context) ([]Override, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT definition, updated_at, updated_by FROM feature_flags")
+	if err != nil {
+		return nil, fmt.Errorf("featureflags: list overrides: %w", err)
+	}
+	defer rows.Close()
+
+	var list []Override
+	for rows.Next() {
+		var (
+			o          Override
+			definition string
+			updatedBy  *int64
+		)
+		if err := rows.Scan(&definition, &o.UpdatedAt, &updatedBy); err != nil {
+			return nil, fmt.Errorf("featureflags: scan override: %w", err)
+		}
+		if err := json.Unmarshal([]byte(definition), &o.Flag); err != nil {
+			return nil, fmt.Errorf("featureflags: decode override: %w", err)
+		}
+		if updatedBy != nil {
+			id := int(*updatedBy)
+			o.UpdatedBy = &id
+		}
+		list = append(list, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("featureflags: list overrides: %w", err)
+	}
+	return list, nil
+}
+
+// Put creates or replaces the override of o.Flag.Name
+func (s *SQLStore) Put(ctx context.Context, o Override) error {
+	definition, err := json.Marshal(o.Flag)
+	if err != nil {
+		return fmt.Errorf("featureflags: encode override: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx, s.db.Rebind(`INSERT INTO feature_flags (name, definition, updated_at, updated_by)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET definition = excluded.definition,
+			updated_at = excluded.updated_at, updated_by = excluded.updated_by`),
+		o.Flag.Name, string(definition), o.UpdatedAt.UTC(), o.UpdatedBy)
+	if err != nil {
+		return fmt.Errorf("featureflags: store override: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the override of name
+func (s *SQLStore) Delete(ctx context.Context, name string) (bool, error) {
+	res, err := s.db.ExecContext(ctx, s.db.Rebind("DELETE FROM feature_flags WHERE name = ?"), name)
+	if err != nil {
+		return false, fmt.Errorf("featureflags: delete override: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
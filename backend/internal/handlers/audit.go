@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/apierror"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/audit"
+)
+
+// AuditLister is the part of the audit store the admin endpoint reads
+type AuditLister interface {
+	List(ctx context.Context, f audit.Filter) ([]*audit.Entry, error)
+}
+
+// AuditQuery holds the filters and paging of GET /admin/audit
+type AuditQuery struct {
+	ActorID    int       `form:"actor_id" binding:"omitempty,min=1" description:"Only requests by this user"`
+	ResourceID string    `form:"resource_id" description:"Only requests on this resource"`
+	Route      string    `form:"route" description:"Route pattern, e.g. /api/v1/auth/register"`
+	Method     string    `form:"method" binding:"omitempty,oneof=POST PUT PATCH DELETE"`
+	Outcome    string    `form:"outcome" binding:"omitempty,oneof=success failure"`
+	Since      time.Time `form:"since" description:"RFC 3339 time, inclusive"`
+	Until      time.Time `form:"until" description:"RFC 3339 time, exclusive"`
+	Cursor     int64     `form:"cursor" binding:"omitempty,min=1" description:"next_cursor of the previous page"`
+	Limit      int       `form:"limit" binding:"omitempty,min=1,max=500" description:"Page size, default 50"`
+}
+
+// AuditLogResponse is one page of audit entries, newest first
+type AuditLogResponse struct {
+	Entries []*audit.Entry `json:"entries"`
+	// NextCursor is absent on the last page
+	NextCursor *int64 `json:"next_cursor,omitempty"`
+}
+
+// AuditHandler serves the audit log admin endpoint
+type AuditHandler struct {
+	audit AuditLister
+}
+
+// NewAuditHandler creates an AuditHandler reading from store
+func NewAuditHandler(store AuditLister) *AuditHandler {
+	return &AuditHandler{audit: store}
+}
+
+// List returns a page of audit entries matching the query
+func (h *AuditHandler) List(c *gin.Context) {
+	var q AuditQuery
+	if !bindQuery(c, &q) {
+		return
+	}
+	if q.Limit == 0 {
+		q.Limit = 50
+	}
+
+	// Ask for one extra entry to learn whether another page follows
+	entries, err := h.audit.List(c.Request.Context(), audit.Filter{
+		ActorID:    q.ActorID,
+		ResourceID: q.ResourceID,
+		Route:      q.Route,
+		Method:     q.Method,
+		Outcome:    audit.Outcome(q.Outcome),
+		Since:      q.Since,
+		Until:      q.Until,
+		BeforeID:   q.Cursor,
+		Limit:      q.Limit + 1,
+	})
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+
+	resp := AuditLogResponse{Entries: entries}
+	if len(entries) > q.Limit {
+		resp.Entries = entries[:q.Limit]
+		next := resp.Entries[q.Limit-1].ID
+		resp.NextCursor = &next
+	}
+	if resp.Entries == nil {
+		resp.Entries = []*audit.Entry{}
+	}
+	c.JSON(http.StatusOK, resp)
+}
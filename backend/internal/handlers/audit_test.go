@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/audit"
+)
+
+// fakeAuditLister serves entries with IDs 1..n, newest first
+type fakeAuditLister struct {
+	n      int64
+	filter audit.Filter
+}
+
+func (f *fakeAuditLister) List(_ context.Context, filter audit.Filter) ([]*audit.Entry, error) {
+	f.filter = filter
+	var entries []*audit.Entry
+	for id := f.n; id > 0 && len(entries) < filter.Limit; id-- {
+		if filter.BeforeID == 0 || id < filter.BeforeID {
+			entries = append(entries, &audit.Entry{ID: id})
+		}
+	}
+	return entries, nil
+}
+
+func TestAuditHandlerList(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	lister := &fakeAuditLister{n: 5}
+	router := gin.New()
+	router.GET("/admin/audit", NewAuditHandler(lister).List)
+
+	get := func(query string) (*httptest.ResponseRecorder, AuditLogResponse) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/audit"+query, nil))
+		var resp AuditLogResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w, resp
+	}
+
+	// Page through all five entries two at a time
+	var ids []int64
+	query := "?limit=2"
+	for pages := 0; pages < 5; pages++ {
+		w, resp := get(query)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		for _, e := range resp.Entries {
+			ids = append(ids, e.ID)
+		}
+		if resp.NextCursor == nil {
+			break
+		}
+		query = "?limit=2&cursor=" + strconv.FormatInt(*resp.NextCursor, 10)
+	}
+	if len(ids) != 5 || ids[0] != 5 || ids[4] != 1 {
+		t.Errorf("Expected entries 5..1 across pages, got %v", ids)
+	}
+
+	since := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	w, _ := get("?actor_id=3&method=PATCH&outcome=failure&resource_id=42&since=2025-08-01T00:00:00Z")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	f := lister.filter
+	if f.ActorID != 3 || f.Method != "PATCH" || f.Outcome != audit.OutcomeFailure || f.ResourceID != "42" ||
+		!f.Since.Equal(since) || f.Limit != 51 {
+		t.Errorf("Unexpected filter %+v", f)
+	}
+
+	for _, bad := range []string{"?method=GET", "?outcome=maybe", "?limit=0&cursor=-1", "?since=yesterday"} {
+		if w, _ := get(bad); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", bad, w.Code)
+		}
+	}
+}
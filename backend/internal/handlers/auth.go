@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/apierror"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/auth"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/middleware"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/userdomain"
+)
+
+// RegisterRequest is the body of POST /auth/register
+type RegisterRequest struct {
+	Email    string `json:"email" binding:"required,email,max=255" example:"alice@example.com"`
+	Name     string `json:"name" binding:"required,min=2,max=50" example:"Alice"`
+	Password string `json:"password" binding:"required,strong_password" example:"Password123"`
+}
+
+// LoginRequest is the body of POST /auth/login
+type LoginRequest struct {
+	Email    string `json:"email" binding:"required" example:"alice@example.com"`
+	Password string `json:"password" binding:"required" example:"Password123"`
+}
+
+// RefreshRequest is the body of POST /auth/refresh and /auth/logout
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// TokenResponse is returned after register, login and refresh
+type TokenResponse struct {
+	AccessToken  string           `json:"access_token"`
+	RefreshToken string           `json:"refresh_token"`
+	TokenType    string           `json:"token_type" example:"Bearer"`
+	ExpiresIn    int              `json:"expires_in" example:"900"`
+	User         *userdomain.User `json:"user"`
+}
+
+// AuthHandler serves the /auth endpoints
+type AuthHandler struct {
+	auth *auth.Service
+}
+
+// NewAuthHandler creates an AuthHandler backed by svc
+func NewAuthHandler(svc *auth.Service) *AuthHandler {
+	return &AuthHandler{auth: svc}
+}
+
+// Register creates an account and returns tokens for it
+func (h *AuthHandler) Register(c *gin.Context) {
+	var req RegisterRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	user, pair, err := h.auth.Register(c.Request.Context(), req.Email, req.Name, req.Password)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	middleware.AuditResource(c, strconv.Itoa(user.ID))
+	middleware.AuditChange(c, nil, user)
+	writeTokens(c, http.StatusCreated, user, pair)
+}
+
+// Login exchanges credentials for tokens
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req LoginRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	user, pair, err := h.auth.Login(c.Request.Context(), req.Email, req.Password)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	// The caller is anonymous until now, so the audit entry names the account instead
+	middleware.AuditResource(c, strconv.Itoa(user.ID))
+	writeTokens(c, http.StatusOK, user, pair)
+}
+
+// Refresh exchanges a refresh token for a new token pair
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	user, pair, err := h.auth.Refresh(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	writeTokens(c, http.StatusOK, user, pair)
+}
+
+// Logout revokes a refresh token
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req RefreshRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.auth.Logout(c.Request.Context(), req.RefreshToken); err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Me returns the authenticated user; it must run after RequireAuth
+func (h *AuthHandler) Me(c *gin.Context) {
+	id, ok := middleware.GetUserID(c)
+	if !ok {
+		apierror.Abort(c, apierror.Unauthorized("missing bearer token"))
+		return
+	}
+
+	user, err := h.auth.User(c.Request.Context(), id)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// fail maps auth errors to problems; anything unexpected becomes a 500
+func (h *AuthHandler) fail(c *gin.Context, err error) {
+	var verr *userdomain.ValidationError
+	switch {
+	case errors.As(err, &verr):
+		err = validationError(verr, requestLang(c))
+	case errors.Is(err, auth.ErrEmailTaken):
+		err = apierror.Conflict("email is already registered").WithCode("email_taken").
+			WithFields(apierror.FieldError{Field: "email", Message: "is already registered"})
+	case errors.Is(err, auth.ErrInvalidCredentials):
+		err = apierror.Unauthorized("invalid email or password").WithCode("invalid_credentials")
+	case errors.Is(err, auth.ErrInvalidRefreshToken):
+		err = apierror.Unauthorized("refresh token is invalid or revoked").WithCode("invalid_token")
+	case errors.Is(err, auth.ErrUserNotFound):
+		err = apierror.NotFound("user not found")
+	}
+	apierror.Abort(c, err)
+}
+
+// writeTokens sends a token pair. Responses carrying credentials must not
+// be cached (RFC 6749 section 5.1), which also keeps them out of the
+// idempotency store.
+func writeTokens(c *gin.Context, status int, user *userdomain.User, pair *auth.TokenPair) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+	c.JSON(status, tokenResponse(user, pair))
+}
+
+func tokenResponse(user *userdomain.User, pair *auth.TokenPair) TokenResponse {
+	return TokenResponse{
+		AccessToken:  pair.AccessToken,
+		RefreshToken: pair.RefreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(pair.ExpiresIn / time.Second),
+		User:         user,
+	}
+}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/apierror"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/auth"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/database/dbtest"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/jwtservice"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/middleware"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/repository"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/security"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newAuthRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	db := dbtest.Open(t)
+
+	jwt, _ := jwtservice.NewJWTService("test-secret", jwtservice.DefaultOptions())
+	h := NewAuthHandler(auth.NewService(
+		repository.NewUserRepository(db),
+		repository.NewRefreshTokenRepository(db),
+		security.NewPasswordServiceWithCost(bcrypt.MinCost),
+		jwt,
+	))
+
+	// OptionalAuth covers the whole API in the server, so it does here too
+	router := gin.New()
+	router.Use(middleware.OptionalAuth(jwt))
+	router.POST("/auth/register", h.Register)
+	router.POST("/auth/login", h.Login)
+	router.POST("/auth/refresh", h.Refresh)
+	router.POST("/auth/logout", h.Logout)
+	router.GET("/auth/me", middleware.RequireAuth(jwt), h.Me)
+	return router
+}
+
+func doJSON(router *gin.Engine, method, path, body, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAuthFlow(t *testing.T) {
+	router := newAuthRouter(t)
+
+	w := doJSON(router, "POST", "/auth/register", `{"email":"alice@example.com","name":"Alice","password":"Password123"}`, "")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var tokens TokenResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &tokens); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if tokens.TokenType != "Bearer" || tokens.ExpiresIn <= 0 || tokens.User == nil {
+		t.Errorf("Unexpected token response: %s", w.Body.String())
+	}
+	if w.Header().Get("Cache-Control") != "no-store" {
+		t.Errorf("Expected token responses to be no-store, got %q", w.Header().Get("Cache-Control"))
+	}
+	if bytes.Contains(w.Body.Bytes(), []byte("password")) {
+		t.Errorf("Expected no password data in response, got %s", w.Body.String())
+	}
+
+	w = doJSON(router, "GET", "/auth/me", "", tokens.AccessToken)
+	if w.Code != http.StatusOK || !bytes.Contains(w.Body.Bytes(), []byte("alice@example.com")) {
+		t.Errorf("Expected /me to return alice, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = doJSON(router, "POST", "/auth/login", `{"email":"alice@example.com","password":"Wrong12345"}`, "")
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 for wrong password, got %d", w.Code)
+	}
+
+	w = doJSON(router, "POST", "/auth/refresh", `{"refresh_token":"`+tokens.RefreshToken+`"}`, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 from refresh, got %d: %s", w.Code, w.Body.String())
+	}
+	var refreshed TokenResponse
+	json.Unmarshal(w.Body.Bytes(), &refreshed)
+
+	w = doJSON(router, "POST", "/auth/logout", `{"refresh_token":"`+refreshed.RefreshToken+`"}`, "")
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204 from logout, got %d", w.Code)
+	}
+	w = doJSON(router, "POST", "/auth/refresh", `{"refresh_token":"`+refreshed.RefreshToken+`"}`, "")
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 after logout, got %d", w.Code)
+	}
+}
+
+// A client that still sends its expired access token must be able to refresh
+func TestRefreshWithExpiredAccessToken(t *testing.T) {
+	router := newAuthRouter(t)
+
+	w := doJSON(router, "POST", "/auth/register", `{"email":"bob@example.com","name":"Bob","password":"Password123"}`, "")
+	var tokens TokenResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &tokens); err != nil {
+		t.Fatalf("Failed to register: %d %s", w.Code, w.Body.String())
+	}
+
+	opts := jwtservice.DefaultOptions()
+	opts.AccessTokenTTL = time.Nanosecond
+	shortLived, _ := jwtservice.NewJWTService("test-secret", opts)
+	expired, _ := shortLived.GenerateToken(tokens.User.ID, "bob@example.com")
+	time.Sleep(time.Second)
+
+	w = doJSON(router, "GET", "/auth/me", "", expired)
+	var problem apierror.Problem
+	json.Unmarshal(w.Body.Bytes(), &problem)
+	if w.Code != http.StatusUnauthorized || problem.Code != "token_expired" {
+		t.Errorf("Expected 401 token_expired from /me, got %d %s", w.Code, w.Body.String())
+	}
+
+	w = doJSON(router, "POST", "/auth/refresh", `{"refresh_token":"`+tokens.RefreshToken+`"}`, expired)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected refresh to succeed despite the expired bearer token, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestAuthErrors(t *testing.T) {
+	router := newAuthRouter(t)
+	doJSON(router, "POST", "/auth/register", `{"email":"bob@example.com","name":"Bob","password":"Password123"}`, "")
+
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		wantStatus int
+		wantCode   string
+		wantFields []string
+	}{
+		{"duplicate email", "/auth/register", `{"email":"BOB@example.com","name":"Bob","password":"Password123"}`,
+			http.StatusConflict, "email_taken", []string{"email"}},
+		{"missing fields", "/auth/register", `{"email":"x@example.com"}`,
+			http.StatusBadRequest, "validation_failed", []string{"name", "password"}},
+		{"invalid fields", "/auth/register", `{"email":"nope","name":"B","password":"weak"}`,
+			http.StatusBadRequest, "validation_failed", []string{"email", "name", "password"}},
+		{"malformed json", "/auth/login", `{"email":`,
+			http.StatusBadRequest, "invalid_body", nil},
+		{"unknown email", "/auth/login", `{"email":"nobody@example.com","password":"Password123"}`,
+			http.StatusUnauthorized, "invalid_credentials", nil},
+		{"bad refresh token", "/auth/refresh", `{"refresh_token":"garbage"}`,
+			http.StatusUnauthorized, "invalid_token", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doJSON(router, "POST", tt.path, tt.body, "")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if ct := w.Header().Get("Content-Type"); ct != apierror.ContentType {
+				t.Errorf("Expected content type %q, got %q", apierror.ContentType, ct)
+			}
+			var body apierror.Problem
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Failed to decode error body: %v", err)
+			}
+			if body.Code != tt.wantCode || body.Status != tt.wantStatus {
+				t.Errorf("Expected code %q and status %d, got %q and %d", tt.wantCode, tt.wantStatus, body.Code, body.Status)
+			}
+			if len(body.Errors) != len(tt.wantFields) {
+				t.Fatalf("Expected fields %v, got %+v", tt.wantFields, body.Errors)
+			}
+			for i, field := range tt.wantFields {
+				if body.Errors[i].Field != field {
+					t.Errorf("Expected field %q at %d, got %q", field, i, body.Errors[i].Field)
+				}
+			}
+		})
+	}
+}
+
+// Rules only the domain enforces, such as a name of spaces, are localized too
+func TestRegisterLocalizesDomainErrors(t *testing.T) {
+	router := newAuthRouter(t)
+
+	req := httptest.NewRequest("POST", "/auth/register",
+		bytes.NewBufferString(`{"email":"carol@example.com","name":"   ","password":"Password123"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", "ru")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Language"); got != "ru" {
+		t.Errorf("Expected Content-Language ru, got %q", got)
+	}
+	var body apierror.Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode error body: %v", err)
+	}
+	if body.Detail != "запрос не прошёл проверку" {
+		t.Errorf("Expected a Russian detail, got %q", body.Detail)
+	}
+	if len(body.Errors) != 1 || body.Errors[0].Field != "name" || body.Errors[0].Message != "имя не может быть пустым" {
+		t.Errorf("Expected a Russian name error, got %+v", body.Errors)
+	}
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/apierror"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/userdomain"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/validation"
+)
+
+// Request types use the custom rules, so they must be registered before
+// anything is bound
+func init() {
+	if err := validation.Install(); err != nil {
+		panic(err)
+	}
+}
+
+// bindJSON decodes the body into req and answers with a problem when that fails.
+// Binding rule violations are reported per field, using the JSON names, in
+// the language the client asked for.
+func bindJSON(c *gin.Context, req any) bool {
+	err := c.ShouldBindJSON(req)
+	if err == nil {
+		return true
+	}
+
+	lang := requestLang(c)
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		var maxBytes *http.MaxBytesError
+		if errors.As(err, &maxBytes) {
+			apierror.Abort(c, err)
+		} else {
+			apierror.Abort(c, apierror.BadRequest(validation.Text(lang, validation.MsgInvalidBody)).WithCode("invalid_body").Wrap(err))
+		}
+		return false
+	}
+	apierror.Abort(c, fieldErrors(verrs, lang))
+	return false
+}
+
+// bindQuery decodes query parameters into req, reporting rule violations
+// per parameter using the form names
+func bindQuery(c *gin.Context, req any) bool {
+	err := c.ShouldBindQuery(req)
+	if err == nil {
+		return true
+	}
+
+	lang := requestLang(c)
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		apierror.Abort(c, apierror.BadRequest(validation.Text(lang, validation.MsgInvalidQuery)).WithCode("invalid_query").Wrap(err))
+		return false
+	}
+	apierror.Abort(c, fieldErrors(verrs, lang))
+	return false
+}
+
+// requestLang picks the message language from Accept-Language and tells
+// the client which one it got
+func requestLang(c *gin.Context) validation.Lang {
+	lang := validation.LanguageFrom(c.GetHeader("Accept-Language"))
+	c.Header("Content-Language", string(lang))
+	c.Writer.Header().Add("Vary", "Accept-Language")
+	return lang
+}
+
+// fieldErrors converts binding rule violations into a validation problem
+func fieldErrors(verrs validator.ValidationErrors, lang validation.Lang) *apierror.Error {
+	return apierror.Validation(validation.Text(lang, validation.MsgValidationFailed), validation.FieldErrors(verrs, lang)...)
+}
+
+// validationError converts a domain validation failure into a 400 with
+// field errors in lang
+func validationError(verr *userdomain.ValidationError, lang validation.Lang) *apierror.Error {
+	fields := make([]apierror.FieldError, len(verr.Fields))
+	for i, f := range verr.Fields {
+		fields[i] = apierror.FieldError{Field: f.Field, Message: validation.DomainMessage(lang, f.Message)}
+	}
+	return apierror.Validation(validation.Text(lang, validation.MsgValidationFailed), fields...).Wrap(verr)
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/apierror"
+)
+
+type settingsRequest struct {
+	Color    string `json:"color" binding:"required,hex_color"`
+	Timezone string `json:"timezone" binding:"required,timezone"`
+	Phone    string `json:"phone" binding:"omitempty,e164"`
+}
+
+type settingsQuery struct {
+	Limit int `form:"limit" binding:"omitempty,max=10"`
+}
+
+func TestBindLocalizesErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/settings", func(c *gin.Context) {
+		var req settingsRequest
+		if bindJSON(c, &req) {
+			c.Status(http.StatusNoContent)
+		}
+	})
+	router.GET("/settings", func(c *gin.Context) {
+		var q settingsQuery
+		if bindQuery(c, &q) {
+			c.Status(http.StatusNoContent)
+		}
+	})
+
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		body        string
+		lang        string
+		wantStatus  int
+		wantLang    string
+		wantDetail  string
+		wantMessage map[string]string
+	}{
+		{"valid", http.MethodPost, "/settings", `{"color":"#1e90ff","timezone":"Europe/Moscow","phone":"+79991234567"}`, "ru",
+			http.StatusNoContent, "", "", nil},
+		{"english by default", http.MethodPost, "/settings", `{"color":"blue","phone":"89991234567"}`, "",
+			http.StatusBadRequest, "en", "request validation failed", map[string]string{
+				"color":    "must be a hex color such as #1e90ff",
+				"timezone": "is required",
+				"phone":    "must be a phone number in E.164 format such as +79991234567",
+			}},
+		{"russian", http.MethodPost, "/settings", `{"color":"#1e90ff","timezone":"Local"}`, "ru-RU,ru;q=0.9,en;q=0.8",
+			http.StatusBadRequest, "ru", "запрос не прошёл проверку", map[string]string{
+				"timezone": "должно быть часовым поясом IANA, например Europe/Moscow",
+			}},
+		{"russian malformed body", http.MethodPost, "/settings", `{"color":`, "ru",
+			http.StatusBadRequest, "ru", "тело запроса не является корректным JSON", nil},
+		{"russian query", http.MethodGet, "/settings?limit=11", "", "ru",
+			http.StatusBadRequest, "ru", "запрос не прошёл проверку", map[string]string{
+				"limit": "должно быть не больше 10",
+			}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.lang != "" {
+				req.Header.Set("Accept-Language", tt.lang)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if w.Code == http.StatusNoContent {
+				return
+			}
+			if got := w.Header().Get("Content-Language"); got != tt.wantLang {
+				t.Errorf("Expected Content-Language %q, got %q", tt.wantLang, got)
+			}
+
+			var p apierror.Problem
+			if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+				t.Fatalf("Failed to decode problem: %v", err)
+			}
+			if p.Detail != tt.wantDetail {
+				t.Errorf("Expected detail %q, got %q", tt.wantDetail, p.Detail)
+			}
+			if len(p.Errors) != len(tt.wantMessage) {
+				t.Fatalf("Expected %d field errors, got %+v", len(tt.wantMessage), p.Errors)
+			}
+			for _, fe := range p.Errors {
+				if want := tt.wantMessage[fe.Field]; fe.Message != want {
+					t.Errorf("%s: expected %q, got %q", fe.Field, want, fe.Message)
+				}
+				if strings.Contains(fe.Message, "Key: ") {
+					t.Errorf("Expected no raw validator text, got %q", fe.Message)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/apierror"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/featureflags"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/middleware"
+)
+
+// FlagManager is what the flag endpoints need; *featureflags.Set implements it
+type FlagManager interface {
+	List() []featureflags.State
+	Get(name string) (featureflags.State, bool)
+	Evaluate(subj featureflags.Subject) map[string]bool
+	Override(ctx context.Context, f featureflags.Flag, actor *int) (featureflags.State, error)
+	Reset(ctx context.Context, name string) (bool, error)
+}
+
+// FlagRequest is the body of PUT /admin/flags/:name
+type FlagRequest struct {
+	Description string   `json:"description" binding:"max=500"`
+	Enabled     bool     `json:"enabled"`
+	Percentage  *int     `json:"percentage" binding:"omitempty,min=0,max=100" description:"Share of signed-in users who get the flag, default 100"`
+	Users       []int    `json:"users" description:"User IDs that always get the flag while it is enabled"`
+	Roles       []string `json:"roles" description:"Roles that always get the flag while it is enabled"`
+}
+
+// FlagsResponse lists every flag definition
+type FlagsResponse struct {
+	Flags []featureflags.State `json:"flags"`
+}
+
+// FlagValuesResponse holds the flags as evaluated for the caller
+type FlagValuesResponse struct {
+	Flags map[string]bool `json:"flags"`
+}
+
+// FlagsHandler serves feature flag evaluation and the flag admin endpoints
+type FlagsHandler struct {
+	flags FlagManager
+}
+
+// NewFlagsHandler creates a FlagsHandler on flags
+func NewFlagsHandler(flags FlagManager) *FlagsHandler {
+	return &FlagsHandler{flags: flags}
+}
+
+// Values returns every flag evaluated for the caller, anonymous or signed in
+func (h *FlagsHandler) Values(c *gin.Context) {
+	c.JSON(http.StatusOK, FlagValuesResponse{Flags: h.flags.Evaluate(middleware.FlagSubject(c))})
+}
+
+// List returns every flag with its source: the config file or an override
+func (h *FlagsHandler) List(c *gin.Context) {
+	c.JSON(http.StatusOK, FlagsResponse{Flags: h.flags.List()})
+}
+
+// Put overrides a flag; the change applies on every replica without a restart
+func (h *FlagsHandler) Put(c *gin.Context) {
+	name := c.Param("name")
+	if !featureflags.ValidName(name) {
+		apierror.Abort(c, apierror.Validation("request validation failed", apierror.FieldError{
+			Field:   "name",
+			Message: "must be 1-100 lowercase letters, digits, '.', '_' or '-'",
+		}))
+		return
+	}
+	var req FlagRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	flag := featureflags.Flag{
+		Name:        name,
+		Description: req.Description,
+		Enabled:     req.Enabled,
+		Percentage:  100,
+		Users:       req.Users,
+		Roles:       req.Roles,
+	}
+	if req.Percentage != nil {
+		flag.Percentage = *req.Percentage
+	}
+	var actor *int
+	if id, ok := middleware.GetUserID(c); ok {
+		actor = &id
+	}
+
+	before, existed := h.flags.Get(name)
+	state, err := h.flags.Override(c.Request.Context(), flag, actor)
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+	middleware.AuditResource(c, name)
+	if existed {
+		middleware.AuditChange(c, before.Flag, state.Flag)
+	} else {
+		middleware.AuditChange(c, nil, state.Flag)
+	}
+	c.JSON(http.StatusOK, state)
+}
+
+// Delete removes a flag's override, going back to the configured definition
+func (h *FlagsHandler) Delete(c *gin.Context) {
+	name := c.Param("name")
+	before, _ := h.flags.Get(name)
+	existed, err := h.flags.Reset(c.Request.Context(), name)
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+	if !existed {
+		apierror.Abort(c, apierror.NotFound("flag "+name+" has no override").WithCode("flag_not_overridden"))
+		return
+	}
+
+	middleware.AuditResource(c, name)
+	if after, ok := h.flags.Get(name); ok {
+		middleware.AuditChange(c, before.Flag, after.Flag)
+	} else {
+		middleware.AuditChange(c, before.Flag, nil)
+	}
+	c.Status(http.StatusNoContent)
+}
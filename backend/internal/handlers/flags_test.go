@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/apierror"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/featureflags"
+)
+
+func TestFlagsHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	flags, err := featureflags.New([]featureflags.Flag{
+		{Name: "search", Enabled: true, Percentage: 100},
+		{Name: "dashboard", Enabled: false, Percentage: 100},
+	}, nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	h := NewFlagsHandler(flags)
+	router := gin.New()
+	router.Use(apierror.Middleware())
+	router.GET("/flags", h.Values)
+	router.GET("/admin/flags", h.List)
+	router.PUT("/admin/flags/:name", h.Put)
+	router.DELETE("/admin/flags/:name", h.Delete)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path, body string
+		wantStatus int
+		wantField  string
+	}{
+		{"override", http.MethodPut, "/admin/flags/dashboard", `{"enabled":true,"roles":["admin"]}`, http.StatusOK, ""},
+		{"create", http.MethodPut, "/admin/flags/experiment", `{"enabled":true,"percentage":0}`, http.StatusOK, ""},
+		{"bad name", http.MethodPut, "/admin/flags/Bad%20Name", `{"enabled":true}`, http.StatusBadRequest, "name"},
+		{"bad percentage", http.MethodPut, "/admin/flags/dashboard", `{"enabled":true,"percentage":120}`, http.StatusBadRequest, "percentage"},
+		{"reset", http.MethodDelete, "/admin/flags/experiment", "", http.StatusNoContent, ""},
+		{"reset without override", http.MethodDelete, "/admin/flags/search", "", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(tt.method, tt.path, tt.body)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantField != "" {
+				var p apierror.Problem
+				json.Unmarshal(w.Body.Bytes(), &p)
+				if len(p.Errors) != 1 || p.Errors[0].Field != tt.wantField {
+					t.Errorf("Expected an error for %s, got %+v", tt.wantField, p.Errors)
+				}
+			}
+		})
+	}
+
+	var list FlagsResponse
+	json.Unmarshal(do(http.MethodGet, "/admin/flags", "").Body.Bytes(), &list)
+	if len(list.Flags) != 2 || list.Flags[0].Name != "dashboard" || list.Flags[0].Source != featureflags.SourceOverride {
+		t.Fatalf("Expected the dashboard override and no experiment, got %+v", list.Flags)
+	}
+	if list.Flags[0].Percentage != 100 || list.Flags[0].Roles[0] != "admin" {
+		t.Errorf("Expected percentage to default to 100, got %+v", list.Flags[0])
+	}
+
+	var values FlagValuesResponse
+	json.Unmarshal(do(http.MethodGet, "/flags", "").Body.Bytes(), &values)
+	if !values.Flags["search"] || !values.Flags["dashboard"] {
+		t.Errorf("Expected both flags on for an anonymous caller at 100%%, got %v", values.Flags)
+	}
+}
@@ -4,20 +4,47 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/health"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/version"
 )
 
-// HealthCheck returns server health status
-func HealthCheck(c *gin.Context) {
+// HealthHandler serves the liveness and readiness probes
+type HealthHandler struct {
+	registry *health.Registry
+}
+
+// NewHealthHandler creates a HealthHandler backed by registry
+func NewHealthHandler(registry *health.Registry) *HealthHandler {
+	return &HealthHandler{registry: registry}
+}
+
+// Live reports that the process is up; it never runs checks
+func (h *HealthHandler) Live(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"status":  "healthy",
-		"service": "sum25-go-flutter-course-backend",
-		"version": "1.0.0",
+		"service": version.Service,
+		"version": version.Version,
+		"commit":  version.Commit,
 	})
 }
 
+// Ready runs every registered check and answers 503 if any fails or shutdown has begun
+func (h *HealthHandler) Ready(c *gin.Context) {
+	report := h.registry.Check(c.Request.Context())
+
+	status := http.StatusOK
+	if report.Status != health.StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
+
+// PingResponse is the body returned by Ping
+type PingResponse struct {
+	Message string `json:"message" example:"pong"`
+}
+
 // Ping returns a simple pong response
 func Ping(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"message": "pong",
-	})
+	c.JSON(http.StatusOK, PingResponse{Message: "pong"})
 }
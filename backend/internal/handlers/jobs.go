@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/apierror"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/jobs"
+)
+
+// JobLister is the part of the job store the admin endpoints read
+type JobLister interface {
+	Counts(ctx context.Context) (map[jobs.Status]int, error)
+	List(ctx context.Context, f jobs.Filter) ([]*jobs.Job, error)
+}
+
+// JobsQuery holds the filters of GET /admin/jobs
+type JobsQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending running done dead" description:"Only jobs in this state; dead is the dead-letter list"`
+	Type   string `form:"type" description:"Only jobs of this type"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=500" description:"Maximum number of jobs, default 50"`
+}
+
+// JobsResponse is the queue overview returned by GET /admin/jobs
+type JobsResponse struct {
+	Counts map[jobs.Status]int `json:"counts"`
+	Jobs   []*jobs.Job         `json:"jobs"`
+}
+
+// JobsHandler serves the background job admin endpoints
+type JobsHandler struct {
+	jobs JobLister
+}
+
+// NewJobsHandler creates a JobsHandler reading from store
+func NewJobsHandler(store JobLister) *JobsHandler {
+	return &JobsHandler{jobs: store}
+}
+
+// List returns the number of jobs per state and the most recently updated jobs
+func (h *JobsHandler) List(c *gin.Context) {
+	var q JobsQuery
+	if !bindQuery(c, &q) {
+		return
+	}
+	if q.Limit == 0 {
+		q.Limit = 50
+	}
+
+	ctx := c.Request.Context()
+	counts, err := h.jobs.Counts(ctx)
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+	list, err := h.jobs.List(ctx, jobs.Filter{Status: jobs.Status(q.Status), Type: q.Type, Limit: q.Limit})
+	if err != nil {
+		apierror.Abort(c, err)
+		return
+	}
+	if list == nil {
+		list = []*jobs.Job{}
+	}
+	c.JSON(http.StatusOK, JobsResponse{Counts: counts, Jobs: list})
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/apierror"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/jobs"
+)
+
+type fakeJobLister struct {
+	filter jobs.Filter
+}
+
+func (f *fakeJobLister) Counts(context.Context) (map[jobs.Status]int, error) {
+	return map[jobs.Status]int{jobs.StatusPending: 2, jobs.StatusDead: 1}, nil
+}
+
+func (f *fakeJobLister) List(_ context.Context, filter jobs.Filter) ([]*jobs.Job, error) {
+	f.filter = filter
+	if filter.Status == jobs.StatusDead {
+		return []*jobs.Job{{ID: 7, Type: "email", Status: jobs.StatusDead, LastError: "smtp down"}}, nil
+	}
+	return nil, nil
+}
+
+func TestJobsHandlerList(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	lister := &fakeJobLister{}
+	router := gin.New()
+	router.GET("/admin/jobs", NewJobsHandler(lister).List)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantFilter jobs.Filter
+		wantJobs   int
+		wantField  string
+	}{
+		{"defaults", "", http.StatusOK, jobs.Filter{Limit: 50}, 0, ""},
+		{"dead letters", "?status=dead&type=email&limit=10", http.StatusOK,
+			jobs.Filter{Status: jobs.StatusDead, Type: "email", Limit: 10}, 1, ""},
+		{"unknown status", "?status=failed", http.StatusBadRequest, jobs.Filter{}, 0, "status"},
+		{"limit too large", "?limit=1000", http.StatusBadRequest, jobs.Filter{}, 0, "limit"},
+		{"limit not a number", "?limit=ten", http.StatusBadRequest, jobs.Filter{}, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lister.filter = jobs.Filter{}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/jobs"+tt.query, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				var p apierror.Problem
+				json.Unmarshal(w.Body.Bytes(), &p)
+				if tt.wantField != "" && (len(p.Errors) != 1 || p.Errors[0].Field != tt.wantField) {
+					t.Errorf("Expected an error for %s, got %+v", tt.wantField, p.Errors)
+				}
+				return
+			}
+
+			if lister.filter != tt.wantFilter {
+				t.Errorf("Expected filter %+v, got %+v", tt.wantFilter, lister.filter)
+			}
+			var resp JobsResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if len(resp.Jobs) != tt.wantJobs || resp.Jobs == nil {
+				t.Errorf("Expected %d jobs, got %v", tt.wantJobs, resp.Jobs)
+			}
+			if resp.Counts[jobs.StatusPending] != 2 || resp.Counts[jobs.StatusDead] != 1 {
+				t.Errorf("Unexpected counts %v", resp.Counts)
+			}
+		})
+	}
+}
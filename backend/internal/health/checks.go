@@ -0,0 +1,67 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Pinger is satisfied by *sql.DB
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// DatabasePing fails when the database does not answer a ping
+func DatabasePing(db Pinger) CheckFunc {
+	return func(ctx context.Context) error {
+		return db.PingContext(ctx)
+	}
+}
+
+// VersionSource is satisfied by *migrate.Migrator
+type VersionSource interface {
+	Version(ctx context.Context) (int64, error)
+	Latest() int64
+}
+
+// MigrationVersion fails until the schema reaches the latest embedded
+// migration. A newer schema passes: during a rolling deploy the new pods
+// migrate first and the old ones must stay ready until they are replaced.
+func MigrationVersion(m VersionSource) CheckFunc {
+	return func(ctx context.Context) error {
+		current, err := m.Version(ctx)
+		if err != nil {
+			return err
+		}
+		if latest := m.Latest(); current < latest {
+			return fmt.Errorf("schema at version %d, expected at least %d", current, latest)
+		}
+		return nil
+	}
+}
+
+// Running fails when isRunning reports false, e.g. for a background broker or worker pool
+func Running(isRunning func() bool) CheckFunc {
+	return func(ctx context.Context) error {
+		if !isRunning() {
+			return fmt.Errorf("not running")
+		}
+		return nil
+	}
+}
+
+// DiskSpace fails when the filesystem holding path has less than minFree bytes available
+func DiskSpace(path string, minFree uint64) CheckFunc {
+	return func(ctx context.Context) error {
+		free, err := freeBytes(path)
+		if err != nil {
+			return err
+		}
+		if free < minFree {
+			return fmt.Errorf("%d bytes free on %s, need %d", free, path, minFree)
+		}
+		return nil
+	}
+}
+
+var _ Pinger = (*sql.DB)(nil)
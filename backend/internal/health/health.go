@@ -0,0 +1,191 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/version"
+)
+
+// Status values used in reports
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// DefaultTimeout bounds checks registered without a timeout
+const DefaultTimeout = 2 * time.Second
+
+// ErrShuttingDown is reported while the process drains before exit
+var ErrShuttingDown = errors.New("shutting down")
+
+// CheckFunc reports a subsystem problem by returning an error
+type CheckFunc func(ctx context.Context) error
+
+// CheckResult is the outcome of one check
+type CheckResult struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report aggregates every check for the readiness endpoint
+type Report struct {
+	Status  string        `json:"status"`
+	Service string        `json:"service"`
+	Version string        `json:"version"`
+	Commit  string        `json:"commit"`
+	Checks  []CheckResult `json:"checks"`
+	// Info holds diagnostic sections that never fail the report
+	Info map[string]any `json:"info,omitempty"`
+}
+
+// InfoFunc returns a diagnostic section for the report, e.g. feature flag state
+type InfoFunc func() any
+
+type check struct {
+	name    string
+	timeout time.Duration
+	fn      CheckFunc
+}
+
+// Registry holds named readiness checks. It starts ready; call
+// SetReady(false) when shutdown begins so load balancers stop routing.
+type Registry struct {
+	mu     sync.RWMutex
+	checks []check
+	info   map[string]InfoFunc
+	ready  atomic.Bool
+}
+
+// NewRegistry creates an empty registry that reports ready
+func NewRegistry() *Registry {
+	r := &Registry{}
+	r.ready.Store(true)
+	return r
+}
+
+// Register adds a named check; a later registration with the same name replaces it
+func (r *Registry) Register(name string, timeout time.Duration, fn CheckFunc) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.checks {
+		if r.checks[i].name == name {
+			r.checks[i] = check{name: name, timeout: timeout, fn: fn}
+			return
+		}
+	}
+	r.checks = append(r.checks, check{name: name, timeout: timeout, fn: fn})
+}
+
+// RegisterInfo adds a named diagnostic section to every report; a later
+// registration with the same name replaces it
+func (r *Registry) RegisterInfo(name string, fn InfoFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.info == nil {
+		r.info = map[string]InfoFunc{}
+	}
+	r.info[name] = fn
+}
+
+// SetReady toggles readiness independently of the checks
+func (r *Registry) SetReady(ready bool) {
+	r.ready.Store(ready)
+}
+
+// Ready reports whether the process accepts traffic
+func (r *Registry) Ready() bool {
+	return r.ready.Load()
+}
+
+// Check runs every registered check concurrently, each under its own timeout
+func (r *Registry) Check(ctx context.Context) Report {
+	r.mu.RLock()
+	checks := append([]check(nil), r.checks...)
+	var info map[string]any
+	if len(r.info) > 0 {
+		info = make(map[string]any, len(r.info))
+		for name, fn := range r.info {
+			info[name] = fn()
+		}
+	}
+	r.mu.RUnlock()
+
+	report := Report{
+		Status:  StatusOK,
+		Service: version.Service,
+		Version: version.Version,
+		Commit:  version.Commit,
+		Checks:  make([]CheckResult, len(checks)),
+		Info:    info,
+	}
+
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c check) {
+			defer wg.Done()
+			report.Checks[i] = run(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	for _, res := range report.Checks {
+		if res.Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+
+	if !r.Ready() {
+		report.Status = StatusFail
+		report.Checks = append(report.Checks, CheckResult{
+			Name: "lifecycle", Status: StatusFail, Error: ErrShuttingDown.Error(),
+		})
+	}
+	return report
+}
+
+func run(ctx context.Context, c check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	errc := make(chan error, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				errc <- fmt.Errorf("check panicked: %v", p)
+			}
+		}()
+		errc <- c.fn(ctx)
+	}()
+
+	// A check that ignores ctx must not hold up the whole report
+	var err error
+	select {
+	case err = <-errc:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out after %s", c.timeout)
+	}
+
+	res := CheckResult{
+		Name:      c.name,
+		Status:    StatusOK,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		res.Status = StatusFail
+		res.Error = err.Error()
+	}
+	return res
+}
@@ -0,0 +1,121 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRegistryAggregatesChecks(t *testing.T) {
+	r := NewRegistry()
+	r.Register("ok", time.Second, func(ctx context.Context) error { return nil })
+
+	report := r.Check(context.Background())
+	if report.Status != StatusOK {
+		t.Fatalf("Expected ok report, got %+v", report)
+	}
+
+	r.Register("broken", time.Second, func(ctx context.Context) error { return errors.New("boom") })
+	report = r.Check(context.Background())
+	if report.Status != StatusFail {
+		t.Errorf("Expected fail report, got %s", report.Status)
+	}
+	if len(report.Checks) != 2 || report.Checks[1].Error != "boom" {
+		t.Errorf("Expected broken check with error, got %+v", report.Checks)
+	}
+}
+
+func TestRegistryReplacesByName(t *testing.T) {
+	r := NewRegistry()
+	r.Register("db", 0, func(ctx context.Context) error { return errors.New("down") })
+	r.Register("db", 0, func(ctx context.Context) error { return nil })
+
+	report := r.Check(context.Background())
+	if len(report.Checks) != 1 || report.Status != StatusOK {
+		t.Errorf("Expected single passing check, got %+v", report)
+	}
+}
+
+func TestRegistryInfo(t *testing.T) {
+	r := NewRegistry()
+	if report := r.Check(context.Background()); report.Info != nil {
+		t.Errorf("Expected no info section, got %v", report.Info)
+	}
+
+	calls := 0
+	r.RegisterInfo("flags", func() any { calls++; return map[string]bool{"search": true} })
+	report := r.Check(context.Background())
+	flags, ok := report.Info["flags"].(map[string]bool)
+	if !ok || !flags["search"] {
+		t.Errorf("Expected flags info, got %v", report.Info)
+	}
+	if report.Status != StatusOK || calls != 1 {
+		t.Errorf("Expected info to be gathered once without affecting status, got %s after %d calls", report.Status, calls)
+	}
+}
+
+func TestCheckTimeout(t *testing.T) {
+	r := NewRegistry()
+	r.Register("slow", 20*time.Millisecond, func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	start := time.Now()
+	report := r.Check(context.Background())
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected check to be cut off by its timeout, took %v", elapsed)
+	}
+	if report.Status != StatusFail {
+		t.Errorf("Expected timed out check to fail, got %+v", report.Checks)
+	}
+}
+
+func TestCheckPanicIsReported(t *testing.T) {
+	r := NewRegistry()
+	r.Register("panics", time.Second, func(ctx context.Context) error { panic("oops") })
+
+	report := r.Check(context.Background())
+	if report.Status != StatusFail || report.Checks[0].Error == "" {
+		t.Errorf("Expected panic to be reported as failure, got %+v", report.Checks)
+	}
+}
+
+func TestNotReadyDuringShutdown(t *testing.T) {
+	r := NewRegistry()
+	r.SetReady(false)
+
+	report := r.Check(context.Background())
+	if report.Status != StatusFail {
+		t.Errorf("Expected not-ready registry to fail, got %s", report.Status)
+	}
+}
+
+type fakeVersions struct{ current, latest int64 }
+
+func (f fakeVersions) Version(ctx context.Context) (int64, error) { return f.current, nil }
+func (f fakeVersions) Latest() int64                              { return f.latest }
+
+func TestBuiltinChecks(t *testing.T) {
+	ctx := context.Background()
+
+	if err := MigrationVersion(fakeVersions{3, 3})(ctx); err != nil {
+		t.Errorf("Expected migrations at latest to pass, got %v", err)
+	}
+	if err := MigrationVersion(fakeVersions{2, 3})(ctx); err == nil {
+		t.Error("Expected pending migrations to fail")
+	}
+	if err := MigrationVersion(fakeVersions{4, 3})(ctx); err != nil {
+		t.Errorf("Expected a schema migrated by a newer release to pass, got %v", err)
+	}
+	if err := Running(func() bool { return false })(ctx); err == nil {
+		t.Error("Expected stopped component to fail")
+	}
+	if err := DiskSpace(t.TempDir(), 1)(ctx); err != nil {
+		t.Errorf("Expected at least one free byte, got %v", err)
+	}
+	if err := DiskSpace(t.TempDir(), ^uint64(0))(ctx); err == nil {
+		t.Error("Expected impossible free space requirement to fail")
+	}
+}
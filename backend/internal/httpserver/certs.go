@@ -0,0 +1,88 @@
+package httpserver
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// CertReloader serves a certificate pair from disk and reloads it when the
+// files change. A failed reload keeps the previous certificate in use.
+type CertReloader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// NewCertReloader loads the pair once and fails if it is unusable
+func NewCertReloader(certFile, keyFile string) (*CertReloader, error) {
+	r := &CertReloader{certFile: certFile, keyFile: keyFile}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate implements tls.Config.GetCertificate
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// Reload re-reads the files and reports whether the certificate changed.
+// Comparing contents rather than modification times copes with the
+// symlink swaps used by Kubernetes secrets and certbot.
+func (r *CertReloader) Reload() (bool, error) {
+	certPEM, err := os.ReadFile(r.certFile)
+	if err != nil {
+		return false, fmt.Errorf("httpserver: read certificate: %w", err)
+	}
+	keyPEM, err := os.ReadFile(r.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("httpserver: read key: %w", err)
+	}
+
+	r.mu.RLock()
+	unchanged := bytes.Equal(certPEM, r.certPEM) && bytes.Equal(keyPEM, r.keyPEM)
+	r.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return false, fmt.Errorf("httpserver: load key pair: %w", err)
+	}
+
+	r.mu.Lock()
+	r.cert, r.certPEM, r.keyPEM = &cert, certPEM, keyPEM
+	r.mu.Unlock()
+	return true, nil
+}
+
+// Watch calls Reload every interval until ctx is done. onReload, if not nil,
+// is told about every change and every failure.
+func (r *CertReloader) Watch(ctx context.Context, interval time.Duration, onReload func(changed bool, err error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changed, err := r.Reload()
+			if onReload != nil && (changed || err != nil) {
+				onReload(changed, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Package httpserver builds the *http.Server instances for cmd/server from
+// the server config: timeouts, header limits, TLS with hot-reloaded
+// certificates, HTTP/2 and h2c, plus the HTTP to HTTPS redirect listener.
+package httpserver
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/config"
+)
+
+// New returns a server for handler listening on addr. With TLS enabled the
+// returned CertReloader must be watched so renewed certificates are picked up;
+// it is nil otherwise.
+func New(addr string, cfg config.ServerConfig, handler http.Handler) (*http.Server, *CertReloader, error) {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+		Protocols:         new(http.Protocols),
+	}
+	srv.Protocols.SetHTTP1(true)
+
+	if !cfg.TLS.Enabled() {
+		if cfg.H2C {
+			srv.Protocols.SetUnencryptedHTTP2(true)
+		}
+		return srv, nil, nil
+	}
+
+	reloader, err := NewCertReloader(cfg.TLS.CertFile, cfg.TLS.KeyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	srv.Protocols.SetHTTP2(true)
+	srv.TLSConfig = &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}
+	return srv, reloader, nil
+}
+
+// Serve serves srv on ln, over TLS when srv.TLSConfig is set
+func Serve(srv *http.Server, ln net.Listener) error {
+	if srv.TLSConfig != nil {
+		return srv.ServeTLS(ln, "", "")
+	}
+	return srv.Serve(ln)
+}
+
+// NewRedirect returns a plain HTTP server on addr that redirects every
+// request to the same URL on https, at httpsPort unless that is 443
+func NewRedirect(addr, httpsPort string) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           RedirectHandler(httpsPort),
+		ReadHeaderTimeout: 5 * time.Second,
+		IdleTimeout:       30 * time.Second,
+	}
+}
+
+// RedirectHandler answers every request with 308 to its https equivalent
+func RedirectHandler(httpsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if httpsPort != "" && httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		} else if strings.Contains(host, ":") {
+			host = "[" + host + "]" // IPv6 literal
+		}
+
+		target := "https://" + host + r.URL.RequestURI()
+		// 308 keeps the method and body, unlike 301
+		http.Redirect(w, r, target, http.StatusPermanentRedirect)
+	})
+}
@@ -0,0 +1,233 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/config"
+)
+
+// writeSelfSigned writes a self-signed localhost certificate with the given
+// serial number to dir and returns the file paths and the parsed certificate
+func writeSelfSigned(t *testing.T, dir string, serial int64) (string, string, *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate() error = %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey() error = %v", err)
+	}
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile, cert
+}
+
+// start serves srv on a random local port and returns its address
+func start(t *testing.T, srv *http.Server) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	go Serve(srv, ln)
+	t.Cleanup(func() { srv.Close() })
+	return ln.Addr().String()
+}
+
+var protoHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte(r.Proto))
+})
+
+func TestNewAppliesTimeouts(t *testing.T) {
+	cfg := config.Default().Server
+	srv, reloader, err := New(":0", cfg, protoHandler)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if reloader != nil || srv.TLSConfig != nil {
+		t.Error("Expected plain HTTP without TLS config")
+	}
+	if srv.ReadHeaderTimeout != cfg.ReadHeaderTimeout || srv.WriteTimeout != cfg.WriteTimeout ||
+		srv.IdleTimeout != cfg.IdleTimeout || srv.MaxHeaderBytes != cfg.MaxHeaderBytes {
+		t.Errorf("Expected server settings from config, got %+v", srv)
+	}
+}
+
+func TestTLSServesHTTP2AndReloadsCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, first := writeSelfSigned(t, dir, 1)
+
+	cfg := config.Default().Server
+	cfg.TLS.CertFile, cfg.TLS.KeyFile = certFile, keyFile
+	srv, reloader, err := New("", cfg, protoHandler)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	addr := start(t, srv)
+
+	get := func(roots *x509.CertPool) (*http.Response, error) {
+		client := &http.Client{Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{RootCAs: roots},
+			ForceAttemptHTTP2: true,
+		}}
+		return client.Get("https://" + addr + "/")
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(first)
+	resp, err := get(roots)
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.ProtoMajor != 2 {
+		t.Errorf("Expected HTTP/2 over TLS, got %s", resp.Proto)
+	}
+
+	// Replace the files; the old root must stop working after a reload
+	_, _, second := writeSelfSigned(t, dir, 2)
+	changed, err := reloader.Reload()
+	if err != nil || !changed {
+		t.Fatalf("Expected Reload to pick up the new pair, got %v, %v", changed, err)
+	}
+	if _, err := get(roots); err == nil {
+		t.Error("Expected the old certificate to be gone after reload")
+	}
+	roots.AddCert(second)
+	resp, err = get(roots)
+	if err != nil {
+		t.Fatalf("GET with new root error = %v", err)
+	}
+	resp.Body.Close()
+	if got := resp.TLS.PeerCertificates[0].SerialNumber.Int64(); got != 2 {
+		t.Errorf("Expected certificate serial 2, got %d", got)
+	}
+
+	// A broken file keeps the current certificate
+	os.WriteFile(certFile, []byte("garbage"), 0o600)
+	if _, err := reloader.Reload(); err == nil {
+		t.Error("Expected Reload to fail on a broken certificate")
+	}
+	if cert, _ := reloader.GetCertificate(nil); cert == nil {
+		t.Error("Expected previous certificate to stay in use")
+	}
+}
+
+func TestWatchReportsChanges(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, _ := writeSelfSigned(t, dir, 1)
+	reloader, err := NewCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("NewCertReloader() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan bool, 1)
+	go reloader.Watch(ctx, 10*time.Millisecond, func(changed bool, err error) {
+		if changed {
+			select {
+			case changes <- true:
+			default:
+			}
+		}
+	})
+
+	writeSelfSigned(t, dir, 3)
+	select {
+	case <-changes:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected Watch to report the new certificate")
+	}
+}
+
+func TestH2C(t *testing.T) {
+	cfg := config.Default().Server
+	cfg.H2C = true
+	srv, _, err := New("", cfg, protoHandler)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	addr := start(t, srv)
+
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Transport: &http.Transport{Protocols: protocols}}
+	resp, err := client.Get("http://" + addr + "/")
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.ProtoMajor != 2 {
+		t.Errorf("Expected HTTP/2 over cleartext, got %s", resp.Proto)
+	}
+}
+
+func TestRedirectHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		httpsPort string
+		host      string
+		want      string
+	}{
+		{"default port", "443", "example.com:8080", "https://example.com/path?q=1"},
+		{"custom port", "8443", "example.com", "https://example.com:8443/path?q=1"},
+		{"ipv6", "8443", "[::1]:80", "https://[::1]:8443/path?q=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/path?q=1", nil)
+			req.Host = tt.host
+			w := httptest.NewRecorder()
+			RedirectHandler(tt.httpsPort).ServeHTTP(w, req)
+
+			if w.Code != http.StatusPermanentRedirect {
+				t.Errorf("Expected status 308, got %d", w.Code)
+			}
+			if got := w.Header().Get("Location"); got != tt.want {
+				t.Errorf("Expected Location %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
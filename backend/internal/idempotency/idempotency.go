@@ -0,0 +1,40 @@
+// Package idempotency stores the first response to a request carrying an
+// Idempotency-Key so that retries can be answered with the same response
+// instead of repeating the side effect. The HTTP side lives in
+// middleware.Idempotency; this package only holds the stores.
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Record is what a store keeps per key
+type Record struct {
+	Key         string
+	Fingerprint string
+	// Completed is false while the first request is still being handled
+	Completed bool
+	Response  Response
+	ExpiresAt time.Time
+}
+
+// Response is a captured HTTP response
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Store persists records. Implementations must make Begin atomic: of
+// several concurrent calls for a new key exactly one may return started.
+type Store interface {
+	// Begin reserves key for a request with fingerprint until lockUntil.
+	// When a live record already exists it is returned with started false.
+	Begin(ctx context.Context, key, fingerprint string, lockUntil, now time.Time) (existing *Record, started bool, err error)
+	// Complete stores the response for a reserved key, kept until expiresAt
+	Complete(ctx context.Context, key string, resp Response, expiresAt time.Time) error
+	// Release drops a reservation so the request may be retried, e.g. after a server error
+	Release(ctx context.Context, key string) error
+}
@@ -0,0 +1,110 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/database/dbtest"
+)
+
+func newSQLStore(t *testing.T) *SQLStore {
+	t.Helper()
+	return NewSQLStore(dbtest.Open(t))
+}
+
+func stores(t *testing.T) map[string]Store {
+	mem := NewMemoryStore(0)
+	t.Cleanup(mem.Close)
+	return map[string]Store{"memory": mem, "sql": newSQLStore(t)}
+}
+
+func TestStoreLifecycle(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			if _, started, err := store.Begin(ctx, "k1", "fp", now.Add(time.Minute), now); err != nil || !started {
+				t.Fatalf("Expected first Begin to start, got %v, %v", started, err)
+			}
+
+			rec, started, err := store.Begin(ctx, "k1", "fp", now.Add(time.Minute), now)
+			if err != nil || started || rec.Completed {
+				t.Fatalf("Expected an in-flight record, got %+v, %v, %v", rec, started, err)
+			}
+
+			resp := Response{Status: 201, Header: http.Header{"Location": {"/items/1"}}, Body: []byte(`{"id":1}`)}
+			if err := store.Complete(ctx, "k1", resp, now.Add(time.Hour)); err != nil {
+				t.Fatalf("Complete() error = %v", err)
+			}
+
+			rec, started, err = store.Begin(ctx, "k1", "other", now.Add(time.Minute), now)
+			if err != nil || started || !rec.Completed {
+				t.Fatalf("Expected a completed record, got %+v, %v, %v", rec, started, err)
+			}
+			if rec.Fingerprint != "fp" || rec.Response.Status != 201 ||
+				string(rec.Response.Body) != `{"id":1}` || rec.Response.Header.Get("Location") != "/items/1" {
+				t.Errorf("Unexpected stored record %+v", rec)
+			}
+
+			// After expiry the key can be used again
+			if _, started, _ := store.Begin(ctx, "k1", "fp2", now.Add(3*time.Hour), now.Add(2*time.Hour)); !started {
+				t.Error("Expected expired key to be reusable")
+			}
+
+			if err := store.Release(ctx, "k1"); err != nil {
+				t.Fatalf("Release() error = %v", err)
+			}
+			if _, started, _ := store.Begin(ctx, "k1", "fp", now.Add(time.Minute), now); !started {
+				t.Error("Expected released key to be reusable")
+			}
+		})
+	}
+}
+
+func TestStoreBeginIsExclusive(t *testing.T) {
+	now := time.Now()
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			var (
+				wg      sync.WaitGroup
+				mu      sync.Mutex
+				winners int
+			)
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, started, err := store.Begin(context.Background(), "race", "fp", now.Add(time.Minute), now)
+					if err != nil {
+						t.Errorf("Begin() error = %v", err)
+					}
+					if started {
+						mu.Lock()
+						winners++
+						mu.Unlock()
+					}
+				}()
+			}
+			wg.Wait()
+			if winners != 1 {
+				t.Errorf("Expected exactly one winner, got %d", winners)
+			}
+		})
+	}
+}
+
+func TestSQLStoreDeleteExpired(t *testing.T) {
+	ctx := context.Background()
+	store := newSQLStore(t)
+	now := time.Now()
+	store.Begin(ctx, "old", "fp", now.Add(-time.Minute), now.Add(-time.Hour))
+	store.Begin(ctx, "new", "fp", now.Add(time.Minute), now)
+
+	if n, err := store.DeleteExpired(ctx, now); err != nil || n != 1 {
+		t.Errorf("Expected 1 expired record removed, got %d (err %v)", n, err)
+	}
+}
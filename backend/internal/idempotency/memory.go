@@ -0,0 +1,100 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps records in process memory. It suits a single instance;
+// use SQLStore when several replicas serve the same clients.
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]*Record
+	done    chan struct{}
+	once    sync.Once
+}
+
+// NewMemoryStore creates a store that drops expired records every
+// cleanupInterval; zero disables the background sweep
+func NewMemoryStore(cleanupInterval time.Duration) *MemoryStore {
+	s := &MemoryStore{
+		records: make(map[string]*Record),
+		done:    make(chan struct{}),
+	}
+	if cleanupInterval > 0 {
+		go s.sweepLoop(cleanupInterval)
+	}
+	return s
+}
+
+// Begin reserves key unless a live record exists
+func (s *MemoryStore) Begin(ctx context.Context, key, fingerprint string, lockUntil, now time.Time) (*Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rec, ok := s.records[key]; ok && now.Before(rec.ExpiresAt) {
+		copied := *rec
+		return &copied, false, nil
+	}
+	s.records[key] = &Record{Key: key, Fingerprint: fingerprint, ExpiresAt: lockUntil}
+	return nil, true, nil
+}
+
+// Complete stores the response for key
+func (s *MemoryStore) Complete(ctx context.Context, key string, resp Response, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.records[key]
+	if !ok {
+		return nil
+	}
+	rec.Completed = true
+	rec.Response = resp
+	rec.ExpiresAt = expiresAt
+	return nil
+}
+
+// Release forgets key
+func (s *MemoryStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
+
+// Len returns the number of stored records
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.records)
+}
+
+// Close stops the background sweeper
+func (s *MemoryStore) Close() {
+	s.once.Do(func() { close(s.done) })
+}
+
+func (s *MemoryStore) sweep(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, rec := range s.records {
+		if !now.Before(rec.ExpiresAt) {
+			delete(s.records, key)
+		}
+	}
+}
+
+func (s *MemoryStore) sweepLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			s.sweep(now)
+		case <-s.done:
+			return
+		}
+	}
+}
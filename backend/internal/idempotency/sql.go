@@ -0,0 +1,114 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/database"
+)
+
+// SQLStore keeps records in the idempotency_keys table so every replica sees them
+type SQLStore struct {
+	db *database.DB
+}
+
+// NewSQLStore creates a store on db; the idempotency_keys migration must be applied
+func NewSQLStore(db *database.DB) *SQLStore {
+	return &SQLStore{db: db}
+}
+
+// Begin inserts a reservation, or takes over an expired record. Both
+// statements are single atomic writes, so concurrent callers cannot both win.
+func (s *SQLStore) Begin(ctx context.Context, key, fingerprint string, lockUntil, now time.Time) (*Record, bool, error) {
+	res, err := s.db.ExecContext(ctx, s.db.Rebind(`INSERT INTO idempotency_keys
+		(id, fingerprint, completed, status, created_at, expires_at)
+		VALUES (?, ?, FALSE, 0, ?, ?) ON CONFLICT (id) DO NOTHING`),
+		key, fingerprint, now.UTC(), lockUntil.UTC())
+	if err != nil {
+		return nil, false, fmt.Errorf("idempotency: reserve key: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 1 {
+		return nil, true, nil
+	}
+
+	res, err = s.db.ExecContext(ctx, s.db.Rebind(`UPDATE idempotency_keys
+		SET fingerprint = ?, completed = FALSE, status = 0, headers = NULL, body = NULL, created_at = ?, expires_at = ?
+		WHERE id = ? AND expires_at <= ?`),
+		fingerprint, now.UTC(), lockUntil.UTC(), key, now.UTC())
+	if err != nil {
+		return nil, false, fmt.Errorf("idempotency: take over expired key: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 1 {
+		return nil, true, nil
+	}
+
+	rec, err := s.get(ctx, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Released between our statements; let the client retry
+		return &Record{Key: key, Fingerprint: fingerprint}, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return rec, false, nil
+}
+
+// Complete stores the response for key
+func (s *SQLStore) Complete(ctx context.Context, key string, resp Response, expiresAt time.Time) error {
+	headers, err := json.Marshal(resp.Header)
+	if err != nil {
+		return fmt.Errorf("idempotency: encode headers: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx, s.db.Rebind(`UPDATE idempotency_keys
+		SET completed = TRUE, status = ?, headers = ?, body = ?, expires_at = ? WHERE id = ?`),
+		resp.Status, string(headers), resp.Body, expiresAt.UTC(), key)
+	if err != nil {
+		return fmt.Errorf("idempotency: store response: %w", err)
+	}
+	return nil
+}
+
+// Release deletes the reservation for key
+func (s *SQLStore) Release(ctx context.Context, key string) error {
+	if _, err := s.db.ExecContext(ctx, s.db.Rebind("DELETE FROM idempotency_keys WHERE id = ?"), key); err != nil {
+		return fmt.Errorf("idempotency: release key: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired removes records that expired before now and returns how many it removed
+func (s *SQLStore) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx, s.db.Rebind("DELETE FROM idempotency_keys WHERE expires_at <= ?"), now.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("idempotency: delete expired: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+func (s *SQLStore) get(ctx context.Context, key string) (*Record, error) {
+	var (
+		rec     = Record{Key: key}
+		headers sql.NullString
+	)
+	err := s.db.QueryRowContext(ctx, s.db.Rebind(`SELECT fingerprint, completed, status, headers, body, expires_at
+		FROM idempotency_keys WHERE id = ?`), key).
+		Scan(&rec.Fingerprint, &rec.Completed, &rec.Response.Status, &headers, &rec.Response.Body, &rec.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("idempotency: load key: %w", err)
+	}
+	if headers.Valid && headers.String != "" {
+		rec.Response.Header = make(http.Header)
+		if err := json.Unmarshal([]byte(headers.String), &rec.Response.Header); err != nil {
+			return nil, fmt.Errorf("idempotency: decode headers: %w", err)
+		}
+	}
+	return &rec, nil
+}
@@ -0,0 +1,210 @@
+package jobs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule yields the activation times of a recurring job
+type Schedule interface {
+	// Next returns the first activation strictly after t
+	Next(t time.Time) time.Time
+}
+
+// CronSchedule is a parsed five-field cron expression
+// (minute hour day-of-month month day-of-week), evaluated in the location
+// of the time passed to Next
+type CronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar/dowStar record an unrestricted field; when both day fields are
+	// restricted a day matches if either does, as in classic cron
+	domStar, dowStar bool
+}
+
+// EverySchedule fires at a fixed interval. Activations are aligned to
+// multiples of the interval so every replica computes the same times.
+type EverySchedule struct {
+	Interval time.Duration
+}
+
+// Next returns the first multiple of the interval after t
+func (s EverySchedule) Next(t time.Time) time.Time {
+	return t.Truncate(s.Interval).Add(s.Interval)
+}
+
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = cronField{name: "minute", min: 0, max: 59}
+	hourField   = cronField{name: "hour", min: 0, max: 23}
+	domField    = cronField{name: "day of month", min: 1, max: 31}
+	monthField  = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowField = cronField{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseSchedule parses a cron expression such as "*/15 9-17 * * mon-fri",
+// a macro like "@daily", or "@every 90s"
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil || d < time.Second {
+			return nil, fmt.Errorf("jobs: invalid interval in %q", spec)
+		}
+		return EverySchedule{Interval: d}, nil
+	}
+	if expanded, ok := cronMacros[strings.ToLower(spec)]; ok {
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("jobs: cron expression %q must have 5 fields, got %d", spec, len(fields))
+	}
+
+	var (
+		s   CronSchedule
+		err error
+	)
+	if s.minute, err = minuteField.parse(fields[0]); err != nil {
+		return nil, err
+	}
+	if s.hour, err = hourField.parse(fields[1]); err != nil {
+		return nil, err
+	}
+	if s.dom, err = domField.parse(fields[2]); err != nil {
+		return nil, err
+	}
+	if s.month, err = monthField.parse(fields[3]); err != nil {
+		return nil, err
+	}
+	if s.dow, err = dowField.parse(fields[4]); err != nil {
+		return nil, err
+	}
+	// 7 is an alias for Sunday
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+	s.domStar = fields[2] == "*" || fields[2] == "?"
+	s.dowStar = fields[4] == "*" || fields[4] == "?"
+	return &s, nil
+}
+
+// parse turns "1,5-10/2,*/15" into a bit set of allowed values
+func (f cronField) parse(expr string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(expr, ",") {
+		rangeExpr, stepExpr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepExpr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("jobs: invalid step %q in %s field", stepExpr, f.name)
+			}
+			step = n
+		}
+
+		var lo, hi int
+		switch {
+		case rangeExpr == "*" || rangeExpr == "?":
+			lo, hi = f.min, f.max
+		case strings.Contains(rangeExpr, "-"):
+			a, b, _ := strings.Cut(rangeExpr, "-")
+			var err error
+			if lo, err = f.value(a); err != nil {
+				return 0, err
+			}
+			if hi, err = f.value(b); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("jobs: empty range %q in %s field", rangeExpr, f.name)
+			}
+		default:
+			v, err := f.value(rangeExpr)
+			if err != nil {
+				return 0, err
+			}
+			// "5/10" means starting at 5 every 10
+			lo, hi = v, v
+			if hasStep {
+				hi = f.max
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func (f cronField) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("jobs: %s value %q is not in %d-%d", f.name, s, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next returns the first matching minute after t, or the zero time if the
+// expression can never match (such as "0 0 30 2 *")
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
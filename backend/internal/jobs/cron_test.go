@@ -0,0 +1,75 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseScheduleNext(t *testing.T) {
+	// Friday
+	from := time.Date(2025, 8, 1, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, 8, 1, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, 8, 1, 10, 15, 0, 0, time.UTC)},
+		{"5 * * * *", time.Date(2025, 8, 1, 11, 5, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2025, 8, 1, 13, 0, 0, 0, time.UTC)},
+		{"30 8 * * mon-wed", time.Date(2025, 8, 4, 8, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2025, 8, 3, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan *", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 15 * 1", time.Date(2025, 8, 4, 12, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2025, 8, 1, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2025, 8, 2, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2025, 8, 3, 0, 0, 0, 0, time.UTC)},
+		{"@every 90s", time.Date(2025, 8, 1, 10, 9, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := ParseSchedule(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseSchedule() error = %v", err)
+			}
+			if got := s.Next(from); !got.Equal(tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseScheduleNeverMatches(t *testing.T) {
+	s, err := ParseSchedule("0 0 31 2 *")
+	if err != nil {
+		t.Fatalf("ParseSchedule() error = %v", err)
+	}
+	if got := s.Next(time.Now()); !got.IsZero() {
+		t.Errorf("Expected zero time, got %v", got)
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"* * * foo *",
+		"@every 10ms",
+		"@every soon",
+	}
+
+	for _, spec := range tests {
+		if _, err := ParseSchedule(spec); err == nil {
+			t.Errorf("Expected error for %q", spec)
+		}
+	}
+}
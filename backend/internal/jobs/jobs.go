@@ -0,0 +1,117 @@
+// Package jobs runs deferred and periodic work from a database-backed queue.
+//
+// Jobs are rows in the jobs table. Any number of replicas may poll the same
+// table: rows are claimed with FOR UPDATE SKIP LOCKED on Postgres (SQLite
+// serializes writers, which gives the same guarantee), so each job runs on
+// one worker at a time. Failed jobs are retried with exponential backoff and
+// moved to the dead-letter state once they run out of attempts.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Status is the state of a job row
+type Status string
+
+// Job states. Dead is the dead-letter state: the job failed on every
+// attempt and will not run again.
+const (
+	StatusPending Status = "pending"
+	StatusRunning Status = "running"
+	StatusDone    Status = "done"
+	StatusDead    Status = "dead"
+)
+
+// Statuses lists every state in lifecycle order
+var Statuses = []Status{StatusPending, StatusRunning, StatusDone, StatusDead}
+
+// Valid reports whether s is a known state
+func (s Status) Valid() bool {
+	for _, known := range Statuses {
+		if s == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Job is a unit of work stored in the queue
+type Job struct {
+	ID          int64           `json:"id"`
+	Type        string          `json:"type"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      Status          `json:"status"`
+	UniqueKey   string          `json:"unique_key,omitempty"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	RunAt       time.Time       `json:"run_at"`
+	LockedBy    string          `json:"locked_by,omitempty"`
+	LockedUntil *time.Time      `json:"locked_until,omitempty"`
+	LastError   string          `json:"last_error,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	FinishedAt  *time.Time      `json:"finished_at,omitempty"`
+}
+
+// Decode unmarshals the payload into v
+func (j *Job) Decode(v any) error {
+	return json.Unmarshal(j.Payload, v)
+}
+
+// Handler processes one job. A returned error schedules a retry unless it
+// is wrapped with Permanent. ctx is cancelled on shutdown and when the
+// job's lock expires.
+type Handler func(ctx context.Context, job *Job) error
+
+// HandlerFunc adapts a handler that takes a typed payload
+func HandlerFunc[T any](fn func(ctx context.Context, payload T) error) Handler {
+	return func(ctx context.Context, job *Job) error {
+		var payload T
+		if err := job.Decode(&payload); err != nil {
+			return Permanent(err)
+		}
+		return fn(ctx, payload)
+	}
+}
+
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as not worth retrying; the job goes straight to dead
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err: err}
+}
+
+// IsPermanent reports whether err was wrapped with Permanent
+func IsPermanent(err error) bool {
+	var p permanentError
+	return errors.As(err, &p)
+}
+
+// Backoff returns the delay before retrying after the given number of
+// failed attempts: base, 2*base, 4*base, ... capped at max
+func Backoff(base, max time.Duration, attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	d := base
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= max || d <= 0 {
+			return max
+		}
+	}
+	if d > max {
+		return max
+	}
+	return d
+}
@@ -0,0 +1,382 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Options configures a Scheduler; zero fields take the defaults below
+type Options struct {
+	// Workers is the number of jobs this process runs at once (4)
+	Workers int
+	// PollInterval is how often the queue is checked for due jobs (1s)
+	PollInterval time.Duration
+	// MaxAttempts is the default number of tries before a job is dead (5)
+	MaxAttempts int
+	// BackoffBase and BackoffMax bound the retry delay (30s, 1h)
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	// LockTimeout is how long a claimed job may run before another worker
+	// may take it over; handlers are cancelled when it runs out (5m)
+	LockTimeout time.Duration
+	// WorkerID identifies this process in locked_by (hostname-pid)
+	WorkerID string
+	Logger   *slog.Logger
+}
+
+// storeTimeout bounds the bookkeeping writes after a job ran, which must
+// succeed even while shutdown is cancelling the job contexts
+const storeTimeout = 10 * time.Second
+
+// releaseGrace is how long Stop waits for cancelled jobs to hand back their rows
+const releaseGrace = 2 * time.Second
+
+// Scheduler polls the store and runs due jobs on a bounded pool of workers
+type Scheduler struct {
+	store *Store
+	opts  Options
+	now   func() time.Time
+
+	mu       sync.RWMutex
+	handlers map[string]Handler
+	periodic []*periodicJob
+
+	slots   chan struct{}
+	wake    chan struct{}
+	running sync.WaitGroup
+
+	started    bool
+	stopPoll   context.CancelFunc
+	pollDone   chan struct{}
+	jobCtx     context.Context
+	cancelJobs context.CancelFunc
+}
+
+type periodicJob struct {
+	name     string
+	jobType  string
+	payload  []byte
+	schedule Schedule
+	next     time.Time
+}
+
+// New creates a Scheduler on store
+func New(store *Store, opts Options) *Scheduler {
+	if opts.Workers <= 0 {
+		opts.Workers = 4
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 5
+	}
+	if opts.BackoffBase <= 0 {
+		opts.BackoffBase = 30 * time.Second
+	}
+	if opts.BackoffMax < opts.BackoffBase {
+		opts.BackoffMax = max(time.Hour, opts.BackoffBase)
+	}
+	if opts.LockTimeout <= 0 {
+		opts.LockTimeout = 5 * time.Minute
+	}
+	if opts.WorkerID == "" {
+		host, _ := os.Hostname()
+		opts.WorkerID = host + "-" + strconv.Itoa(os.Getpid())
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+
+	return &Scheduler{
+		store:    store,
+		opts:     opts,
+		now:      time.Now,
+		handlers: make(map[string]Handler),
+		slots:    make(chan struct{}, opts.Workers),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Register sets the handler for jobType, replacing any previous one
+func (s *Scheduler) Register(jobType string, h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[jobType] = h
+}
+
+// Cron enqueues a jobType job with payload on every activation of spec
+// (see ParseSchedule). name must be unique and stable across deploys: it
+// keys the enqueued jobs so replicas running the same schedule enqueue
+// each activation once. Call Cron before Start.
+func (s *Scheduler) Cron(name, spec, jobType string, payload any) error {
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("jobs: encode payload for %s: %w", name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return fmt.Errorf("jobs: cron %s registered after Start", name)
+	}
+	for _, p := range s.periodic {
+		if p.name == name {
+			return fmt.Errorf("jobs: cron %s registered twice", name)
+		}
+	}
+	s.periodic = append(s.periodic, &periodicJob{name: name, jobType: jobType, payload: raw, schedule: schedule})
+	return nil
+}
+
+// Option adjusts a job before Enqueue stores it
+type Option func(*Job)
+
+// At runs the job no earlier than t
+func At(t time.Time) Option {
+	return func(j *Job) { j.RunAt = t }
+}
+
+// After delays the job by d
+func After(d time.Duration) Option {
+	return func(j *Job) { j.RunAt = time.Now().Add(d) }
+}
+
+// MaxAttempts overrides the scheduler's default number of tries
+func MaxAttempts(n int) Option {
+	return func(j *Job) { j.MaxAttempts = n }
+}
+
+// UniqueKey drops the job if another job with key was ever enqueued
+func UniqueKey(key string) Option {
+	return func(j *Job) { j.UniqueKey = key }
+}
+
+// Enqueue stores a jobType job with payload encoded as JSON. It returns
+// nil and no error when UniqueKey dropped the job as a duplicate.
+func (s *Scheduler) Enqueue(ctx context.Context, jobType string, payload any, opts ...Option) (*Job, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("jobs: encode payload for %s: %w", jobType, err)
+	}
+	job := &Job{Type: jobType, Payload: raw, MaxAttempts: s.opts.MaxAttempts}
+	for _, opt := range opts {
+		opt(job)
+	}
+
+	inserted, err := s.store.Insert(ctx, job)
+	if err != nil || !inserted {
+		return nil, err
+	}
+	if !job.RunAt.After(s.now()) {
+		s.poke()
+	}
+	return job, nil
+}
+
+// poke wakes the poll loop early
+func (s *Scheduler) poke() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start begins polling. It returns immediately; call Stop to shut down.
+func (s *Scheduler) Start(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return errors.New("jobs: scheduler already started")
+	}
+	s.started = true
+
+	now := s.now().UTC()
+	for _, p := range s.periodic {
+		p.next = p.schedule.Next(now)
+	}
+
+	var pollCtx context.Context
+	pollCtx, s.stopPoll = context.WithCancel(context.Background())
+	s.jobCtx, s.cancelJobs = context.WithCancel(context.Background())
+	s.pollDone = make(chan struct{})
+	go s.loop(pollCtx)
+	return nil
+}
+
+// Stop stops claiming jobs and waits for running ones. If ctx ends first
+// the running jobs are cancelled and released back to the queue without
+// using up an attempt.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.RLock()
+	started := s.started
+	s.mu.RUnlock()
+	if !started {
+		return nil
+	}
+
+	s.stopPoll()
+	<-s.pollDone
+
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.cancelJobs()
+		return nil
+	case <-ctx.Done():
+	}
+
+	s.cancelJobs()
+	select {
+	case <-done:
+	case <-time.After(releaseGrace):
+	}
+	return fmt.Errorf("jobs: running jobs interrupted: %w", ctx.Err())
+}
+
+func (s *Scheduler) loop(ctx context.Context) {
+	defer close(s.pollDone)
+	ticker := time.NewTicker(s.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.tick(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// tick enqueues due periodic jobs and claims as many jobs as there are free workers
+func (s *Scheduler) tick(ctx context.Context) {
+	now := s.now().UTC()
+	s.enqueuePeriodic(ctx, now)
+
+	free := 0
+acquire:
+	for free < s.opts.Workers {
+		select {
+		case s.slots <- struct{}{}:
+			free++
+		default:
+			break acquire
+		}
+	}
+	if free == 0 {
+		return
+	}
+
+	claimed, err := s.store.Claim(ctx, s.opts.WorkerID, free, now.Add(s.opts.LockTimeout), now)
+	if err != nil && ctx.Err() == nil {
+		s.opts.Logger.Error("claiming jobs failed", slog.Any("error", err))
+	}
+	for i := len(claimed); i < free; i++ {
+		<-s.slots
+	}
+
+	for _, job := range claimed {
+		s.running.Add(1)
+		go s.run(job)
+	}
+}
+
+func (s *Scheduler) enqueuePeriodic(ctx context.Context, now time.Time) {
+	for _, p := range s.periodic {
+		if p.next.IsZero() || now.Before(p.next) {
+			continue
+		}
+		job := &Job{
+			Type:        p.jobType,
+			Payload:     p.payload,
+			MaxAttempts: s.opts.MaxAttempts,
+			RunAt:       p.next,
+			UniqueKey:   "cron:" + p.name + ":" + p.next.Format(time.RFC3339),
+		}
+		if _, err := s.store.Insert(ctx, job); err != nil {
+			if ctx.Err() == nil {
+				s.opts.Logger.Error("enqueueing periodic job failed", slog.String("cron", p.name), slog.Any("error", err))
+			}
+			continue
+		}
+		// Activations missed while the process was down are skipped
+		p.next = p.schedule.Next(now)
+	}
+}
+
+func (s *Scheduler) run(job *Job) {
+	defer func() {
+		<-s.slots
+		s.running.Done()
+	}()
+
+	s.mu.RLock()
+	handler := s.handlers[job.Type]
+	s.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(s.jobCtx, s.opts.LockTimeout)
+	started := s.now()
+	err := s.call(ctx, handler, job)
+	cancel()
+
+	storeCtx, cancelStore := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancelStore()
+
+	log := s.opts.Logger.With(
+		slog.Int64("job_id", job.ID),
+		slog.String("job_type", job.Type),
+		slog.Int("attempt", job.Attempts),
+	)
+	now := s.now()
+
+	var storeErr error
+	switch {
+	case err == nil:
+		storeErr = s.store.Complete(storeCtx, job.ID, s.opts.WorkerID, now)
+		log.Debug("job done", slog.Duration("duration", now.Sub(started)))
+	case s.jobCtx.Err() != nil:
+		storeErr = s.store.Release(storeCtx, job.ID, s.opts.WorkerID, now)
+		log.Info("job interrupted by shutdown, released")
+	case IsPermanent(err) || job.Attempts >= job.MaxAttempts:
+		storeErr = s.store.Kill(storeCtx, job.ID, s.opts.WorkerID, err.Error(), now)
+		log.Error("job failed, moved to dead-letter", slog.Any("error", err))
+	default:
+		delay := Backoff(s.opts.BackoffBase, s.opts.BackoffMax, job.Attempts)
+		storeErr = s.store.Retry(storeCtx, job.ID, s.opts.WorkerID, err.Error(), now.Add(delay))
+		log.Warn("job failed, will retry", slog.Any("error", err), slog.Duration("retry_in", delay))
+	}
+	if storeErr != nil {
+		log.Error("recording job result failed", slog.Any("error", storeErr))
+	}
+}
+
+// call runs handler, turning a missing handler or a panic into an error
+func (s *Scheduler) call(ctx context.Context, handler Handler, job *Job) (err error) {
+	if handler == nil {
+		return fmt.Errorf("no handler registered for job type %q", job.Type)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
@@ -0,0 +1,223 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestScheduler(t *testing.T, store *Store) *Scheduler {
+	t.Helper()
+	s := New(store, Options{
+		Workers:      2,
+		PollInterval: 10 * time.Millisecond,
+		MaxAttempts:  3,
+		BackoffBase:  time.Millisecond,
+		BackoffMax:   5 * time.Millisecond,
+		WorkerID:     "test",
+	})
+	t.Cleanup(func() { s.Stop(context.Background()) })
+	return s
+}
+
+// waitForStatus polls until the job reaches status or the test times out
+func waitForStatus(t *testing.T, store *Store, id int64, status Status) *Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, err := store.Get(context.Background(), id)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if job.Status == status {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected job %d to become %s, still %s", id, status, job.Status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestSchedulerRunsJobs(t *testing.T) {
+	store := openTestStore(t)
+	s := newTestScheduler(t, store)
+
+	type welcome struct {
+		Email string `json:"email"`
+	}
+	got := make(chan string, 1)
+	s.Register("welcome", HandlerFunc(func(ctx context.Context, p welcome) error {
+		got <- p.Email
+		return nil
+	}))
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	job, err := s.Enqueue(context.Background(), "welcome", welcome{Email: "alice@example.com"})
+	if err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	done := waitForStatus(t, store, job.ID, StatusDone)
+	if email := <-got; email != "alice@example.com" {
+		t.Errorf("Expected payload to reach the handler, got %q", email)
+	}
+	if done.Attempts != 1 || done.FinishedAt == nil {
+		t.Errorf("Unexpected finished job %+v", done)
+	}
+
+	if dup, err := s.Enqueue(context.Background(), "welcome", welcome{}, UniqueKey("once")); err != nil || dup == nil {
+		t.Fatalf("Expected first keyed job to be stored, got %v, %v", dup, err)
+	}
+	if dup, err := s.Enqueue(context.Background(), "welcome", welcome{}, UniqueKey("once")); err != nil || dup != nil {
+		t.Errorf("Expected duplicate keyed job to be dropped, got %v, %v", dup, err)
+	}
+}
+
+func TestSchedulerFailures(t *testing.T) {
+	tests := []struct {
+		name     string
+		handler  Handler
+		attempts int
+		lastErr  string
+	}{
+		{"retried until dead", func(context.Context, *Job) error { return errors.New("smtp down") }, 3, "smtp down"},
+		{"permanent", func(context.Context, *Job) error { return Permanent(errors.New("bad address")) }, 1, "bad address"},
+		{"panic", func(context.Context, *Job) error { panic("nil map") }, 3, "panic: nil map"},
+		{"bad payload", HandlerFunc(func(context.Context, int) error { return nil }), 1, "cannot unmarshal"},
+		{"no handler", nil, 3, "no handler registered"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := openTestStore(t)
+			s := newTestScheduler(t, store)
+			if tt.handler != nil {
+				s.Register("task", tt.handler)
+			}
+			s.Start(context.Background())
+
+			job, err := s.Enqueue(context.Background(), "task", map[string]string{"to": "x"})
+			if err != nil {
+				t.Fatalf("Enqueue() error = %v", err)
+			}
+			dead := waitForStatus(t, store, job.ID, StatusDead)
+			if dead.Attempts != tt.attempts {
+				t.Errorf("Expected %d attempts, got %d", tt.attempts, dead.Attempts)
+			}
+			if !strings.Contains(dead.LastError, tt.lastErr) {
+				t.Errorf("Expected last error to mention %q, got %q", tt.lastErr, dead.LastError)
+			}
+		})
+	}
+}
+
+func TestSchedulerDelayedJob(t *testing.T) {
+	store := openTestStore(t)
+	s := newTestScheduler(t, store)
+	s.Register("later", func(context.Context, *Job) error { return nil })
+	s.Start(context.Background())
+
+	job, err := s.Enqueue(context.Background(), "later", nil, After(time.Hour))
+	if err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if got, _ := store.Get(context.Background(), job.ID); got.Status != StatusPending || got.Attempts != 0 {
+		t.Errorf("Expected delayed job to wait, got %s after %d attempts", got.Status, got.Attempts)
+	}
+}
+
+func TestSchedulerCronEnqueuesOncePerActivation(t *testing.T) {
+	store := openTestStore(t)
+	ctx := context.Background()
+	activation := time.Date(2025, 8, 1, 10, 0, 0, 0, time.UTC)
+
+	// Two replicas with the same schedule
+	var replicas []*Scheduler
+	for i := 0; i < 2; i++ {
+		s := New(store, Options{})
+		if err := s.Cron("cleanup", "@hourly", "cleanup", map[string]int{"days": 7}); err != nil {
+			t.Fatalf("Cron() error = %v", err)
+		}
+		s.periodic[0].next = activation
+		replicas = append(replicas, s)
+	}
+	for _, s := range replicas {
+		s.enqueuePeriodic(ctx, activation.Add(time.Second))
+	}
+
+	jobs, err := store.List(ctx, Filter{Type: "cleanup"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(jobs) != 1 {
+		t.Fatalf("Expected one job per activation, got %d", len(jobs))
+	}
+	if !jobs[0].RunAt.Equal(activation) || string(jobs[0].Payload) != `{"days":7}` {
+		t.Errorf("Unexpected periodic job %+v", jobs[0])
+	}
+	if next := replicas[0].periodic[0].next; !next.Equal(activation.Add(time.Hour)) {
+		t.Errorf("Expected next activation at %v, got %v", activation.Add(time.Hour), next)
+	}
+
+	s := replicas[0]
+	if err := s.Cron("cleanup", "@daily", "cleanup", nil); err == nil {
+		t.Error("Expected duplicate cron name to fail")
+	}
+	if err := s.Cron("broken", "every day", "cleanup", nil); err == nil {
+		t.Error("Expected invalid spec to fail")
+	}
+}
+
+func TestSchedulerStopReleasesInterruptedJobs(t *testing.T) {
+	store := openTestStore(t)
+	s := newTestScheduler(t, store)
+	started := make(chan struct{})
+	s.Register("slow", func(ctx context.Context, _ *Job) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	s.Start(context.Background())
+
+	job, err := s.Enqueue(context.Background(), "slow", nil)
+	if err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.Stop(ctx); err == nil {
+		t.Error("Expected Stop to report interrupted jobs")
+	}
+
+	released := waitForStatus(t, store, job.ID, StatusPending)
+	if released.Attempts != 0 || released.LockedBy != "" {
+		t.Errorf("Expected the job back in the queue unused, got %+v", released)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, 10 * time.Second},
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{4, 80 * time.Second},
+		{7, 5 * time.Minute},
+		{200, 5 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := Backoff(10*time.Second, 5*time.Minute, tt.attempts); got != tt.want {
+			t.Errorf("Backoff(%d): expected %v, got %v", tt.attempts, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/database"
+)
+
+var (
+	// ErrNotFound is returned when a job does not exist
+	ErrNotFound = errors.New("jobs: job not found")
+	// ErrLockLost means the job was reclaimed by another worker before it finished
+	ErrLockLost = errors.New("jobs: lock lost to another worker")
+)
+
+const jobColumns = `id, type, payload, status, unique_key, attempts, max_attempts, run_at,
+	locked_by, locked_until, last_error, created_at, updated_at, finished_at`
+
+// Store persists jobs in the jobs table
+type Store struct {
+	db *database.DB
+}
+
+// NewStore creates a Store on db; the jobs migration must be applied
+func NewStore(db *database.DB) *Store {
+	return &Store{db: db}
+}
+
+// Insert adds job as pending and fills in its ID. A job whose UniqueKey is
+// already taken is not inserted and Insert reports false.
+func (s *Store) Insert(ctx context.Context, job *Job) (bool, error) {
+	now := time.Now().UTC()
+	if job.RunAt.IsZero() {
+		job.RunAt = now
+	}
+	if len(job.Payload) == 0 {
+		job.Payload = []byte("{}")
+	}
+	job.Status = StatusPending
+	job.CreatedAt, job.UpdatedAt = now, now
+
+	err := s.db.QueryRowContext(ctx, s.db.Rebind(`INSERT INTO jobs
+		(type, payload, status, unique_key, max_attempts, run_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT (unique_key) DO NOTHING RETURNING id`),
+		job.Type, string(job.Payload), job.Status, nullString(job.UniqueKey), job.MaxAttempts,
+		job.RunAt.UTC(), now, now).Scan(&job.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("jobs: insert: %w", err)
+	}
+	return true, nil
+}
+
+// Claim locks up to limit runnable jobs for worker until lockUntil and
+// counts an attempt on each. Runnable means pending and due, or running
+// with an expired lock (its worker died). Concurrent claims never return
+// the same job.
+func (s *Store) Claim(ctx context.Context, worker string, limit int, lockUntil, now time.Time) ([]*Job, error) {
+	skipLocked := ""
+	if s.db.Dialect == database.Postgres {
+		skipLocked = " FOR UPDATE SKIP LOCKED"
+	}
+	query := s.db.Rebind(`UPDATE jobs
+		SET status = ?, attempts = attempts + 1, locked_by = ?, locked_until = ?, updated_at = ?
+		WHERE id IN (
+			SELECT id FROM jobs
+			WHERE (status = ? AND run_at <= ?) OR (status = ? AND locked_until <= ?)
+			ORDER BY run_at, id LIMIT ?` + skipLocked + `
+		)
+		RETURNING ` + jobColumns)
+
+	now = now.UTC()
+	rows, err := s.db.QueryContext(ctx, query,
+		StatusRunning, worker, lockUntil.UTC(), now,
+		StatusPending, now, StatusRunning, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("jobs: claim: %w", err)
+	}
+	return scanJobs(rows)
+}
+
+// Complete marks a job claimed by worker as done
+func (s *Store) Complete(ctx context.Context, id int64, worker string, now time.Time) error {
+	return s.finish(ctx, id, worker, `status = ?, last_error = NULL, finished_at = ?`, StatusDone, now.UTC())
+}
+
+// Retry returns a failed job to pending, to run again at runAt
+func (s *Store) Retry(ctx context.Context, id int64, worker, lastError string, runAt time.Time) error {
+	return s.finish(ctx, id, worker, `status = ?, last_error = ?, run_at = ?`, StatusPending, lastError, runAt.UTC())
+}
+
+// Kill moves a job to the dead-letter state
+func (s *Store) Kill(ctx context.Context, id int64, worker, lastError string, now time.Time) error {
+	return s.finish(ctx, id, worker, `status = ?, last_error = ?, finished_at = ?`, StatusDead, lastError, now.UTC())
+}
+
+// Release hands an interrupted job back without counting the attempt
+func (s *Store) Release(ctx context.Context, id int64, worker string, now time.Time) error {
+	return s.finish(ctx, id, worker, `status = ?, attempts = attempts - 1, run_at = ?`, StatusPending, now.UTC())
+}
+
+// finish updates a job only while worker still holds its lock, so a worker
+// whose lock expired cannot overwrite the job's new owner
+func (s *Store) finish(ctx context.Context, id int64, worker, set string, args ...any) error {
+	query := s.db.Rebind(`UPDATE jobs SET ` + set + `, locked_by = NULL, locked_until = NULL, updated_at = ?
+		WHERE id = ? AND status = ? AND locked_by = ?`)
+	args = append(args, time.Now().UTC(), id, StatusRunning, worker)
+	res, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("jobs: update job %d: %w", id, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w (job %d)", ErrLockLost, id)
+	}
+	return nil
+}
+
+// Get returns the job with id, or ErrNotFound
+func (s *Store) Get(ctx context.Context, id int64) (*Job, error) {
+	rows, err := s.db.QueryContext(ctx, s.db.Rebind(`SELECT `+jobColumns+` FROM jobs WHERE id = ?`), id)
+	if err != nil {
+		return nil, fmt.Errorf("jobs: get: %w", err)
+	}
+	jobs, err := scanJobs(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(jobs) == 0 {
+		return nil, ErrNotFound
+	}
+	return jobs[0], nil
+}
+
+// Filter narrows List; zero values match everything
+type Filter struct {
+	Status Status
+	Type   string
+	Limit  int
+}
+
+// List returns jobs matching f, most recently updated first
+func (s *Store) List(ctx context.Context, f Filter) ([]*Job, error) {
+	var (
+		where []string
+		args  []any
+	)
+	if f.Status != "" {
+		where = append(where, "status = ?")
+		args = append(args, f.Status)
+	}
+	if f.Type != "" {
+		where = append(where, "type = ?")
+		args = append(args, f.Type)
+	}
+	query := `SELECT ` + jobColumns + ` FROM jobs`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY updated_at DESC, id DESC"
+	if f.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, f.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, s.db.Rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("jobs: list: %w", err)
+	}
+	return scanJobs(rows)
+}
+
+// Counts returns the number of jobs in each state; every state is present
+func (s *Store) Counts(ctx context.Context) (map[Status]int, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT status, COUNT(*) FROM jobs GROUP BY status")
+	if err != nil {
+		return nil, fmt.Errorf("jobs: count: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[Status]int, len(Statuses))
+	for _, st := range Statuses {
+		counts[st] = 0
+	}
+	for rows.Next() {
+		var (
+			st Status
+			n  int
+		)
+		if err := rows.Scan(&st, &n); err != nil {
+			return nil, fmt.Errorf("jobs: count: %w", err)
+		}
+		counts[st] = n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("jobs: count: %w", err)
+	}
+	return counts, nil
+}
+
+// DeleteFinished removes done jobs that finished before cutoff; dead jobs
+// are kept for inspection
+func (s *Store) DeleteFinished(ctx context.Context, cutoff time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx, s.db.Rebind("DELETE FROM jobs WHERE status = ? AND finished_at < ?"),
+		StatusDone, cutoff.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("jobs: delete finished: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+func scanJobs(rows *sql.Rows) ([]*Job, error) {
+	defer rows.Close()
+
+	var jobs []*Job
+	for rows.Next() {
+		var (
+			j                        Job
+			payload                  string
+			uniqueKey, lockedBy, msg sql.NullString
+			lockedUntil, finishedAt  sql.NullTime
+		)
+		err := rows.Scan(&j.ID, &j.Type, &payload, &j.Status, &uniqueKey, &j.Attempts, &j.MaxAttempts, &j.RunAt,
+			&lockedBy, &lockedUntil, &msg, &j.CreatedAt, &j.UpdatedAt, &finishedAt)
+		if err != nil {
+			return nil, fmt.Errorf("jobs: scan: %w", err)
+		}
+		j.Payload = []byte(payload)
+		j.UniqueKey, j.LockedBy, j.LastError = uniqueKey.String, lockedBy.String, msg.String
+		if lockedUntil.Valid {
+			j.LockedUntil = &lockedUntil.Time
+		}
+		if finishedAt.Valid {
+			j.FinishedAt = &finishedAt.Time
+		}
+		jobs = append(jobs, &j)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("jobs: scan: %w", err)
+	}
+	return jobs, nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,219 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/database/dbtest"
+)
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	return NewStore(dbtest.Open(t))
+}
+
+func insertJob(t *testing.T, store *Store, job *Job) *Job {
+	t.Helper()
+	if job.MaxAttempts == 0 {
+		job.MaxAttempts = 3
+	}
+	if _, err := store.Insert(context.Background(), job); err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+	return job
+}
+
+func TestStoreInsertUniqueKey(t *testing.T) {
+	store := openTestStore(t)
+	ctx := context.Background()
+
+	first := &Job{Type: "report", UniqueKey: "report:2025-08", MaxAttempts: 3}
+	if ok, err := store.Insert(ctx, first); err != nil || !ok {
+		t.Fatalf("Expected first insert to succeed, got %v %v", ok, err)
+	}
+	dup := &Job{Type: "report", UniqueKey: "report:2025-08", MaxAttempts: 3}
+	if ok, err := store.Insert(ctx, dup); err != nil || ok {
+		t.Fatalf("Expected duplicate to be skipped, got %v %v", ok, err)
+	}
+	// Jobs without a key never collide
+	insertJob(t, store, &Job{Type: "report"})
+	insertJob(t, store, &Job{Type: "report"})
+
+	got, err := store.Get(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Status != StatusPending || string(got.Payload) != "{}" || got.UniqueKey != "report:2025-08" {
+		t.Errorf("Unexpected job %+v", got)
+	}
+	if _, err := store.Get(ctx, 999); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestStoreClaim(t *testing.T) {
+	store := openTestStore(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	due := insertJob(t, store, &Job{Type: "a", RunAt: now.Add(-time.Minute)})
+	insertJob(t, store, &Job{Type: "later", RunAt: now.Add(time.Hour)})
+
+	claimed, err := store.Claim(ctx, "w1", 10, now.Add(time.Minute), now)
+	if err != nil {
+		t.Fatalf("Claim() error = %v", err)
+	}
+	if len(claimed) != 1 || claimed[0].ID != due.ID {
+		t.Fatalf("Expected only the due job, got %d jobs", len(claimed))
+	}
+	if c := claimed[0]; c.Status != StatusRunning || c.Attempts != 1 || c.LockedBy != "w1" || c.LockedUntil == nil {
+		t.Errorf("Unexpected claimed job %+v", c)
+	}
+
+	if again, _ := store.Claim(ctx, "w2", 10, now.Add(time.Minute), now); len(again) != 0 {
+		t.Errorf("Expected a locked job not to be claimed twice, got %d", len(again))
+	}
+
+	// Once the lock expires another worker takes over
+	later := now.Add(2 * time.Minute)
+	stale, err := store.Claim(ctx, "w2", 10, later.Add(time.Minute), later)
+	if err != nil {
+		t.Fatalf("Claim() error = %v", err)
+	}
+	if len(stale) != 1 || stale[0].LockedBy != "w2" || stale[0].Attempts != 2 {
+		t.Fatalf("Expected w2 to reclaim the stale job, got %+v", stale)
+	}
+	if err := store.Complete(ctx, due.ID, "w1", later); !errors.Is(err, ErrLockLost) {
+		t.Errorf("Expected ErrLockLost for the old owner, got %v", err)
+	}
+	if err := store.Complete(ctx, due.ID, "w2", later); err != nil {
+		t.Errorf("Complete() error = %v", err)
+	}
+}
+
+func TestStoreClaimConcurrent(t *testing.T) {
+	store := openTestStore(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	for i := 0; i < 20; i++ {
+		insertJob(t, store, &Job{Type: "a", RunAt: now.Add(-time.Second)})
+	}
+
+	var (
+		mu   sync.Mutex
+		seen = make(map[int64]string)
+		wg   sync.WaitGroup
+	)
+	for w := 0; w < 4; w++ {
+		worker := fmt.Sprintf("w%d", w)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				claimed, err := store.Claim(ctx, worker, 3, now.Add(time.Minute), now)
+				if err != nil {
+					t.Errorf("Claim() error = %v", err)
+					return
+				}
+				if len(claimed) == 0 {
+					return
+				}
+				mu.Lock()
+				for _, j := range claimed {
+					if prev, ok := seen[j.ID]; ok {
+						t.Errorf("Job %d claimed by %s and %s", j.ID, prev, worker)
+					}
+					seen[j.ID] = worker
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(seen) != 20 {
+		t.Errorf("Expected 20 claimed jobs, got %d", len(seen))
+	}
+}
+
+func TestStoreOutcomes(t *testing.T) {
+	store := openTestStore(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	for i := 0; i < 4; i++ {
+		insertJob(t, store, &Job{Type: "a", RunAt: now.Add(-time.Second)})
+	}
+	claimed, err := store.Claim(ctx, "w", 4, now.Add(time.Minute), now)
+	if err != nil || len(claimed) != 4 {
+		t.Fatalf("Claim() = %d, %v", len(claimed), err)
+	}
+
+	retryAt := now.Add(time.Hour)
+	if err := store.Complete(ctx, claimed[0].ID, "w", now); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if err := store.Retry(ctx, claimed[1].ID, "w", "boom", retryAt); err != nil {
+		t.Fatalf("Retry() error = %v", err)
+	}
+	if err := store.Kill(ctx, claimed[2].ID, "w", "fatal", now); err != nil {
+		t.Fatalf("Kill() error = %v", err)
+	}
+	if err := store.Release(ctx, claimed[3].ID, "w", now); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+
+	tests := []struct {
+		id       int64
+		status   Status
+		attempts int
+		lastErr  string
+		finished bool
+	}{
+		{claimed[0].ID, StatusDone, 1, "", true},
+		{claimed[1].ID, StatusPending, 1, "boom", false},
+		{claimed[2].ID, StatusDead, 1, "fatal", true},
+		{claimed[3].ID, StatusPending, 0, "", false},
+	}
+	for _, tt := range tests {
+		j, err := store.Get(ctx, tt.id)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if j.Status != tt.status || j.Attempts != tt.attempts || j.LastError != tt.lastErr ||
+			(j.FinishedAt != nil) != tt.finished || j.LockedBy != "" {
+			t.Errorf("Job %d: expected %s/%d/%q, got %+v", tt.id, tt.status, tt.attempts, tt.lastErr, j)
+		}
+	}
+	if j, _ := store.Get(ctx, claimed[1].ID); !j.RunAt.Equal(retryAt) {
+		t.Errorf("Expected run_at %v, got %v", retryAt, j.RunAt)
+	}
+
+	counts, err := store.Counts(ctx)
+	if err != nil {
+		t.Fatalf("Counts() error = %v", err)
+	}
+	want := map[Status]int{StatusPending: 2, StatusRunning: 0, StatusDone: 1, StatusDead: 1}
+	for st, n := range want {
+		if counts[st] != n {
+			t.Errorf("Expected %d %s jobs, got %d", n, st, counts[st])
+		}
+	}
+
+	dead, err := store.List(ctx, Filter{Status: StatusDead})
+	if err != nil || len(dead) != 1 || dead[0].ID != claimed[2].ID {
+		t.Errorf("Expected the dead job from List, got %d, %v", len(dead), err)
+	}
+	if all, _ := store.List(ctx, Filter{Limit: 2}); len(all) != 2 {
+		t.Errorf("Expected List to honour the limit, got %d", len(all))
+	}
+
+	n, err := store.DeleteFinished(ctx, now.Add(time.Second))
+	if err != nil || n != 1 {
+		t.Errorf("Expected DeleteFinished to remove the done job, got %d, %v", n, err)
+	}
+}
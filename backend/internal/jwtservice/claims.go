@@ -0,0 +1,35 @@
+package jwtservice
+
+import (
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Token types carried in the "typ" claim
+const (
+	AccessToken  = "access"
+	RefreshToken = "refresh"
+)
+
+// Claims represents JWT token claims
+type Claims struct {
+	UserID    int      `json:"user_id"`
+	Email     string   `json:"email"`
+	Roles     []string `json:"roles,omitempty"`
+	TokenType string   `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+// Valid validates the claims (required by jwt.Claims interface)
+func (c Claims) Valid() error {
+	return c.RegisteredClaims.Valid()
+}
+
+// HasRole reports whether the claims include role
+func (c *Claims) HasRole(role string) bool {
+	for _, r := range c.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package jwtservice
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Options tunes token lifetimes and the issuer claim
+type Options struct {
+	Issuer          string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+}
+
+// DefaultOptions returns short-lived access tokens and week-long refresh tokens
+func DefaultOptions() Options {
+	return Options{
+		AccessTokenTTL:  15 * time.Minute,
+		RefreshTokenTTL: 7 * 24 * time.Hour,
+	}
+}
+
+// JWTService handles JWT token operations
+type JWTService struct {
+	secretKey string
+	opts      Options
+	now       func() time.Time
+}
+
+// NewJWTService creates a new JWT service
+func NewJWTService(secretKey string, opts Options) (*JWTService, error) {
+	if secretKey == "" {
+		return nil, NewValidationError("secretKey", "cannot be empty")
+	}
+	if opts.AccessTokenTTL <= 0 || opts.RefreshTokenTTL <= 0 {
+		return nil, NewValidationError("opts", "token lifetimes must be positive")
+	}
+
+	return &JWTService{
+		secretKey: secretKey,
+		opts:      opts,
+		now:       time.Now,
+	}, nil
+}
+
+// GenerateToken creates an access token for the user
+func (j *JWTService) GenerateToken(userID int, email string, roles ...string) (string, error) {
+	token, _, err := j.generate(AccessToken, userID, email, roles, j.opts.AccessTokenTTL)
+	return token, err
+}
+
+// GenerateRefreshToken creates a refresh token and returns its claims, whose ID
+// (jti) can be stored to revoke the token later
+func (j *JWTService) GenerateRefreshToken(userID int, email string, roles ...string) (string, *Claims, error) {
+	return j.generate(RefreshToken, userID, email, roles, j.opts.RefreshTokenTTL)
+}
+
+// ValidateToken validates an access token and returns its claims
+func (j *JWTService) ValidateToken(tokenString string) (*Claims, error) {
+	return j.validate(tokenString, AccessToken)
+}
+
+// ValidateRefreshToken validates a refresh token and returns its claims
+func (j *JWTService) ValidateRefreshToken(tokenString string) (*Claims, error) {
+	return j.validate(tokenString, RefreshToken)
+}
+
+// AccessTokenTTL returns the lifetime of access tokens
+func (j *JWTService) AccessTokenTTL() time.Duration {
+	return j.opts.AccessTokenTTL
+}
+
+func (j *JWTService) generate(tokenType string, userID int, email string, roles []string, ttl time.Duration) (string, *Claims, error) {
+	if userID <= 0 {
+		return "", nil, NewValidationError("userID", "must be positive")
+	}
+
+	if email == "" {
+		return "", nil, NewValidationError("email", "cannot be empty")
+	}
+
+	id, err := newTokenID()
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := j.now()
+	claims := &Claims{
+		UserID:    userID,
+		Email:     email,
+		Roles:     roles,
+		TokenType: tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        id,
+			Issuer:    j.opts.Issuer,
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	tokenString, err := token.SignedString([]byte(j.secretKey))
+	if err != nil {
+		return "", nil, err
+	}
+
+	return tokenString, claims, nil
+}
+
+func (j *JWTService) validate(tokenString, tokenType string) (*Claims, error) {
+	if tokenString == "" {
+		return nil, ErrEmptyToken
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, NewInvalidSigningMethodError(token.Header["alg"])
+		}
+		return []byte(j.secretKey), nil
+	})
+
+	if err != nil {
+		var ve *jwt.ValidationError
+		if errors.As(err, &ve) && ve.Errors&jwt.ValidationErrorExpired != 0 {
+			return nil, ErrTokenExpired
+		}
+		return nil, ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, ErrInvalidClaims
+	}
+
+	// A refresh token must never work as an access token and vice versa
+	if claims.TokenType != tokenType {
+		return nil, ErrInvalidClaims
+	}
+	if j.opts.Issuer != "" && claims.Issuer != j.opts.Issuer {
+		return nil, ErrInvalidClaims
+	}
+
+	return claims, nil
+}
+
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
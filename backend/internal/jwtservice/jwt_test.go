@@ -0,0 +1,150 @@
+package jwtservice
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestService(t *testing.T, secret string) *JWTService {
+	t.Helper()
+	opts := DefaultOptions()
+	opts.Issuer = "test"
+	service, err := NewJWTService(secret, opts)
+	if err != nil {
+		t.Fatalf("NewJWTService() error = %v", err)
+	}
+	return service
+}
+
+func TestNewJWTService(t *testing.T) {
+	tests := []struct {
+		name      string
+		secretKey string
+		opts      Options
+		wantErr   bool
+	}{
+		{"valid secret", "my-secret-key", DefaultOptions(), false},
+		{"empty secret", "", DefaultOptions(), true},
+		{"zero TTL", "my-secret-key", Options{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, err := NewJWTService(tt.secretKey, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewJWTService() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && service == nil {
+				t.Error("NewJWTService() should return non-nil service")
+			}
+		})
+	}
+}
+
+func TestJWTService_GenerateToken(t *testing.T) {
+	service := newTestService(t, "test-secret")
+
+	tests := []struct {
+		name    string
+		userID  int
+		email   string
+		wantErr bool
+	}{
+		{"valid user", 1, "test@example.com", false},
+		{"zero userID", 0, "test@example.com", true},
+		{"negative userID", -1, "test@example.com", true},
+		{"empty email", 1, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := service.GenerateToken(tt.userID, tt.email)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GenerateToken() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && token == "" {
+				t.Error("GenerateToken() should return non-empty token")
+			}
+		})
+	}
+}
+
+func TestJWTService_ValidateToken(t *testing.T) {
+	service := newTestService(t, "test-secret")
+
+	token, err := service.GenerateToken(123, "test@example.com", "admin")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	claims, err := service.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("ValidateToken() error = %v", err)
+	}
+	if claims.UserID != 123 || claims.Email != "test@example.com" {
+		t.Errorf("Unexpected claims %+v", claims)
+	}
+	if !claims.HasRole("admin") || claims.HasRole("user") {
+		t.Errorf("Unexpected roles %v", claims.Roles)
+	}
+
+	for _, bad := range []string{"", "invalid.token.here", "not-a-jwt-token"} {
+		if _, err := service.ValidateToken(bad); err == nil {
+			t.Errorf("ValidateToken(%q) should fail", bad)
+		}
+	}
+}
+
+func TestJWTService_TokenTypesAreNotInterchangeable(t *testing.T) {
+	service := newTestService(t, "test-secret")
+
+	access, _ := service.GenerateToken(1, "a@b.c")
+	refresh, refreshClaims, err := service.GenerateRefreshToken(1, "a@b.c")
+	if err != nil {
+		t.Fatalf("GenerateRefreshToken() error = %v", err)
+	}
+	if refreshClaims.ID == "" {
+		t.Error("Expected refresh token to carry a token ID")
+	}
+
+	if _, err := service.ValidateToken(refresh); !errors.Is(err, ErrInvalidClaims) {
+		t.Errorf("Expected refresh token to be rejected as access token, got %v", err)
+	}
+	if _, err := service.ValidateRefreshToken(access); !errors.Is(err, ErrInvalidClaims) {
+		t.Errorf("Expected access token to be rejected as refresh token, got %v", err)
+	}
+	if _, err := service.ValidateRefreshToken(refresh); err != nil {
+		t.Errorf("Expected refresh token to validate, got %v", err)
+	}
+}
+
+func TestJWTService_TokenExpiry(t *testing.T) {
+	service := newTestService(t, "test-secret")
+	service.now = func() time.Time { return time.Now().Add(-time.Hour) }
+
+	token, err := service.GenerateToken(1, "a@b.c")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	if _, err := service.ValidateToken(token); !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("Expected ErrTokenExpired, got %v", err)
+	}
+}
+
+func TestJWTService_DifferentSecretsAndIssuers(t *testing.T) {
+	service1 := newTestService(t, "secret1")
+	service2 := newTestService(t, "secret2")
+
+	token, _ := service1.GenerateToken(123, "test@example.com")
+	if _, err := service2.ValidateToken(token); err == nil {
+		t.Error("Token should not be valid with different secret")
+	}
+
+	other, _ := NewJWTService("secret1", Options{Issuer: "other", AccessTokenTTL: time.Minute, RefreshTokenTTL: time.Hour})
+	if _, err := other.ValidateToken(token); err == nil {
+		t.Error("Token should not be valid for a different issuer")
+	}
+}
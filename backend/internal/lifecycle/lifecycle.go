@@ -0,0 +1,285 @@
+// Package lifecycle starts and stops the server's subsystems in a fixed order.
+//
+// Subsystems register a Hook with a priority. Start runs hooks from the
+// lowest priority to the highest; shutdown first runs the OnShutdown
+// callbacks (e.g. marking readiness false), waits for the drain period so
+// load balancers notice, then stops the hooks in reverse order, each under
+// its own timeout. A hook that does not return in time is reported as hung
+// and shutdown moves on to the next one.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Suggested priorities; lower values start earlier and stop later
+const (
+	PriorityStorage  = 0   // database pools, caches
+	PriorityServices = 100 // background workers, hubs
+	PriorityServers  = 200 // listeners accepting traffic
+)
+
+// Hook is a subsystem's start and stop functions. Either may be nil.
+type Hook struct {
+	Name     string
+	Priority int
+	Start    func(ctx context.Context) error
+	Stop     func(ctx context.Context) error
+	// Timeout bounds Stop; zero uses Options.StopTimeout
+	Timeout time.Duration
+}
+
+// Options configures a Manager
+type Options struct {
+	// DrainPeriod is how long to keep serving after readiness turns false
+	DrainPeriod time.Duration
+	// StopTimeout is the default per-hook stop timeout
+	StopTimeout time.Duration
+	Logger      *slog.Logger
+}
+
+// HookResult describes how one hook stopped
+type HookResult struct {
+	Name     string
+	Duration time.Duration
+	Err      error
+	// Hung is set when the hook did not return within its timeout
+	Hung bool
+}
+
+// Report summarizes a shutdown
+type Report struct {
+	Results []HookResult
+}
+
+// Hung returns the names of hooks that did not stop in time
+func (r Report) Hung() []string {
+	var names []string
+	for _, res := range r.Results {
+		if res.Hung {
+			names = append(names, res.Name)
+		}
+	}
+	return names
+}
+
+// Err joins the errors of every hook that failed or hung
+func (r Report) Err() error {
+	var errs []error
+	for _, res := range r.Results {
+		if res.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", res.Name, res.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ErrHookTimeout is the error recorded for a hung hook
+var ErrHookTimeout = errors.New("lifecycle: stop hook timed out")
+
+// Manager owns the hooks and runs them in priority order
+type Manager struct {
+	opts Options
+
+	mu         sync.Mutex
+	hooks      []Hook
+	started    []Hook
+	onShutdown []func()
+
+	// sleep and exit are replaced in tests
+	sleep func(context.Context, time.Duration)
+	exit  func(code int)
+}
+
+// New creates a Manager
+func New(opts Options) *Manager {
+	if opts.StopTimeout <= 0 {
+		opts.StopTimeout = 10 * time.Second
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	return &Manager{opts: opts, sleep: sleepCtx, exit: os.Exit}
+}
+
+// Append registers a hook. Hooks with equal priority keep registration order.
+func (m *Manager) Append(h Hook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, h)
+}
+
+// OnShutdown registers fn to run as soon as shutdown begins, before the drain period
+func (m *Manager) OnShutdown(fn func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onShutdown = append(m.onShutdown, fn)
+}
+
+// Start runs the start hooks in priority order. If one fails, the hooks
+// started so far are stopped again and the error is returned.
+func (m *Manager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	hooks := append([]Hook(nil), m.hooks...)
+	m.mu.Unlock()
+	sort.SliceStable(hooks, func(i, j int) bool { return hooks[i].Priority < hooks[j].Priority })
+
+	for _, h := range hooks {
+		if h.Start != nil {
+			if err := h.Start(ctx); err != nil {
+				m.opts.Logger.Error("start hook failed", slog.String("hook", h.Name), slog.Any("error", err))
+				m.stopHooks(context.Background())
+				return fmt.Errorf("lifecycle: start %s: %w", h.Name, err)
+			}
+		}
+		m.mu.Lock()
+		m.started = append(m.started, h)
+		m.mu.Unlock()
+	}
+	return nil
+}
+
+// Shutdown runs the OnShutdown callbacks, waits for the drain period and
+// stops the started hooks in reverse order. ctx cuts the drain period short.
+func (m *Manager) Shutdown(ctx context.Context) Report {
+	m.mu.Lock()
+	callbacks := append([]func(){}, m.onShutdown...)
+	m.mu.Unlock()
+	for _, fn := range callbacks {
+		fn()
+	}
+
+	if m.opts.DrainPeriod > 0 {
+		m.opts.Logger.Info("draining before shutdown", slog.Duration("period", m.opts.DrainPeriod))
+		m.sleep(ctx, m.opts.DrainPeriod)
+	}
+
+	return m.stopHooks(ctx)
+}
+
+// Run starts the hooks, waits for SIGINT or SIGTERM (or ctx to end) and
+// shuts down. A second signal during shutdown exits the process with status 1.
+func (m *Manager) Run(ctx context.Context) error {
+	if err := m.Start(ctx); err != nil {
+		return err
+	}
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	return m.wait(ctx, signals)
+}
+
+// wait blocks until the first signal, then shuts down while watching for a second
+func (m *Manager) wait(ctx context.Context, signals <-chan os.Signal) error {
+	select {
+	case sig := <-signals:
+		m.opts.Logger.Info("shutdown requested", slog.String("signal", sig.String()))
+	case <-ctx.Done():
+		m.opts.Logger.Info("shutdown requested", slog.String("reason", ctx.Err().Error()))
+	}
+
+	done := make(chan Report, 1)
+	go func() { done <- m.Shutdown(context.Background()) }()
+
+	select {
+	case report := <-done:
+		if hung := report.Hung(); len(hung) > 0 {
+			m.opts.Logger.Error("shutdown finished with hung hooks", slog.String("hooks", strings.Join(hung, ", ")))
+		}
+		return report.Err()
+	case sig := <-signals:
+		m.opts.Logger.Error("second signal received, forcing exit", slog.String("signal", sig.String()))
+		m.exit(1)
+		return errors.New("lifecycle: forced exit")
+	}
+}
+
+// stopHooks stops every started hook in reverse start order
+func (m *Manager) stopHooks(ctx context.Context) Report {
+	m.mu.Lock()
+	started := m.started
+	m.started = nil
+	m.mu.Unlock()
+
+	var report Report
+	for i := len(started) - 1; i >= 0; i-- {
+		h := started[i]
+		if h.Stop == nil {
+			continue
+		}
+		res := m.stopOne(ctx, h)
+		report.Results = append(report.Results, res)
+
+		attrs := []any{slog.String("hook", h.Name), slog.Duration("duration", res.Duration)}
+		switch {
+		case res.Hung:
+			m.opts.Logger.Error("stop hook hung", attrs...)
+		case res.Err != nil:
+			m.opts.Logger.Error("stop hook failed", append(attrs, slog.Any("error", res.Err))...)
+		default:
+			m.opts.Logger.Info("stopped", attrs...)
+		}
+	}
+	return report
+}
+
+// stopOne runs h.Stop under its timeout. A hook that ignores its context is
+// abandoned, not waited for.
+func (m *Manager) stopOne(ctx context.Context, h Hook) HookResult {
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = m.opts.StopTimeout
+	}
+	// Stopping must proceed even when the caller's context is already done
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- h.Stop(ctx)
+	}()
+
+	res := HookResult{Name: h.Name}
+	select {
+	case res.Err = <-done:
+	case <-ctx.Done():
+		// Give a hook that honours ctx a moment to return its own error
+		select {
+		case res.Err = <-done:
+		case <-time.After(50 * time.Millisecond):
+		}
+		if res.Err == nil || errors.Is(res.Err, context.DeadlineExceeded) {
+			res.Hung = true
+			res.Err = ErrHookTimeout
+		}
+	}
+	res.Duration = time.Since(start)
+	return res
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,193 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"reflect"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// recorder collects events from hooks in order
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) add(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+func (r *recorder) hook(name string, priority int) Hook {
+	return Hook{
+		Name:     name,
+		Priority: priority,
+		Start:    func(context.Context) error { r.add("start " + name); return nil },
+		Stop:     func(context.Context) error { r.add("stop " + name); return nil },
+	}
+}
+
+func newTestManager(opts Options) *Manager {
+	opts.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	return New(opts)
+}
+
+func TestStartAndShutdownOrder(t *testing.T) {
+	rec := &recorder{}
+	m := newTestManager(Options{DrainPeriod: time.Hour})
+	m.sleep = func(_ context.Context, d time.Duration) { rec.add("drain " + d.String()) }
+
+	m.Append(rec.hook("http", PriorityServers))
+	m.Append(rec.hook("db", PriorityStorage))
+	m.Append(rec.hook("worker", PriorityServices))
+	m.Append(rec.hook("hub", PriorityServices))
+	m.OnShutdown(func() { rec.add("not ready") })
+
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	report := m.Shutdown(context.Background())
+	if err := report.Err(); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	want := []string{
+		"start db", "start worker", "start hub", "start http",
+		"not ready", "drain 1h0m0s",
+		"stop http", "stop hub", "stop worker", "stop db",
+	}
+	if got := rec.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected events %v, got %v", want, got)
+	}
+}
+
+func TestStartFailureStopsStartedHooks(t *testing.T) {
+	rec := &recorder{}
+	m := newTestManager(Options{})
+	m.Append(rec.hook("db", PriorityStorage))
+	m.Append(Hook{
+		Name:     "broken",
+		Priority: PriorityServices,
+		Start:    func(context.Context) error { return errors.New("boom") },
+		Stop:     func(context.Context) error { rec.add("stop broken"); return nil },
+	})
+	m.Append(rec.hook("http", PriorityServers))
+
+	if err := m.Start(context.Background()); err == nil {
+		t.Fatal("Expected Start to fail")
+	}
+	want := []string{"start db", "stop db"}
+	if got := rec.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected events %v, got %v", want, got)
+	}
+}
+
+func TestHungHookIsReportedAndSkipped(t *testing.T) {
+	rec := &recorder{}
+	m := newTestManager(Options{StopTimeout: time.Second})
+	m.Append(rec.hook("db", PriorityStorage))
+	release := make(chan struct{})
+	defer close(release)
+	m.Append(Hook{
+		Name:     "stuck",
+		Priority: PriorityServices,
+		Timeout:  20 * time.Millisecond,
+		Stop: func(context.Context) error {
+			<-release // ignores its context
+			return nil
+		},
+	})
+	m.Append(Hook{
+		Name:     "failing",
+		Priority: PriorityServers,
+		Stop:     func(context.Context) error { return errors.New("close failed") },
+	})
+
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	report := m.Shutdown(context.Background())
+
+	if hung := report.Hung(); !reflect.DeepEqual(hung, []string{"stuck"}) {
+		t.Errorf("Expected [stuck] to be reported hung, got %v", hung)
+	}
+	if err := report.Err(); !errors.Is(err, ErrHookTimeout) {
+		t.Errorf("Expected report error to include ErrHookTimeout, got %v", err)
+	}
+	if got := rec.get(); got[len(got)-1] != "stop db" {
+		t.Errorf("Expected shutdown to continue past the hung hook, got %v", got)
+	}
+}
+
+func TestHookHonouringContextIsNotHung(t *testing.T) {
+	m := newTestManager(Options{StopTimeout: 20 * time.Millisecond})
+	m.Append(Hook{
+		Name: "server",
+		Stop: func(ctx context.Context) error {
+			<-ctx.Done()
+			return errors.New("connections still open")
+		},
+	})
+	m.Start(context.Background())
+
+	report := m.Shutdown(context.Background())
+	if len(report.Hung()) != 0 {
+		t.Errorf("Expected no hung hooks, got %v", report.Hung())
+	}
+	if report.Err() == nil {
+		t.Error("Expected the hook's own error to be reported")
+	}
+}
+
+func TestSecondSignalForcesExit(t *testing.T) {
+	m := newTestManager(Options{DrainPeriod: time.Hour})
+	exited := make(chan int, 1)
+	m.exit = func(code int) { exited <- code }
+	m.Append(Hook{Name: "noop", Stop: func(context.Context) error { return nil }})
+	m.Start(context.Background())
+
+	signals := make(chan os.Signal, 2)
+	signals <- syscall.SIGTERM
+	signals <- syscall.SIGINT
+	if err := m.wait(context.Background(), signals); err == nil {
+		t.Error("Expected forced exit to return an error")
+	}
+
+	select {
+	case code := <-exited:
+		if code != 1 {
+			t.Errorf("Expected exit code 1, got %d", code)
+		}
+	default:
+		t.Error("Expected exit to be called")
+	}
+}
+
+func TestContextCancelTriggersShutdown(t *testing.T) {
+	rec := &recorder{}
+	m := newTestManager(Options{})
+	m.Append(rec.hook("db", PriorityStorage))
+	m.Start(context.Background())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := m.wait(ctx, make(chan os.Signal)); err != nil {
+		t.Fatalf("wait() error = %v", err)
+	}
+	if got := rec.get(); got[len(got)-1] != "stop db" {
+		t.Errorf("Expected db to be stopped, got %v", got)
+	}
+}
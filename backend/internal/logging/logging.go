@@ -0,0 +1,76 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/config"
+)
+
+// Redacted replaces the value of sensitive attributes
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys never reach the output, whatever the caller passes in
+var sensitiveKeys = []string{"authorization", "password", "secret", "token", "cookie", "api_key", "apikey"}
+
+type ctxKey int
+
+const (
+	loggerKey ctxKey = iota
+	requestIDKey
+)
+
+// New creates a logger writing to w with the level and format from cfg
+func New(cfg config.LogConfig, w io.Writer) (*slog.Logger, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		return nil, fmt.Errorf("logging: invalid level %q", cfg.Level)
+	}
+
+	opts := &slog.HandlerOptions{Level: level, ReplaceAttr: redact}
+	switch strings.ToLower(cfg.Format) {
+	case "json", "":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	case "text":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("logging: invalid format %q", cfg.Format)
+	}
+}
+
+// NewContext returns a copy of ctx carrying logger
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, logger)
+}
+
+// FromContext returns the request-scoped logger, or slog.Default when there is none
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// WithRequestID returns a copy of ctx carrying the request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID returns the request ID stored in ctx, or ""
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+func redact(groups []string, a slog.Attr) slog.Attr {
+	key := strings.ToLower(a.Key)
+	for _, s := range sensitiveKeys {
+		if strings.Contains(key, s) {
+			return slog.String(a.Key, Redacted)
+		}
+	}
+	return a
+}
@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/config"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.LogConfig
+		wantErr bool
+	}{
+		{"json", config.LogConfig{Level: "info", Format: "json"}, false},
+		{"text", config.LogConfig{Level: "debug", Format: "text"}, false},
+		{"bad level", config.LogConfig{Level: "loud", Format: "json"}, true},
+		{"bad format", config.LogConfig{Level: "info", Format: "xml"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.cfg, &bytes.Buffer{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRedactsSensitiveAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(config.LogConfig{Level: "info", Format: "json"}, &buf)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	logger.Info("login", "email", "a@b.c", "password", "hunter2", "Authorization", "Bearer x", "refresh_token", "r")
+
+	out := buf.String()
+	for _, leaked := range []string{"hunter2", "Bearer x", `"r"`} {
+		if strings.Contains(out, leaked) {
+			t.Errorf("Expected %q to be redacted: %s", leaked, out)
+		}
+	}
+	if !strings.Contains(out, "a@b.c") {
+		t.Errorf("Expected non-sensitive attributes to be kept: %s", out)
+	}
+}
+
+func TestContextHelpers(t *testing.T) {
+	ctx := context.Background()
+	if FromContext(ctx) == nil {
+		t.Error("Expected default logger without a context logger")
+	}
+	if RequestID(ctx) != "" {
+		t.Error("Expected empty request ID")
+	}
+
+	ctx = WithRequestID(ctx, "abc")
+	if RequestID(ctx) != "abc" {
+		t.Errorf("Expected request ID 'abc', got %q", RequestID(ctx))
+	}
+}
@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"database/sql"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(w.Body)
+	return string(body)
+}
+
+func TestMiddlewareRecordsRouteTemplates(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := New()
+
+	router := gin.New()
+	router.Use(m.Middleware())
+	router.GET("/users/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, path := range []string{"/users/1", "/users/2", "/missing"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	out := scrape(t, m)
+	for _, want := range []string{
+		`http_requests_total{method="GET",route="/users/:id",status="200"} 2`,
+		`http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`http_request_duration_seconds_count{method="GET",route="/users/:id"} 2`,
+		`http_requests_in_flight 0`,
+		`go_goroutines`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected scrape output to contain %q", want)
+		}
+	}
+	if strings.Contains(out, `route="/users/1"`) {
+		t.Error("Expected raw paths not to be used as labels")
+	}
+}
+
+func TestCustomCollectorsAndDBStats(t *testing.T) {
+	m := New()
+
+	sent := m.NewCounter("chat_messages_sent_total", "Messages sent.", "room")
+	sent.WithLabelValues("general").Add(3)
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+	m.RegisterDB("main", db)
+
+	out := scrape(t, m)
+	if !strings.Contains(out, `chat_messages_sent_total{room="general"} 3`) {
+		t.Error("Expected custom counter in scrape output")
+	}
+	if !strings.Contains(out, `go_sql_open_connections{db_name="main"}`) {
+		t.Error("Expected DB pool stats in scrape output")
+	}
+}
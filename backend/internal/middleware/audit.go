@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/audit"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/logging"
+)
+
+// Gin context keys for what handlers add to the audit entry
+const (
+	auditResourceKey = "auditResource"
+	auditChangesKey  = "auditChanges"
+)
+
+// AuditRecorder accepts finished audit entries; *audit.Recorder implements it
+type AuditRecorder interface {
+	Record(e *audit.Entry) bool
+}
+
+// Audit records every POST, PUT, PATCH and DELETE on a known route once the
+// handler has finished: the actor, route, resource, status, client and
+// request ID, plus the field changes a handler reports with AuditChange.
+// The resource ID defaults to the :id path parameter. Client-supplied text
+// is truncated to the audit_log column widths.
+func Audit(rec AuditRecorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !unsafeMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+		occurred := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			return
+		}
+		entry := &audit.Entry{
+			OccurredAt: occurred,
+			Method:     c.Request.Method,
+			Route:      route,
+			Path:       c.Request.URL.Path,
+			ResourceID: c.Param("id"),
+			Status:     c.Writer.Status(),
+			Outcome:    audit.OutcomeOf(c.Writer.Status()),
+			IP:         c.ClientIP(),
+			UserAgent:  c.Request.UserAgent(),
+			RequestID:  logging.RequestID(c.Request.Context()),
+		}
+		if id, ok := GetUserID(c); ok {
+			entry.ActorID = &id
+		}
+		if id := c.GetString(auditResourceKey); id != "" {
+			entry.ResourceID = id
+		}
+		if changes, ok := c.Get(auditChangesKey); ok {
+			entry.Changes = changes.(map[string]audit.Change)
+		}
+		entry.Truncate()
+		rec.Record(entry)
+	}
+}
+
+// AuditResource sets the ID of the resource the request acted on, for
+// routes without an :id parameter such as creation
+func AuditResource(c *gin.Context, id string) {
+	c.Set(auditResourceKey, id)
+}
+
+// AuditChange records the resource before and after the change; either may
+// be nil for creation or deletion. Only differing JSON fields are kept.
+func AuditChange(c *gin.Context, before, after any) {
+	changes, err := audit.Diff(before, after)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Warn("audit diff failed", slog.Any("error", err))
+		return
+	}
+	c.Set(auditChangesKey, changes)
+}
@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/audit"
+)
+
+type captureRecorder struct {
+	entries []*audit.Entry
+}
+
+func (r *captureRecorder) Record(e *audit.Entry) bool {
+	r.entries = append(r.entries, e)
+	return true
+}
+
+func TestAudit(t *testing.T) {
+	rec := &captureRecorder{}
+	router := gin.New()
+	router.Use(RequestID(), func(c *gin.Context) {
+		if c.GetHeader("X-Test-User") != "" {
+			c.Set(userIDKey, 7)
+		}
+	}, Audit(rec))
+
+	router.PATCH("/records/:id", func(c *gin.Context) {
+		AuditChange(c, gin.H{"weight": 70, "height": 180}, gin.H{"weight": 72, "height": 180})
+		c.Status(http.StatusOK)
+	})
+	router.POST("/records", func(c *gin.Context) {
+		AuditResource(c, "99")
+		c.Status(http.StatusCreated)
+	})
+	router.DELETE("/records/:id", func(c *gin.Context) { c.Status(http.StatusForbidden) })
+	router.GET("/records/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		name         string
+		method, path string
+		user         bool
+		wantRecord   bool
+		wantResource string
+		wantOutcome  audit.Outcome
+	}{
+		{"update with diff", http.MethodPatch, "/records/5", true, true, "5", audit.OutcomeSuccess},
+		{"create sets resource", http.MethodPost, "/records", true, true, "99", audit.OutcomeSuccess},
+		{"anonymous failure", http.MethodDelete, "/records/6", false, true, "6", audit.OutcomeFailure},
+		{"reads are not audited", http.MethodGet, "/records/5", true, false, "", ""},
+		{"unknown route", http.MethodPost, "/nowhere", true, false, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec.entries = nil
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("User-Agent", "audit-test")
+			if tt.user {
+				req.Header.Set("X-Test-User", "1")
+			}
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			if !tt.wantRecord {
+				if len(rec.entries) != 0 {
+					t.Errorf("Expected no audit entry, got %+v", rec.entries[0])
+				}
+				return
+			}
+			if len(rec.entries) != 1 {
+				t.Fatalf("Expected one audit entry, got %d", len(rec.entries))
+			}
+			e := rec.entries[0]
+			if e.ResourceID != tt.wantResource || e.Outcome != tt.wantOutcome || e.Method != tt.method {
+				t.Errorf("Unexpected entry %+v", e)
+			}
+			if (e.ActorID != nil) != tt.user || (tt.user && *e.ActorID != 7) {
+				t.Errorf("Expected actor when authenticated, got %v", e.ActorID)
+			}
+			if e.RequestID == "" || e.UserAgent != "audit-test" || e.IP == "" || e.Route == "" {
+				t.Errorf("Expected request metadata, got %+v", e)
+			}
+		})
+	}
+
+	rec.entries = nil
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPatch, "/records/5", nil))
+	changes := rec.entries[0].Changes
+	if len(changes) != 1 || string(changes["weight"].Before) != "70" || string(changes["weight"].After) != "72" {
+		t.Errorf("Expected only the weight change, got %v", changes)
+	}
+}
+
+func TestAuditTruncatesOversizedFields(t *testing.T) {
+	rec := &captureRecorder{}
+	router := gin.New()
+	router.Use(Audit(rec))
+	router.POST("/records/:id", func(c *gin.Context) { c.Status(http.StatusCreated) })
+
+	id := strings.Repeat("x", 300)
+	req := httptest.NewRequest(http.MethodPost, "/records/"+id, nil)
+	req.Header.Set("User-Agent", strings.Repeat("ü", 5000))
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(rec.entries) != 1 {
+		t.Fatalf("Expected one audit entry, got %d", len(rec.entries))
+	}
+	e := rec.entries[0]
+	if ua := []rune(e.UserAgent); len(ua) != 512 || ua[0] != 'ü' {
+		t.Errorf("Expected the User-Agent cut to 512 characters, got %d", len(ua))
+	}
+	if len(e.ResourceID) != 255 {
+		t.Errorf("Expected the resource ID cut to 255 characters, got %d", len(e.ResourceID))
+	}
+}
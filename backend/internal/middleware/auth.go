@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/apierror"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/jwtservice"
+)
+
+// Gin context keys set by the auth middleware, next to userIDKey
+const (
+	userEmailKey = "userEmail"
+	userRolesKey = "userRoles"
+	claimsKey    = "claims"
+)
+
+// TokenValidator is satisfied by *jwtservice.JWTService
+type TokenValidator interface {
+	ValidateToken(token string) (*jwtservice.Claims, error)
+}
+
+// RequireAuth rejects requests without a valid bearer access token with 401
+func RequireAuth(v TokenValidator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, err := authenticate(c, v)
+		if err != nil {
+			unauthorized(c, err)
+			return
+		}
+		setClaims(c, claims)
+		c.Next()
+	}
+}
+
+// OptionalAuth sets the user when a valid token is present and lets every
+// other request through as anonymous, including ones with an expired or
+// invalid token: a client still sending its stale access token must be
+// able to reach /auth/refresh and /auth/login. RequireAuth on protected
+// routes is what rejects such tokens with 401.
+func OptionalAuth(v TokenValidator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if claims, err := authenticate(c, v); err == nil {
+			setClaims(c, claims)
+		}
+		c.Next()
+	}
+}
+
+// RequireRole allows the request when the user has any of roles. It must
+// run after RequireAuth: anonymous requests get 401, other users 403.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := GetUserID(c); !ok {
+			unauthorized(c, errNoToken)
+			return
+		}
+		for _, role := range roles {
+			if HasRole(c, role) {
+				c.Next()
+				return
+			}
+		}
+		apierror.Abort(c, apierror.Forbidden("insufficient role"))
+	}
+}
+
+// GetUserID returns the authenticated user's ID
+func GetUserID(c *gin.Context) (int, bool) {
+	id, ok := c.Get(userIDKey)
+	if !ok {
+		return 0, false
+	}
+	userID, ok := id.(int)
+	return userID, ok
+}
+
+// GetUserEmail returns the authenticated user's email, or ""
+func GetUserEmail(c *gin.Context) string {
+	return c.GetString(userEmailKey)
+}
+
+// GetUserRoles returns the authenticated user's roles
+func GetUserRoles(c *gin.Context) []string {
+	return c.GetStringSlice(userRolesKey)
+}
+
+// GetClaims returns the validated token claims
+func GetClaims(c *gin.Context) (*jwtservice.Claims, bool) {
+	v, ok := c.Get(claimsKey)
+	if !ok {
+		return nil, false
+	}
+	claims, ok := v.(*jwtservice.Claims)
+	return claims, ok
+}
+
+// HasRole reports whether the authenticated user has role
+func HasRole(c *gin.Context, role string) bool {
+	for _, r := range GetUserRoles(c) {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+var errNoToken = errors.New("missing bearer token")
+
+func authenticate(c *gin.Context, v TokenValidator) (*jwtservice.Claims, error) {
+	header := c.GetHeader("Authorization")
+	if header == "" {
+		return nil, errNoToken
+	}
+
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return nil, jwtservice.ErrInvalidToken
+	}
+	return v.ValidateToken(strings.TrimSpace(token))
+}
+
+func setClaims(c *gin.Context, claims *jwtservice.Claims) {
+	c.Set(userIDKey, claims.UserID)
+	c.Set(userEmailKey, claims.Email)
+	c.Set(userRolesKey, claims.Roles)
+	c.Set(claimsKey, claims)
+}
+
+func unauthorized(c *gin.Context, err error) {
+	code, message := apierror.CodeUnauthorized, "authentication required"
+	switch {
+	case errors.Is(err, jwtservice.ErrTokenExpired):
+		code, message = "token_expired", "token expired"
+	case !errors.Is(err, errNoToken):
+		code, message = "invalid_token", "invalid token"
+	}
+
+	if code == apierror.CodeUnauthorized {
+		c.Header("WWW-Authenticate", `Bearer`)
+	} else {
+		c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+	}
+	apierror.Abort(c, apierror.Unauthorized(message).WithCode(code))
+}
@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/apierror"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/jwtservice"
+)
+
+func newAuthRouter(t *testing.T) (*gin.Engine, *jwtservice.JWTService) {
+	t.Helper()
+	jwt, err := jwtservice.NewJWTService("test-secret", jwtservice.DefaultOptions())
+	if err != nil {
+		t.Fatalf("NewJWTService() error = %v", err)
+	}
+
+	router := gin.New()
+	router.GET("/me", RequireAuth(jwt), func(c *gin.Context) {
+		id, _ := GetUserID(c)
+		c.JSON(http.StatusOK, gin.H{"id": id, "email": GetUserEmail(c)})
+	})
+	router.GET("/admin", RequireAuth(jwt), RequireRole("admin"), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	router.GET("/feed", OptionalAuth(jwt), func(c *gin.Context) {
+		_, ok := GetUserID(c)
+		c.JSON(http.StatusOK, gin.H{"authenticated": ok})
+	})
+	return router, jwt
+}
+
+func authGet(router http.Handler, path, authorization string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRequireAuth(t *testing.T) {
+	router, jwt := newAuthRouter(t)
+	token, _ := jwt.GenerateToken(7, "user@example.com")
+	refresh, _, _ := jwt.GenerateRefreshToken(7, "user@example.com")
+
+	tests := []struct {
+		name          string
+		authorization string
+		status        int
+		code          string
+	}{
+		{"valid token", "Bearer " + token, http.StatusOK, ""},
+		{"lowercase scheme", "bearer " + token, http.StatusOK, ""},
+		{"missing header", "", http.StatusUnauthorized, "unauthorized"},
+		{"wrong scheme", "Basic dXNlcjpwYXNz", http.StatusUnauthorized, "invalid_token"},
+		{"garbage token", "Bearer nope", http.StatusUnauthorized, "invalid_token"},
+		{"refresh token", "Bearer " + refresh, http.StatusUnauthorized, "invalid_token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := authGet(router, "/me", tt.authorization)
+			if w.Code != tt.status {
+				t.Fatalf("Expected %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+			if tt.code == "" {
+				return
+			}
+			var body apierror.Problem
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Code != tt.code {
+				t.Errorf("Expected error code %q, got %s", tt.code, w.Body.String())
+			}
+			if w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("Expected WWW-Authenticate header on 401")
+			}
+		})
+	}
+}
+
+func TestRequireAuthExpiredToken(t *testing.T) {
+	jwt, _ := jwtservice.NewJWTService("test-secret", jwtservice.Options{
+		AccessTokenTTL: time.Nanosecond, RefreshTokenTTL: time.Hour,
+	})
+	router := gin.New()
+	router.GET("/me", RequireAuth(jwt), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	token, _ := jwt.GenerateToken(1, "a@b.c")
+	time.Sleep(time.Second)
+
+	w := authGet(router, "/me", "Bearer "+token)
+	var body apierror.Problem
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if w.Code != http.StatusUnauthorized || body.Code != "token_expired" {
+		t.Errorf("Expected 401 token_expired, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	router, jwt := newAuthRouter(t)
+	user, _ := jwt.GenerateToken(1, "user@example.com", "user")
+	admin, _ := jwt.GenerateToken(2, "admin@example.com", "user", "admin")
+
+	if w := authGet(router, "/admin", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for anonymous, got %d", w.Code)
+	}
+	if w := authGet(router, "/admin", "Bearer "+user); w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for plain user, got %d", w.Code)
+	}
+	if w := authGet(router, "/admin", "Bearer "+admin); w.Code != http.StatusNoContent {
+		t.Errorf("Expected 204 for admin, got %d", w.Code)
+	}
+}
+
+func TestOptionalAuth(t *testing.T) {
+	router, jwt := newAuthRouter(t)
+	token, _ := jwt.GenerateToken(1, "a@b.c")
+
+	if w := authGet(router, "/feed", ""); w.Code != http.StatusOK || w.Body.String() != `{"authenticated":false}` {
+		t.Errorf("Expected anonymous access, got %d %s", w.Code, w.Body.String())
+	}
+	if w := authGet(router, "/feed", "Bearer "+token); w.Body.String() != `{"authenticated":true}` {
+		t.Errorf("Expected authenticated access, got %s", w.Body.String())
+	}
+	if w := authGet(router, "/feed", "Bearer broken"); w.Code != http.StatusOK || w.Body.String() != `{"authenticated":false}` {
+		t.Errorf("Expected invalid token to be treated as anonymous, got %d %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/apierror"
+)
+
+// MaxBodySize rejects request bodies larger than limit bytes with 413. A
+// declared Content-Length over the limit is refused before the handler
+// runs; otherwise reading past the limit fails with *http.MaxBytesError,
+// which the bind helpers and apierror turn into the same 413. Limits nest,
+// so a route can tighten its group's limit but not raise it.
+func MaxBodySize(limit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > limit {
+			apierror.Abort(c, &http.MaxBytesError{Limit: limit})
+			return
+		}
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		}
+		c.Next()
+	}
+}
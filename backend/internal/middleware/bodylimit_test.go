@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/apierror"
+)
+
+func TestMaxBodySize(t *testing.T) {
+	router := gin.New()
+	router.Use(apierror.Middleware(), MaxBodySize(16))
+	echo := func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			apierror.Abort(c, err)
+			return
+		}
+		c.String(http.StatusOK, "%d", len(body))
+	}
+	router.POST("/group", echo)
+	router.POST("/tight", MaxBodySize(4), echo)
+	router.POST("/loose", MaxBodySize(64), echo)
+
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		chunked    bool
+		wantStatus int
+	}{
+		{"within limit", "/group", "0123456789", false, http.StatusOK},
+		{"declared too large", "/group", strings.Repeat("x", 17), false, http.StatusRequestEntityTooLarge},
+		{"streamed too large", "/group", strings.Repeat("x", 17), true, http.StatusRequestEntityTooLarge},
+		{"route tightens", "/tight", "01234", false, http.StatusRequestEntityTooLarge},
+		{"route cannot raise", "/loose", strings.Repeat("x", 32), true, http.StatusRequestEntityTooLarge},
+		{"empty body", "/tight", "", false, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantStatus == http.StatusRequestEntityTooLarge && !strings.Contains(w.Body.String(), "body_too_large") {
+				t.Errorf("Expected a body_too_large problem, got %s", w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+// CompressOptions configures the Compress middleware
+type CompressOptions struct {
+	// MinSize is the smallest body worth compressing, in bytes
+	MinSize int
+	// ContentTypes lists compressible media types; entries ending in "/*"
+	// match a whole family. text/event-stream is never compressed.
+	ContentTypes []string
+	// Brotli offers br to clients that accept it, preferred over gzip
+	Brotli bool
+	// GzipLevel is a compress/gzip level; zero means gzip.DefaultCompression
+	GzipLevel int
+}
+
+// DefaultCompressOptions compresses text and JSON bodies of 1 KiB or more with gzip
+func DefaultCompressOptions() CompressOptions {
+	return CompressOptions{
+		MinSize: 1024,
+		ContentTypes: []string{
+			"application/json", "application/problem+json", "application/javascript",
+			"application/xml", "image/svg+xml", "text/*",
+		},
+	}
+}
+
+// Compress negotiates gzip or brotli from Accept-Encoding. Bodies are held
+// back until MinSize bytes have been written, so small responses go out
+// untouched. Strong ETags are weakened on compressed responses because the
+// bytes no longer match the uncompressed representation. Websocket
+// upgrades, event streams and range requests pass through unchanged.
+func Compress(opts CompressOptions) gin.HandlerFunc {
+	if opts.GzipLevel == 0 {
+		opts.GzipLevel = gzip.DefaultCompression
+	}
+	gzipPool := &sync.Pool{New: func() any {
+		w, _ := gzip.NewWriterLevel(io.Discard, opts.GzipLevel)
+		return w
+	}}
+
+	return func(c *gin.Context) {
+		if isStreamingRequest(c.Request) || c.GetHeader("Range") != "" {
+			c.Next()
+			return
+		}
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"), opts.Brotli)
+		if encoding == "" {
+			c.Next()
+			return
+		}
+
+		w := &compressWriter{ResponseWriter: c.Writer, opts: &opts, encoding: encoding, gzipPool: gzipPool}
+		c.Writer = w
+		defer func() {
+			w.finish()
+			c.Writer = w.ResponseWriter
+		}()
+		c.Next()
+	}
+}
+
+// isStreamingRequest spots websocket upgrades and server-sent event subscriptions
+func isStreamingRequest(r *http.Request) bool {
+	return r.Header.Get("Upgrade") != "" ||
+		strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+// negotiateEncoding picks br, gzip or "" (identity) from an Accept-Encoding header
+func negotiateEncoding(header string, allowBrotli bool) string {
+	quality := map[string]float64{}
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		quality[name] = q
+	}
+
+	accepts := func(enc string) bool {
+		if q, ok := quality[enc]; ok {
+			return q > 0
+		}
+		q, ok := quality["*"]
+		return ok && q > 0
+	}
+	if allowBrotli && accepts("br") {
+		return "br"
+	}
+	if accepts("gzip") {
+		return "gzip"
+	}
+	return ""
+}
+
+// compressWriter buffers the start of the body to decide whether to compress
+type compressWriter struct {
+	gin.ResponseWriter
+	opts     *CompressOptions
+	encoding string
+	gzipPool *sync.Pool
+
+	buf     []byte
+	decided bool
+	enc     io.WriteCloser
+	flusher interface{ Flush() error }
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if !w.decided {
+		w.buf = append(w.buf, b...)
+		if len(w.buf) < w.opts.MinSize {
+			return len(b), nil
+		}
+		if err := w.decide(); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	if w.enc != nil {
+		return w.enc.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Written reports buffered bytes as written so error middleware leaves them alone
+func (w *compressWriter) Written() bool {
+	return len(w.buf) > 0 || w.ResponseWriter.Written()
+}
+
+func (w *compressWriter) WriteHeaderNow() {
+	if !w.decided {
+		w.decide()
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// Flush sends what is buffered; a handler that flushes is streaming, so
+// the decision is made with whatever has been written so far
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide()
+	}
+	if w.flusher != nil {
+		w.flusher.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// decide picks compressed or identity output and writes out the buffer
+func (w *compressWriter) decide() error {
+	w.decided = true
+	header := w.Header()
+
+	if w.compressible() {
+		header.Add("Vary", "Accept-Encoding")
+		if len(w.buf) >= w.opts.MinSize {
+			header.Set("Content-Encoding", w.encoding)
+			header.Del("Content-Length")
+			if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+				header.Set("ETag", "W/"+etag)
+			}
+			w.startEncoder()
+		}
+	}
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if w.enc != nil {
+		_, err = w.enc.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+func (w *compressWriter) compressible() bool {
+	status := w.Status()
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		return false
+	}
+	if w.Header().Get("Content-Encoding") != "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	if err != nil || mediaType == "text/event-stream" {
+		return false
+	}
+	for _, allowed := range w.opts.ContentTypes {
+		if family, ok := strings.CutSuffix(allowed, "/*"); ok {
+			if strings.HasPrefix(mediaType, family+"/") {
+				return true
+			}
+		} else if mediaType == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *compressWriter) startEncoder() {
+	if w.encoding == "br" {
+		bw := brotli.NewWriterLevel(w.ResponseWriter, brotli.DefaultCompression)
+		w.enc, w.flusher = bw, bw
+		return
+	}
+	gw := w.gzipPool.Get().(*gzip.Writer)
+	gw.Reset(w.ResponseWriter)
+	w.enc, w.flusher = gw, gw
+}
+
+// finish flushes a body that never reached MinSize and closes the encoder
+func (w *compressWriter) finish() {
+	if !w.decided {
+		w.decide()
+	}
+	if w.enc == nil {
+		return
+	}
+	w.enc.Close()
+	if gw, ok := w.enc.(*gzip.Writer); ok {
+		w.gzipPool.Put(gw)
+	}
+}
@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+var bigJSON = `{"items":"` + strings.Repeat("a", 4096) + `"}`
+
+func newCompressRouter(opts CompressOptions) *gin.Engine {
+	router := gin.New()
+	router.Use(Compress(opts), ConditionalGET())
+	router.GET("/big", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", []byte(bigJSON))
+	})
+	router.GET("/small", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+	router.GET("/image", func(c *gin.Context) {
+		c.Data(http.StatusOK, "image/png", []byte(bigJSON))
+	})
+	router.GET("/events", func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		for i := 0; i < 3; i++ {
+			c.Writer.WriteString("data: " + strings.Repeat("x", 1024) + "\n\n")
+			c.Writer.Flush()
+		}
+	})
+	return router
+}
+
+func getWith(router http.Handler, path string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCompressGzip(t *testing.T) {
+	router := newCompressRouter(DefaultCompressOptions())
+	w := getWith(router, "/big", map[string]string{"Accept-Encoding": "gzip, deflate"})
+
+	if w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Expected gzip encoding, got %q", w.Header().Get("Content-Encoding"))
+	}
+	if !strings.Contains(w.Header().Get("Vary"), "Accept-Encoding") {
+		t.Error("Expected Vary: Accept-Encoding")
+	}
+	if etag := w.Header().Get("ETag"); !strings.HasPrefix(etag, "W/") {
+		t.Errorf("Expected weakened ETag on compressed response, got %q", etag)
+	}
+	zr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatalf("gzip.NewReader() error = %v", err)
+	}
+	body, _ := io.ReadAll(zr)
+	if string(body) != bigJSON {
+		t.Error("Expected decompressed body to match")
+	}
+}
+
+func TestCompressBrotli(t *testing.T) {
+	opts := DefaultCompressOptions()
+	opts.Brotli = true
+	router := newCompressRouter(opts)
+	w := getWith(router, "/big", map[string]string{"Accept-Encoding": "gzip;q=0.8, br"})
+
+	if w.Header().Get("Content-Encoding") != "br" {
+		t.Fatalf("Expected br encoding, got %q", w.Header().Get("Content-Encoding"))
+	}
+	body, _ := io.ReadAll(brotli.NewReader(w.Body))
+	if string(body) != bigJSON {
+		t.Error("Expected decompressed body to match")
+	}
+}
+
+func TestCompressSkips(t *testing.T) {
+	router := newCompressRouter(DefaultCompressOptions())
+
+	tests := []struct {
+		name    string
+		path    string
+		headers map[string]string
+	}{
+		{"no accept-encoding", "/big", nil},
+		{"gzip refused", "/big", map[string]string{"Accept-Encoding": "gzip;q=0, identity"}},
+		{"below min size", "/small", map[string]string{"Accept-Encoding": "gzip"}},
+		{"content type not allowed", "/image", map[string]string{"Accept-Encoding": "gzip"}},
+		{"event stream", "/events", map[string]string{"Accept-Encoding": "gzip"}},
+		{"websocket upgrade", "/big", map[string]string{"Accept-Encoding": "gzip", "Upgrade": "websocket"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := getWith(router, tt.path, tt.headers)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected 200, got %d", w.Code)
+			}
+			if enc := w.Header().Get("Content-Encoding"); enc != "" {
+				t.Errorf("Expected identity encoding, got %q", enc)
+			}
+		})
+	}
+}
+
+func TestCompressStreamsEventsUnbuffered(t *testing.T) {
+	router := newCompressRouter(DefaultCompressOptions())
+	w := getWith(router, "/events", map[string]string{"Accept-Encoding": "gzip"})
+
+	if got := strings.Count(w.Body.String(), "data: "); got != 3 {
+		t.Errorf("Expected 3 events, got %d", got)
+	}
+	if !w.Flushed {
+		t.Error("Expected the stream to be flushed")
+	}
+}
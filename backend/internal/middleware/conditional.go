@@ -0,0 +1,174 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxETagBody caps how much of a response ConditionalGET buffers; larger
+// responses are streamed without an ETag
+const maxETagBody = 4 << 20
+
+// ConditionalGET answers GET and HEAD requests whose If-None-Match or
+// If-Modified-Since still matches with 304 Not Modified. Handlers may set
+// their own validators with SetETag and SetLastModified; otherwise a strong
+// ETag is computed from the body of 200 responses. Streaming responses (a
+// handler calling Flush, websocket upgrades, event streams) are passed through.
+func ConditionalGET() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		method := c.Request.Method
+		if (method != http.MethodGet && method != http.MethodHead) || isStreamingRequest(c.Request) {
+			c.Next()
+			return
+		}
+
+		w := &etagWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+		w.finish(c.Request)
+		c.Writer = w.ResponseWriter
+	}
+}
+
+// SetETag sets the response ETag from an opaque tag; weak tags compare equal
+// for semantically equivalent representations
+func SetETag(c *gin.Context, tag string, weak bool) {
+	etag := `"` + strings.Trim(tag, `"`) + `"`
+	if weak {
+		etag = "W/" + etag
+	}
+	c.Header("ETag", etag)
+}
+
+// SetLastModified sets the Last-Modified header
+func SetLastModified(c *gin.Context, t time.Time) {
+	c.Header("Last-Modified", t.UTC().Format(http.TimeFormat))
+}
+
+// NotModified answers 304 and aborts when the validators already set with
+// SetETag/SetLastModified match the request, so handlers can skip building
+// the body. It reports whether it did.
+func NotModified(c *gin.Context) bool {
+	header := c.Writer.Header()
+	if !requestNotModified(c.Request, header.Get("ETag"), header.Get("Last-Modified")) {
+		return false
+	}
+	writeNotModified(c.Writer)
+	c.Abort()
+	return true
+}
+
+// requestNotModified applies RFC 9110: If-None-Match (weak comparison) wins
+// over If-Modified-Since
+func requestNotModified(r *http.Request, etag, lastModified string) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		if etag == "" {
+			return false
+		}
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	ims := r.Header.Get("If-Modified-Since")
+	if ims == "" || lastModified == "" {
+		return false
+	}
+	since, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(lastModified)
+	return err == nil && !modified.After(since)
+}
+
+func writeNotModified(w gin.ResponseWriter) {
+	header := w.Header()
+	header.Del("Content-Type")
+	header.Del("Content-Length")
+	w.WriteHeader(http.StatusNotModified)
+	w.WriteHeaderNow()
+}
+
+// etagWriter holds the body back until the handler is done
+type etagWriter struct {
+	gin.ResponseWriter
+	buf         []byte
+	passthrough bool
+}
+
+func (w *etagWriter) Write(b []byte) (int, error) {
+	if w.passthrough {
+		return w.ResponseWriter.Write(b)
+	}
+	w.buf = append(w.buf, b...)
+	if len(w.buf) > maxETagBody {
+		w.startPassthrough()
+	}
+	return len(b), nil
+}
+
+func (w *etagWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Written reports buffered bytes as written so error middleware leaves them alone
+func (w *etagWriter) Written() bool {
+	return len(w.buf) > 0 || w.ResponseWriter.Written()
+}
+
+// Flush means the handler is streaming; stop buffering for good
+func (w *etagWriter) Flush() {
+	w.startPassthrough()
+	w.ResponseWriter.Flush()
+}
+
+func (w *etagWriter) startPassthrough() {
+	if w.passthrough {
+		return
+	}
+	w.passthrough = true
+	buf := w.buf
+	w.buf = nil
+	if len(buf) > 0 {
+		w.ResponseWriter.Write(buf)
+	}
+}
+
+func (w *etagWriter) finish(r *http.Request) {
+	if w.passthrough {
+		return
+	}
+	// Handlers like NotModified may already have sent the headers
+	if w.ResponseWriter.Written() {
+		w.startPassthrough()
+		return
+	}
+	// Nothing written yet: leave the response to outer error middleware
+	if len(w.buf) == 0 {
+		return
+	}
+
+	header := w.Header()
+	if w.Status() == http.StatusOK {
+		if header.Get("ETag") == "" && r.Method == http.MethodGet {
+			sum := sha256.Sum256(w.buf)
+			header.Set("ETag", `"`+base64.RawURLEncoding.EncodeToString(sum[:18])+`"`)
+		}
+		if requestNotModified(r, header.Get("ETag"), header.Get("Last-Modified")) {
+			w.buf = nil
+			writeNotModified(w.ResponseWriter)
+			return
+		}
+	}
+	w.startPassthrough()
+}
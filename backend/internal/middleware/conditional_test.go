@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestConditionalGETComputesETag(t *testing.T) {
+	calls := 0
+	router := gin.New()
+	router.Use(ConditionalGET())
+	router.GET("/items", func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusOK, gin.H{"items": []int{1, 2, 3}})
+	})
+
+	first := getWith(router, "/items", nil)
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" || etag[0] != '"' {
+		t.Fatalf("Expected 200 with a strong ETag, got %d %q", first.Code, etag)
+	}
+
+	second := getWith(router, "/items", map[string]string{"If-None-Match": `"other", ` + etag})
+	if second.Code != http.StatusNotModified || second.Body.Len() != 0 {
+		t.Errorf("Expected empty 304, got %d %q", second.Code, second.Body.String())
+	}
+	if second.Header().Get("ETag") != etag {
+		t.Errorf("Expected 304 to repeat the ETag, got %q", second.Header().Get("ETag"))
+	}
+
+	// Weak comparison: a weakened tag from a compressed response still matches
+	third := getWith(router, "/items", map[string]string{"If-None-Match": "W/" + etag})
+	if third.Code != http.StatusNotModified {
+		t.Errorf("Expected 304 for weak match, got %d", third.Code)
+	}
+
+	stale := getWith(router, "/items", map[string]string{"If-None-Match": `"stale"`})
+	if stale.Code != http.StatusOK || stale.Body.Len() == 0 {
+		t.Errorf("Expected full 200 for a stale tag, got %d", stale.Code)
+	}
+}
+
+func TestConditionalGETHandlerValidators(t *testing.T) {
+	modified := time.Date(2025, 8, 1, 12, 0, 0, 0, time.UTC)
+	built := 0
+	router := gin.New()
+	router.Use(ConditionalGET())
+	router.GET("/profile", func(c *gin.Context) {
+		SetETag(c, "v42", true)
+		SetLastModified(c, modified)
+		if NotModified(c) {
+			return
+		}
+		built++
+		c.JSON(http.StatusOK, gin.H{"name": "Alice"})
+	})
+
+	w := getWith(router, "/profile", nil)
+	if w.Header().Get("ETag") != `W/"v42"` || w.Header().Get("Last-Modified") == "" {
+		t.Errorf("Expected handler validators, got %v", w.Header())
+	}
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    int
+	}{
+		{"etag match", map[string]string{"If-None-Match": `W/"v42"`}, http.StatusNotModified},
+		{"not modified since", map[string]string{"If-Modified-Since": modified.Add(time.Hour).Format(http.TimeFormat)}, http.StatusNotModified},
+		{"modified since", map[string]string{"If-Modified-Since": modified.Add(-time.Hour).Format(http.TimeFormat)}, http.StatusOK},
+		{"if-none-match wins", map[string]string{
+			"If-None-Match":     `"v41"`,
+			"If-Modified-Since": modified.Add(time.Hour).Format(http.TimeFormat),
+		}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := getWith(router, "/profile", tt.headers); w.Code != tt.want {
+				t.Errorf("Expected %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+	if built != 3 {
+		t.Errorf("Expected the body to be built 3 times, got %d", built)
+	}
+}
+
+func TestConditionalGETIgnoresUnsafeMethodsAndErrors(t *testing.T) {
+	router := gin.New()
+	router.Use(ConditionalGET())
+	router.POST("/items", func(c *gin.Context) { c.JSON(http.StatusCreated, gin.H{}) })
+	router.GET("/missing", func(c *gin.Context) { c.JSON(http.StatusNotFound, gin.H{}) })
+
+	req := httptest.NewRequest(http.MethodPost, "/items", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Header().Get("ETag") != "" {
+		t.Error("Expected no ETag on POST")
+	}
+
+	if w := getWith(router, "/missing", map[string]string{"If-None-Match": "*"}); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 to pass through, got %d", w.Code)
+	}
+}
+
+func TestConditionalGETLeavesUnwrittenErrorsToOuterMiddleware(t *testing.T) {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Next()
+		if !c.Writer.Written() {
+			c.String(http.StatusTeapot, "rendered outside")
+		}
+	})
+	router.Use(Compress(DefaultCompressOptions()), ConditionalGET())
+	router.GET("/fail", func(c *gin.Context) { c.Error(http.ErrAbortHandler) })
+
+	w := getWith(router, "/fail", map[string]string{"Accept-Encoding": "gzip"})
+	if w.Code != http.StatusTeapot || w.Body.String() != "rendered outside" {
+		t.Errorf("Expected outer middleware response, got %d %q", w.Code, w.Body.String())
+	}
+	if w.Header().Get("ETag") != "" {
+		t.Errorf("Expected no ETag, got %q", w.Header().Get("ETag"))
+	}
+}
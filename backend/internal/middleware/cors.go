@@ -1,22 +1,283 @@
 package middleware
 
 import (
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/config"
 )
 
+// CORSOptions describes a Cross-Origin Resource Sharing policy.
+//
+// AllowedOrigins entries are exact origins ("http://localhost:3000"),
+// "*" for any origin, or wildcard subdomain patterns with or without a
+// scheme ("https://*.example.com", "*.example.com"). A pattern only
+// restricts the port when it names one ("https://*.example.com:8443").
+type CORSOptions struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// DefaultCORSOptions returns the methods and headers the API uses, with no origins allowed
+func DefaultCORSOptions() CORSOptions {
+	return CORSOptions{
+		AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders: []string{
+			"Accept", "Accept-Encoding", "Authorization", "Cache-Control",
+			"Content-Type", "Content-Length", "If-Modified-Since", "If-None-Match", "Origin",
+			"X-CSRF-Token", "X-Requested-With", RequestIDHeader, APIKeyHeader, IdempotencyKeyHeader,
+		},
+		ExposedHeaders: []string{
+			RequestIDHeader, "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After",
+			IdempotentReplayedHeader, "ETag",
+		},
+		MaxAge: 12 * time.Hour,
+	}
+}
+
+// CORSFromConfig builds CORSOptions from Config.CORSOrigins and the cors section
+func CORSFromConfig(cfg *config.Config) CORSOptions {
+	opts := DefaultCORSOptions()
+	opts.AllowedOrigins = cfg.AllowedOrigins()
+	opts.AllowCredentials = cfg.CORS.AllowCredentials
+	opts.MaxAge = cfg.CORS.MaxAge
+	return opts
+}
+
+// CORSPolicy applies a default CORSOptions plus per-path-prefix overrides.
+// Overrides are resolved by path rather than attached to router groups so
+// that preflight requests, which have no matching route, still see them.
+type CORSPolicy struct {
+	defaults  *corsRules
+	overrides []corsOverride
+}
+
+type corsOverride struct {
+	prefix string
+	rules  *corsRules
+}
+
+// NewCORSPolicy creates a policy applying opts to every path
+func NewCORSPolicy(opts CORSOptions) *CORSPolicy {
+	return &CORSPolicy{defaults: newCORSRules(opts)}
+}
+
+// Override applies opts to requests whose path starts with prefix (e.g. "/api/v1/public").
+// The longest matching prefix wins.
+func (p *CORSPolicy) Override(prefix string, opts CORSOptions) *CORSPolicy {
+	p.overrides = append(p.overrides, corsOverride{prefix: prefix, rules: newCORSRules(opts)})
+	sort.SliceStable(p.overrides, func(i, j int) bool {
+		return len(p.overrides[i].prefix) > len(p.overrides[j].prefix)
+	})
+	return p
+}
+
+// Handler returns the gin middleware enforcing the policy
+func (p *CORSPolicy) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p.rulesFor(c.Request.URL.Path).handle(c)
+	}
+}
+
+func (p *CORSPolicy) rulesFor(path string) *corsRules {
+	for _, o := range p.overrides {
+		if strings.HasPrefix(path, o.prefix) {
+			return o.rules
+		}
+	}
+	return p.defaults
+}
+
 // CORS middleware to handle Cross-Origin Resource Sharing
-func CORS() gin.HandlerFunc {
-	return gin.HandlerFunc(func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
-
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+func CORS(opts CORSOptions) gin.HandlerFunc {
+	return NewCORSPolicy(opts).Handler()
+}
+
+// corsRules is CORSOptions preprocessed for matching
+type corsRules struct {
+	opts           CORSOptions
+	anyOrigin      bool
+	exact          map[string]bool
+	patterns       []originPattern
+	methods        map[string]bool
+	anyHeader      bool
+	headers        map[string]bool
+	allowMethods   string
+	allowHeaders   string
+	exposedHeaders string
+	maxAge         string
+}
+
+// originPattern matches "scheme://*.host:port" style origins; an empty
+// scheme or port matches any
+type originPattern struct {
+	scheme string
+	suffix string
+	port   string
+}
+
+func newCORSRules(opts CORSOptions) *corsRules {
+	r := &corsRules{
+		opts:    opts,
+		exact:   make(map[string]bool),
+		methods: make(map[string]bool),
+		headers: make(map[string]bool),
+	}
+
+	for _, origin := range opts.AllowedOrigins {
+		origin = strings.ToLower(strings.TrimSpace(origin))
+		switch {
+		case origin == "*":
+			r.anyOrigin = true
+		case strings.Contains(origin, "*."):
+			var p originPattern
+			if scheme, host, ok := strings.Cut(origin, "://"); ok {
+				p.scheme, origin = scheme, host
+			}
+			if host, port, err := net.SplitHostPort(origin); err == nil {
+				origin, p.port = host, port
+			}
+			p.suffix = strings.TrimPrefix(origin, "*")
+			r.patterns = append(r.patterns, p)
+		case origin != "":
+			r.exact[origin] = true
+		}
+	}
+
+	for _, m := range opts.AllowedMethods {
+		r.methods[strings.ToUpper(m)] = true
+	}
+	for _, h := range opts.AllowedHeaders {
+		if h == "*" {
+			r.anyHeader = true
+		}
+		r.headers[strings.ToLower(h)] = true
+	}
+
+	r.allowMethods = strings.Join(opts.AllowedMethods, ", ")
+	r.allowHeaders = strings.Join(opts.AllowedHeaders, ", ")
+	r.exposedHeaders = strings.Join(opts.ExposedHeaders, ", ")
+	if opts.MaxAge > 0 {
+		r.maxAge = strconv.Itoa(int(opts.MaxAge / time.Second))
+	}
+	return r
+}
+
+func (r *corsRules) originAllowed(origin string) bool {
+	if r.anyOrigin {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	if r.exact[origin] {
+		return true
+	}
+	if len(r.patterns) == 0 {
+		return false
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	host := u.Hostname()
+	for _, p := range r.patterns {
+		if p.scheme != "" && p.scheme != u.Scheme {
+			continue
+		}
+		if p.port != "" && p.port != u.Port() {
+			continue
+		}
+		// "*.example.com" needs at least one label in front of the suffix
+		if strings.HasSuffix(host, p.suffix) && len(host) > len(p.suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *corsRules) headersAllowed(requested string) bool {
+	if r.anyHeader || requested == "" {
+		return true
+	}
+	for _, h := range strings.Split(requested, ",") {
+		if h = strings.ToLower(strings.TrimSpace(h)); h != "" && !r.headers[h] {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *corsRules) handle(c *gin.Context) {
+	header := c.Writer.Header()
+	origin := c.Request.Header.Get("Origin")
+	preflight := c.Request.Method == http.MethodOptions &&
+		c.Request.Header.Get("Access-Control-Request-Method") != ""
+
+	// Responses differ per origin, so caches must key on it
+	header.Add("Vary", "Origin")
+	if preflight {
+		header.Add("Vary", "Access-Control-Request-Method")
+		header.Add("Vary", "Access-Control-Request-Headers")
+	}
+
+	if origin == "" {
+		c.Next()
+		return
+	}
+
+	if !r.originAllowed(origin) {
+		if preflight {
+			c.AbortWithStatus(http.StatusForbidden)
 			return
 		}
+		// Let the request through without CORS headers; the browser blocks the response
+		c.Next()
+		return
+	}
+
+	// Browsers reject "*" together with credentials, so echo the origin instead
+	if r.anyOrigin && !r.opts.AllowCredentials {
+		header.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		header.Set("Access-Control-Allow-Origin", origin)
+	}
+	if r.opts.AllowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
 
+	if !preflight {
+		if r.exposedHeaders != "" {
+			header.Set("Access-Control-Expose-Headers", r.exposedHeaders)
+		}
 		c.Next()
-	})
+		return
+	}
+
+	method := strings.ToUpper(c.Request.Header.Get("Access-Control-Request-Method"))
+	requestedHeaders := c.Request.Header.Get("Access-Control-Request-Headers")
+	if !r.methods[method] || !r.headersAllowed(requestedHeaders) {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	header.Set("Access-Control-Allow-Methods", r.allowMethods)
+	if r.anyHeader && requestedHeaders != "" {
+		header.Set("Access-Control-Allow-Headers", requestedHeaders)
+	} else if r.allowHeaders != "" {
+		header.Set("Access-Control-Allow-Headers", r.allowHeaders)
+	}
+	if r.maxAge != "" {
+		header.Set("Access-Control-Max-Age", r.maxAge)
+	}
+	c.AbortWithStatus(http.StatusNoContent)
 }
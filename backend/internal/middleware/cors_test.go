@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func newCORSRouter(policy *CORSPolicy) *gin.Engine {
+	router := gin.New()
+	router.Use(policy.Handler())
+	router.GET("/api/v1/ping", func(c *gin.Context) { c.String(http.StatusOK, "pong") })
+	router.GET("/public/feed", func(c *gin.Context) { c.String(http.StatusOK, "feed") })
+	return router
+}
+
+func corsRequest(router http.Handler, method, path, origin string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCORSOriginMatching(t *testing.T) {
+	opts := DefaultCORSOptions()
+	opts.AllowedOrigins = []string{"http://localhost:3000", "https://*.example.com", "https://*.internal.test:8443"}
+	opts.AllowCredentials = true
+	router := newCORSRouter(NewCORSPolicy(opts))
+
+	tests := []struct {
+		name    string
+		origin  string
+		allowed bool
+	}{
+		{"exact origin", "http://localhost:3000", true},
+		{"subdomain", "https://app.example.com", true},
+		{"nested subdomain", "https://a.b.example.com", true},
+		{"bare domain", "https://example.com", false},
+		{"wrong scheme", "http://app.example.com", false},
+		{"suffix trick", "https://evilexample.com", false},
+		{"subdomain with port", "https://app.example.com:8443", true},
+		{"bare domain with port", "https://example.com:8443", false},
+		{"pattern port", "https://app.internal.test:8443", true},
+		{"other port", "https://app.internal.test:9443", false},
+		{"missing port", "https://app.internal.test", false},
+		{"unknown origin", "http://evil.test", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := corsRequest(router, http.MethodGet, "/api/v1/ping", tt.origin, nil)
+			got := w.Header().Get("Access-Control-Allow-Origin")
+			if tt.allowed && got != tt.origin {
+				t.Errorf("Expected origin %q to be echoed, got %q", tt.origin, got)
+			}
+			if !tt.allowed && got != "" {
+				t.Errorf("Expected no Allow-Origin for %q, got %q", tt.origin, got)
+			}
+			if w.Header().Get("Vary") != "Origin" {
+				t.Errorf("Expected Vary: Origin, got %q", w.Header().Get("Vary"))
+			}
+			if w.Code != http.StatusOK {
+				t.Errorf("Expected simple request to pass through, got %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestCORSCredentialsNeverWithWildcard(t *testing.T) {
+	opts := DefaultCORSOptions()
+	opts.AllowedOrigins = []string{"*"}
+	opts.AllowCredentials = true
+	router := newCORSRouter(NewCORSPolicy(opts))
+
+	w := corsRequest(router, http.MethodGet, "/api/v1/ping", "http://any.test", nil)
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "http://any.test" {
+		t.Errorf("Expected echoed origin with credentials, got %q", got)
+	}
+
+	opts.AllowCredentials = false
+	router = newCORSRouter(NewCORSPolicy(opts))
+	w = corsRequest(router, http.MethodGet, "/api/v1/ping", "http://any.test", nil)
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Expected '*' without credentials, got %q", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Errorf("Expected no Allow-Credentials, got %q", got)
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	opts := DefaultCORSOptions()
+	opts.AllowedOrigins = []string{"http://localhost:3000"}
+	router := newCORSRouter(NewCORSPolicy(opts))
+
+	w := corsRequest(router, http.MethodOptions, "/api/v1/ping", "http://localhost:3000", map[string]string{
+		"Access-Control-Request-Method":  "POST",
+		"Access-Control-Request-Headers": "content-type, authorization",
+	})
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204 for allowed preflight, got %d", w.Code)
+	}
+	if got := w.Header().Get("Access-Control-Max-Age"); got != "43200" {
+		t.Errorf("Expected Max-Age 43200, got %q", got)
+	}
+	if w.Header().Get("Access-Control-Allow-Methods") == "" {
+		t.Error("Expected Allow-Methods on preflight")
+	}
+
+	tests := []struct {
+		name    string
+		origin  string
+		headers map[string]string
+	}{
+		{"disallowed origin", "http://evil.test", map[string]string{"Access-Control-Request-Method": "GET"}},
+		{"disallowed method", "http://localhost:3000", map[string]string{"Access-Control-Request-Method": "TRACE"}},
+		{"disallowed header", "http://localhost:3000", map[string]string{
+			"Access-Control-Request-Method":  "GET",
+			"Access-Control-Request-Headers": "x-secret",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := corsRequest(router, http.MethodOptions, "/api/v1/ping", tt.origin, tt.headers)
+			if w.Code != http.StatusForbidden {
+				t.Errorf("Expected 403, got %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestCORSOverride(t *testing.T) {
+	opts := DefaultCORSOptions()
+	opts.AllowedOrigins = []string{"http://localhost:3000"}
+
+	public := DefaultCORSOptions()
+	public.AllowedOrigins = []string{"*"}
+	public.ExposedHeaders = []string{"X-Total-Count"}
+
+	router := newCORSRouter(NewCORSPolicy(opts).Override("/public", public))
+
+	w := corsRequest(router, http.MethodGet, "/public/feed", "http://other.test", nil)
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Expected override to allow any origin, got %q", got)
+	}
+	if got := w.Header().Get("Access-Control-Expose-Headers"); got != "X-Total-Count" {
+		t.Errorf("Expected exposed headers, got %q", got)
+	}
+
+	w = corsRequest(router, http.MethodGet, "/api/v1/ping", "http://other.test", nil)
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Expected default policy to reject other origin, got %q", got)
+	}
+}
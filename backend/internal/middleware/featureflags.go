@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/apierror"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/featureflags"
+)
+
+// Gin context key for the flag evaluator
+const featureFlagsKey = "featureFlags"
+
+// FlagEvaluator answers flag lookups; *featureflags.Set implements it
+type FlagEvaluator interface {
+	Enabled(name string, subj featureflags.Subject) bool
+	Evaluate(subj featureflags.Subject) map[string]bool
+}
+
+// FeatureFlags makes flags available to handlers through FlagEnabled and
+// Flags. It must run after OptionalAuth so flags are evaluated for the caller.
+func FeatureFlags(flags FlagEvaluator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(featureFlagsKey, flags)
+		c.Next()
+	}
+}
+
+// FlagSubject returns who flags are evaluated for in this request
+func FlagSubject(c *gin.Context) featureflags.Subject {
+	id, _ := GetUserID(c)
+	return featureflags.Subject{UserID: id, Roles: GetUserRoles(c)}
+}
+
+// FlagEnabled reports whether the named flag is on for the caller. Without
+// the FeatureFlags middleware every flag is off.
+func FlagEnabled(c *gin.Context, name string) bool {
+	flags, ok := c.Get(featureFlagsKey)
+	return ok && flags.(FlagEvaluator).Enabled(name, FlagSubject(c))
+}
+
+// Flags returns the value of every flag for the caller
+func Flags(c *gin.Context) map[string]bool {
+	flags, ok := c.Get(featureFlagsKey)
+	if !ok {
+		return map[string]bool{}
+	}
+	return flags.(FlagEvaluator).Evaluate(FlagSubject(c))
+}
+
+// RequireFlag answers 404 while the named flag is off for the caller, so
+// unreleased endpoints look like they do not exist
+func RequireFlag(name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !FlagEnabled(c, name) {
+			apierror.NotFoundHandler(c)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/featureflags"
+)
+
+func TestFeatureFlags(t *testing.T) {
+	flags, err := featureflags.New([]featureflags.Flag{
+		{Name: "beta", Enabled: true, Percentage: 0, Users: []int{7}},
+		{Name: "public", Enabled: true, Percentage: 100},
+	}, nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if c.GetHeader("X-Test-User") != "" {
+			c.Set(userIDKey, 7)
+		}
+	}, FeatureFlags(flags))
+	router.GET("/beta", RequireFlag("beta"), func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/values", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"beta": FlagEnabled(c, "beta"), "all": len(Flags(c))})
+	})
+
+	tests := []struct {
+		name       string
+		path       string
+		user       bool
+		wantStatus int
+		wantBody   string
+	}{
+		{"gated for anonymous", "/beta", false, http.StatusNotFound, ""},
+		{"open for allowlisted user", "/beta", true, http.StatusOK, ""},
+		{"anonymous values", "/values", false, http.StatusOK, `{"all":2,"beta":false}`},
+		{"user values", "/values", true, http.StatusOK, `{"all":2,"beta":true}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.user {
+				req.Header.Set("X-Test-User", "7")
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("Expected body %s, got %s", tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestFlagEnabledWithoutMiddleware(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	if FlagEnabled(c, "anything") || len(Flags(c)) != 0 {
+		t.Error("Expected every flag to be off without the middleware")
+	}
+}
@@ -0,0 +1,251 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/apierror"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/idempotency"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/logging"
+)
+
+// Idempotency headers
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	defaultIdempotencyMaxBody = 1 << 20
+)
+
+// IdempotencyOptions configures the Idempotency middleware
+type IdempotencyOptions struct {
+	Store idempotency.Store
+	// TTL is how long a stored response is replayed
+	TTL time.Duration
+	// LockTimeout bounds how long an unfinished request blocks its key,
+	// in case the instance handling it dies; defaults to one minute
+	LockTimeout time.Duration
+	// MaxBody is the largest request body fingerprinted; bigger requests get 413
+	MaxBody int64
+	// Key scopes keys per caller; defaults to KeyFirst(KeyByUser, KeyByIP)
+	Key KeyFunc
+}
+
+// Idempotency honours the Idempotency-Key header on unsafe methods. The
+// first response for a key is stored and replayed for identical retries
+// with an Idempotent-Replayed header. Reusing a key with a different
+// method, path or body gives 422, and a retry while the first request is
+// still running gives 409. Server errors and transient rejections (408,
+// 409, 413, 425, 429) are not stored, so those can be retried. Responses
+// marked Cache-Control: no-store, such as issued tokens, are never kept.
+// If the store fails the request goes through without protection.
+func Idempotency(opts IdempotencyOptions) gin.HandlerFunc {
+	if opts.Key == nil {
+		opts.Key = KeyFirst(KeyByUser, KeyByIP)
+	}
+	if opts.LockTimeout <= 0 {
+		opts.LockTimeout = time.Minute
+	}
+	if opts.MaxBody <= 0 {
+		opts.MaxBody = defaultIdempotencyMaxBody
+	}
+
+	return func(c *gin.Context) {
+		header := c.GetHeader(IdempotencyKeyHeader)
+		if header == "" || !unsafeMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+		if len(header) > maxIdempotencyKeyLength {
+			apierror.Abort(c, apierror.BadRequest("Idempotency-Key must be at most 255 characters").WithCode("invalid_idempotency_key"))
+			return
+		}
+		scope, ok := opts.Key(c)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, opts.MaxBody))
+		if err != nil {
+			apierror.Abort(c, err)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		key := hashParts(scope, header)
+		fingerprint := hashParts(c.Request.Method, c.Request.URL.RequestURI(), string(body))
+		now := time.Now()
+
+		rec, started, err := opts.Store.Begin(ctx, key, fingerprint, now.Add(opts.LockTimeout), now)
+		if err != nil {
+			logging.FromContext(ctx).Error("idempotency store failed", "error", err)
+			c.Next()
+			return
+		}
+
+		if !started {
+			switch {
+			case rec.Fingerprint != fingerprint:
+				apierror.Abort(c, apierror.New(http.StatusUnprocessableEntity, "idempotency_key_reused",
+					"Idempotency-Key was already used for a different request"))
+			case !rec.Completed:
+				c.Header("Retry-After", "1")
+				apierror.Abort(c, apierror.Conflict("a request with this Idempotency-Key is still in progress").
+					WithCode("idempotency_in_progress"))
+			default:
+				replay(c, rec.Response)
+			}
+			return
+		}
+
+		recorder := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		completed := false
+		defer func() {
+			// Panics and responses that are not stored free the key so the
+			// client can retry
+			if !completed {
+				if err := opts.Store.Release(context.WithoutCancel(ctx), key); err != nil {
+					logging.FromContext(ctx).Error("idempotency release failed", "error", err)
+				}
+			}
+		}()
+
+		c.Next()
+
+		status := recorder.Status()
+		if retryableStatus(status) || noStore(recorder.handlerHeader()) {
+			return
+		}
+		resp := idempotency.Response{
+			Status: status,
+			Header: storableHeaders(recorder.handlerHeader()),
+			Body:   recorder.body.Bytes(),
+		}
+		if err := opts.Store.Complete(context.WithoutCancel(ctx), key, resp, time.Now().Add(opts.TTL)); err != nil {
+			logging.FromContext(ctx).Error("idempotency store failed", "error", err)
+			return
+		}
+		completed = true
+	}
+}
+
+// retryableStatus reports whether a response describes a passing condition
+// rather than the outcome of the request, so a retry may well succeed
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusRequestTimeout, http.StatusConflict, http.StatusRequestEntityTooLarge,
+		http.StatusTooEarly, http.StatusTooManyRequests:
+		return true
+	}
+	return status >= http.StatusInternalServerError
+}
+
+// noStore reports whether the handler asked for the response not to be kept
+func noStore(h http.Header) bool {
+	for _, directive := range strings.Split(h.Get("Cache-Control"), ",") {
+		if strings.EqualFold(strings.TrimSpace(directive), "no-store") {
+			return true
+		}
+	}
+	return false
+}
+
+func unsafeMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func hashParts(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		io.WriteString(h, p)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// perRequestHeaders belong to one exchange and are never replayed
+var perRequestHeaders = []string{
+	"Set-Cookie", "Date", "Content-Length", RequestIDHeader,
+	"Ratelimit-Limit", "Ratelimit-Remaining", "Ratelimit-Reset", "Retry-After",
+	"Access-Control-Allow-Origin", "Access-Control-Allow-Credentials", "Access-Control-Expose-Headers", "Vary",
+}
+
+func storableHeaders(h http.Header) http.Header {
+	out := h.Clone()
+	for _, name := range perRequestHeaders {
+		out.Del(name)
+	}
+	return out
+}
+
+func replay(c *gin.Context, resp idempotency.Response) {
+	for name, values := range resp.Header {
+		if strings.EqualFold(name, "Content-Length") {
+			continue
+		}
+		c.Writer.Header()[name] = values
+	}
+	c.Header(IdempotentReplayedHeader, "true")
+	c.Status(resp.Status)
+	c.Writer.Write(resp.Body)
+	c.Abort()
+}
+
+// recordingWriter copies the body while passing it through. It also
+// snapshots the headers as the handler left them before the first write
+// reaches the writers outside it: Compress rewrites Content-Encoding, ETag
+// and Vary there, while the recorded body is still uncompressed. A replay
+// goes back through Compress and is encoded afresh.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body   bytes.Buffer
+	header http.Header
+}
+
+func (w *recordingWriter) snapshot() {
+	if w.header == nil {
+		w.header = w.ResponseWriter.Header().Clone()
+	}
+}
+
+// handlerHeader returns the headers set by the handler
+func (w *recordingWriter) handlerHeader() http.Header {
+	w.snapshot()
+	return w.header
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.snapshot()
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.snapshot()
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *recordingWriter) WriteHeaderNow() {
+	w.snapshot()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *recordingWriter) Flush() {
+	w.snapshot()
+	w.ResponseWriter.Flush()
+}
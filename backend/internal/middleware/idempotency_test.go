@@ -0,0 +1,207 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/idempotency"
+)
+
+func newIdempotencyRouter(t *testing.T, handler gin.HandlerFunc) *gin.Engine {
+	t.Helper()
+	store := idempotency.NewMemoryStore(0)
+	t.Cleanup(store.Close)
+
+	router := gin.New()
+	router.Use(Idempotency(IdempotencyOptions{Store: store, TTL: time.Hour}))
+	router.POST("/orders", handler)
+	router.GET("/orders", handler)
+	return router
+}
+
+func postOrder(router http.Handler, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReplaysFirstResponse(t *testing.T) {
+	var calls atomic.Int32
+	router := newIdempotencyRouter(t, func(c *gin.Context) {
+		n := calls.Add(1)
+		c.Header("Location", "/orders/"+strconv.Itoa(int(n)))
+		c.JSON(http.StatusCreated, gin.H{"id": n})
+	})
+
+	first := postOrder(router, "abc", `{"item":"tea"}`)
+	second := postOrder(router, "abc", `{"item":"tea"}`)
+
+	if calls.Load() != 1 {
+		t.Errorf("Expected handler to run once, ran %d times", calls.Load())
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Errorf("Expected replay of %d %s, got %d %s", first.Code, first.Body, second.Code, second.Body)
+	}
+	if second.Header().Get("Location") != "/orders/1" || second.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Errorf("Expected replayed headers, got %v", second.Header())
+	}
+
+	// Without a key or on safe methods nothing is deduplicated
+	postOrder(router, "", `{"item":"tea"}`)
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set(IdempotencyKeyHeader, "abc")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	if calls.Load() != 3 {
+		t.Errorf("Expected 3 handler calls, got %d", calls.Load())
+	}
+}
+
+func TestIdempotencyRejectsDifferentPayload(t *testing.T) {
+	router := newIdempotencyRouter(t, func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	postOrder(router, "abc", `{"item":"tea"}`)
+	w := postOrder(router, "abc", `{"item":"coffee"}`)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 for a reused key, got %d", w.Code)
+	}
+}
+
+func TestIdempotencyConflictWhileInFlight(t *testing.T) {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	router := newIdempotencyRouter(t, func(c *gin.Context) {
+		close(entered)
+		<-release
+		c.Status(http.StatusCreated)
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- postOrder(router, "abc", `{}`) }()
+	<-entered
+
+	w := postOrder(router, "abc", `{}`)
+	if w.Code != http.StatusConflict || w.Header().Get("Retry-After") == "" {
+		t.Errorf("Expected 409 with Retry-After while in flight, got %d", w.Code)
+	}
+
+	close(release)
+	if first := <-done; first.Code != http.StatusCreated {
+		t.Errorf("Expected first request to finish with 201, got %d", first.Code)
+	}
+}
+
+func TestIdempotencyDoesNotStoreServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	router := newIdempotencyRouter(t, func(c *gin.Context) {
+		if calls.Add(1) == 1 {
+			c.Status(http.StatusServiceUnavailable)
+			return
+		}
+		c.Status(http.StatusCreated)
+	})
+
+	postOrder(router, "abc", `{}`)
+	if w := postOrder(router, "abc", `{}`); w.Code != http.StatusCreated {
+		t.Errorf("Expected retry after a 503 to run again, got %d", w.Code)
+	}
+}
+
+func TestIdempotencyDoesNotStoreTransientRejections(t *testing.T) {
+	for _, status := range []int{http.StatusRequestTimeout, http.StatusConflict,
+		http.StatusRequestEntityTooLarge, http.StatusTooEarly, http.StatusTooManyRequests} {
+		t.Run(strconv.Itoa(status), func(t *testing.T) {
+			var calls atomic.Int32
+			router := newIdempotencyRouter(t, func(c *gin.Context) {
+				if calls.Add(1) == 1 {
+					c.Header("Retry-After", "30")
+					c.Status(status)
+					return
+				}
+				c.Status(http.StatusCreated)
+			})
+
+			postOrder(router, "abc", `{}`)
+			if w := postOrder(router, "abc", `{}`); w.Code != http.StatusCreated {
+				t.Errorf("Expected retry after a %d to run again, got %d", status, w.Code)
+			}
+		})
+	}
+}
+
+func TestIdempotencyDoesNotStoreNoStoreResponses(t *testing.T) {
+	var calls atomic.Int32
+	router := newIdempotencyRouter(t, func(c *gin.Context) {
+		calls.Add(1)
+		c.Header("Cache-Control", "private, no-store")
+		c.JSON(http.StatusOK, gin.H{"access_token": "secret"})
+	})
+
+	postOrder(router, "abc", `{}`)
+	w := postOrder(router, "abc", `{}`)
+	if calls.Load() != 2 || w.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Errorf("Expected a no-store response to be served fresh, got %d calls", calls.Load())
+	}
+}
+
+func TestIdempotencyReplayThroughCompression(t *testing.T) {
+	store := idempotency.NewMemoryStore(0)
+	t.Cleanup(store.Close)
+
+	var calls atomic.Int32
+	router := gin.New()
+	router.Use(Compress(DefaultCompressOptions()))
+	router.Use(Idempotency(IdempotencyOptions{Store: store, TTL: time.Hour}))
+	router.POST("/orders", func(c *gin.Context) {
+		calls.Add(1)
+		c.Header("ETag", `"v1"`)
+		c.Data(http.StatusCreated, "application/json", []byte(bigJSON))
+	})
+
+	post := func(acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(`{"item":"tea"}`))
+		req.Header.Set(IdempotencyKeyHeader, "abc")
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for i, w := range []*httptest.ResponseRecorder{post("gzip"), post("gzip")} {
+		if w.Header().Get("Content-Encoding") != "gzip" || w.Header().Get("ETag") != `W/"v1"` {
+			t.Fatalf("response %d: expected gzip with a weak ETag, got %v", i, w.Header())
+		}
+		zr, err := gzip.NewReader(w.Body)
+		if err != nil {
+			t.Fatalf("response %d: not gzip: %v", i, err)
+		}
+		body, _ := io.ReadAll(zr)
+		if string(body) != bigJSON {
+			t.Errorf("response %d: decoded body mismatch", i)
+		}
+	}
+
+	// A replay to a client without gzip gets the plain body and original ETag
+	plain := post("")
+	if plain.Header().Get("Content-Encoding") != "" || plain.Header().Get("ETag") != `"v1"` || plain.Body.String() != bigJSON {
+		t.Errorf("Expected uncompressed replay, got %v", plain.Header())
+	}
+	if plain.Header().Get(IdempotentReplayedHeader) != "true" || calls.Load() != 1 {
+		t.Errorf("Expected replays, handler ran %d times", calls.Load())
+	}
+}
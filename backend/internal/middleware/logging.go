@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/logging"
+)
+
+// RequestIDHeader carries the correlation ID in both directions
+const RequestIDHeader = "X-Request-ID"
+
+// Gin context keys shared by the middleware in this package
+const (
+	requestIDKey = "requestID"
+	userIDKey    = "userID"
+)
+
+// validRequestID limits propagated IDs to something safe to log and echo
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID propagates a well-formed X-Request-ID from the client or
+// generates a new one, and exposes it on the response, the gin context
+// and the request context
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+
+		c.Set(requestIDKey, id)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// GetRequestID returns the ID assigned by the RequestID middleware
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// RequestLogger stores a logger tagged with the request ID in the request
+// context (see logging.FromContext) and writes one line per request.
+// Headers, query strings and bodies are never logged.
+func RequestLogger(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		reqLogger := logger.With(slog.String("request_id", GetRequestID(c)))
+		c.Request = c.Request.WithContext(logging.NewContext(c.Request.Context(), reqLogger))
+
+		c.Next()
+
+		status := c.Writer.Status()
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", route),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", max(c.Writer.Size(), 0)),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if userID, ok := c.Get(userIDKey); ok {
+			attrs = append(attrs, slog.Any("user_id", userID))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", c.Errors.Last().Error()))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+		reqLogger.LogAttrs(c.Request.Context(), level, "request", attrs...)
+	}
+}
+
+// LoggerFrom returns the request-scoped logger for handlers
+func LoggerFrom(c *gin.Context) *slog.Logger {
+	return logging.FromContext(c.Request.Context())
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/logging"
+)
+
+func TestRequestIDPropagation(t *testing.T) {
+	router := gin.New()
+	router.Use(RequestID())
+	router.GET("/id", func(c *gin.Context) {
+		c.String(http.StatusOK, logging.RequestID(c.Request.Context()))
+	})
+
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{"propagates client ID", "abc-123", true},
+		{"generates when missing", "", false},
+		{"replaces malformed ID", "bad id\nwith newline", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/id", nil)
+			if tt.incoming != "" {
+				req.Header.Set(RequestIDHeader, tt.incoming)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			got := w.Header().Get(RequestIDHeader)
+			if tt.keep && got != tt.incoming {
+				t.Errorf("Expected %q to be propagated, got %q", tt.incoming, got)
+			}
+			if !tt.keep && (got == "" || got == tt.incoming) {
+				t.Errorf("Expected a generated ID, got %q", got)
+			}
+			if w.Body.String() != got {
+				t.Errorf("Expected request context to carry %q, got %q", got, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRequestLoggerWritesOneLine(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	router := gin.New()
+	router.Use(RequestID(), RequestLogger(logger))
+	router.POST("/users/:id", func(c *gin.Context) {
+		c.Set(userIDKey, 42)
+		LoggerFrom(c).Info("handler")
+		c.String(http.StatusCreated, "created")
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/users/7?token=secret", strings.NewReader(`{"password":"hunter2"}`))
+	req.Header.Set("Authorization", "Bearer secret-token")
+	req.Header.Set(RequestIDHeader, "req-1")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected handler line and request line, got %d: %s", len(lines), buf.String())
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+		t.Fatalf("Expected JSON log line: %v", err)
+	}
+	want := map[string]any{
+		"request_id": "req-1",
+		"route":      "/users/:id",
+		"path":       "/users/7",
+		"status":     float64(201),
+		"bytes":      float64(7),
+		"user_id":    float64(42),
+	}
+	for k, v := range want {
+		if entry[k] != v {
+			t.Errorf("Expected %s=%v, got %v", k, v, entry[k])
+		}
+	}
+
+	for _, leaked := range []string{"secret-token", "hunter2", "token=secret"} {
+		if strings.Contains(buf.String(), leaked) {
+			t.Errorf("Log output leaked %q: %s", leaked, buf.String())
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/ratelimit"
+)
+
+func TestRateLimit(t *testing.T) {
+	store := ratelimit.NewMemoryStore(0)
+	defer store.Close()
+
+	router := gin.New()
+	router.Use(RateLimit(RateLimitOptions{Name: "api", Limit: ratelimit.PerMinute(1, 2), Store: store}))
+	router.GET("/ping", func(c *gin.Context) { c.String(http.StatusOK, "pong") })
+
+	send := func(remoteAddr, apiKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		req.RemoteAddr = remoteAddr
+		if apiKey != "" {
+			req.Header.Set(APIKeyHeader, apiKey)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		w := send("10.0.0.1:1234", "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected request %d to pass, got %d", i+1, w.Code)
+		}
+		if w.Header().Get("RateLimit-Limit") != "2" {
+			t.Errorf("Expected RateLimit-Limit 2, got %q", w.Header().Get("RateLimit-Limit"))
+		}
+	}
+
+	w := send("10.0.0.1:1234", "")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "60" {
+		t.Errorf("Expected Retry-After 60, got %q", w.Header().Get("Retry-After"))
+	}
+	if w.Header().Get("RateLimit-Remaining") != "0" {
+		t.Errorf("Expected RateLimit-Remaining 0, got %q", w.Header().Get("RateLimit-Remaining"))
+	}
+
+	if w := send("10.0.0.2:1234", ""); w.Code != http.StatusOK {
+		t.Errorf("Expected another IP to have its own bucket, got %d", w.Code)
+	}
+	// Nothing authenticates X-API-Key, so a made-up key must not open a new bucket
+	if w := send("10.0.0.1:1234", "made-up"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected an unauthenticated API key to stay limited by IP, got %d", w.Code)
+	}
+}
+
+func TestRateLimitKeyByUser(t *testing.T) {
+	store := ratelimit.NewMemoryStore(0)
+	defer store.Close()
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if id := c.GetHeader("X-Test-User"); id != "" {
+			c.Set(userIDKey, id)
+		}
+	})
+	router.Use(RateLimit(RateLimitOptions{Name: "user", Limit: ratelimit.PerMinute(1, 1), Store: store, Key: KeyByUser}))
+	router.GET("/me", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	send := func(user string) int {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		if user != "" {
+			req.Header.Set("X-Test-User", user)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if send("1") != http.StatusOK || send("1") != http.StatusTooManyRequests {
+		t.Error("Expected second request for user 1 to be limited")
+	}
+	if send("2") != http.StatusOK {
+		t.Error("Expected user 2 to have its own bucket")
+	}
+	if send("") != http.StatusOK || send("") != http.StatusOK {
+		t.Error("Expected anonymous requests to skip a user-keyed limiter")
+	}
+}
@@ -0,0 +1,44 @@
+package migrate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const template = `-- +goose Up
+-- +goose StatementBegin
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+-- +goose StatementEnd
+`
+
+var nonWord = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Create writes an empty migration named "<timestamp>_<name>.sql" into dir
+// and returns its path. Timestamps follow the lab04 goose convention.
+func Create(dir, name string, now time.Time) (string, error) {
+	slug := strings.Trim(nonWord.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if slug == "" {
+		return "", fmt.Errorf("migrate: migration name %q has no usable characters", name)
+	}
+
+	path := filepath.Join(dir, now.UTC().Format("20060102150405")+"_"+slug+".sql")
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return "", fmt.Errorf("migrate: create %s: %w", path, err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(template); err != nil {
+		return "", fmt.Errorf("migrate: write %s: %w", path, err)
+	}
+	return path, nil
+}
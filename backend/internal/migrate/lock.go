@@ -0,0 +1,123 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/database"
+)
+
+// advisoryLockID is an arbitrary constant shared by every replica
+const advisoryLockID int64 = 7_250_825_017
+
+// lock makes sure only one process migrates at a time. Postgres uses a
+// session advisory lock, released even if the process dies; SQLite uses a
+// single-row lock table whose row is taken over once it is older than
+// StaleLockAfter. Both wait up to LockTimeout before giving up.
+func (m *Migrator) lock(ctx context.Context) (unlock func(), err error) {
+	ctx, cancel := context.WithTimeout(ctx, m.LockTimeout)
+	defer cancel()
+
+	if m.db.Dialect == database.Postgres {
+		return m.lockAdvisory(ctx)
+	}
+	return m.lockTable(ctx)
+}
+
+func (m *Migrator) lockAdvisory(ctx context.Context) (func(), error) {
+	// The lock belongs to the session, so keep one connection for its lifetime
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("migrate: lock connection: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", advisoryLockID); err != nil {
+		conn.Close()
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("migrate: %w", ErrLocked)
+		}
+		return nil, fmt.Errorf("migrate: acquire lock: %w", err)
+	}
+
+	return func() {
+		conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", advisoryLockID)
+		conn.Close()
+	}, nil
+}
+
+func (m *Migrator) lockTable(ctx context.Context) (func(), error) {
+	owner := lockOwner()
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		_, err := m.db.ExecContext(ctx, m.db.Rebind(
+			"INSERT INTO schema_migrations_lock (id, locked_by, locked_at) VALUES (1, ?, ?)"),
+			owner, time.Now().UTC())
+		if err == nil {
+			break
+		}
+		if m.clearStaleLock(ctx) {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			var holder string
+			m.db.QueryRowContext(context.Background(),
+				"SELECT locked_by FROM schema_migrations_lock WHERE id = 1").Scan(&holder)
+			return nil, fmt.Errorf("migrate: %w (held by %q)", ErrLocked, holder)
+		case <-ticker.C:
+		}
+	}
+
+	return func() {
+		m.db.ExecContext(context.Background(), m.db.Rebind(
+			"DELETE FROM schema_migrations_lock WHERE id = 1 AND locked_by = ?"), owner)
+	}, nil
+}
+
+// clearStaleLock deletes the lock row when it outlived StaleLockAfter and
+// reports whether it did
+func (m *Migrator) clearStaleLock(ctx context.Context) bool {
+	if m.StaleLockAfter <= 0 {
+		return false
+	}
+	res, err := m.db.ExecContext(ctx, m.db.Rebind(
+		"DELETE FROM schema_migrations_lock WHERE id = 1 AND locked_at < ?"),
+		time.Now().UTC().Add(-m.StaleLockAfter))
+	if err != nil {
+		return false
+	}
+	n, err := res.RowsAffected()
+	return err == nil && n > 0
+}
+
+// ForceUnlock removes the SQLite lock row left behind by a migrator that
+// died and reports whether there was one. Postgres advisory locks end with
+// their session, so there is never anything to remove there.
+func (m *Migrator) ForceUnlock(ctx context.Context) (bool, error) {
+	if m.db.Dialect == database.Postgres {
+		return false, nil
+	}
+	exists, err := m.tableExists(ctx, "schema_migrations_lock")
+	if err != nil || !exists {
+		return false, err
+	}
+	res, err := m.db.ExecContext(ctx, "DELETE FROM schema_migrations_lock WHERE id = 1")
+	if err != nil {
+		return false, fmt.Errorf("migrate: force unlock: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("migrate: force unlock: %w", err)
+	}
+	return n > 0, nil
+}
+
+func lockOwner() string {
+	host, _ := os.Hostname()
+	return host + ":" + strconv.Itoa(os.Getpid()) + ":" + strconv.FormatInt(time.Now().UnixNano(), 36)
+}
@@ -0,0 +1,352 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/database"
+)
+
+// Errors returned by the Migrator
+var (
+	ErrChecksumMismatch = errors.New("applied migration was modified")
+	ErrMissingMigration = errors.New("applied migration has no file")
+	ErrNoDownMigration  = errors.New("migration has no down section")
+	ErrUnknownVersion   = errors.New("unknown migration version")
+	ErrLocked           = errors.New("migrations are locked by another process")
+)
+
+// Status describes one migration as known to the files and the database
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+	// Modified is set when the file no longer matches the recorded checksum
+	Modified bool
+	// Missing is set when the version is recorded but its file is gone
+	Missing bool
+}
+
+// Migrator applies migrations to a database, recording each applied
+// version with its checksum in the schema_migrations table
+type Migrator struct {
+	db          *database.DB
+	migrations  []Migration
+	LockTimeout time.Duration
+	// StaleLockAfter is how old a SQLite lock may get before another
+	// migrator takes it over, assuming its owner crashed; zero never does.
+	// It must be longer than the slowest migration.
+	StaleLockAfter time.Duration
+}
+
+type appliedRecord struct {
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+// New loads the migrations in source for the database's dialect
+func New(db *database.DB, source fs.FS) (*Migrator, error) {
+	migrations, err := Load(source, db.Dialect)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations, LockTimeout: 30 * time.Second, StaleLockAfter: 15 * time.Minute}, nil
+}
+
+// Migrations returns the loaded migrations in version order
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+// Up applies every pending migration in version order
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(applied map[int64]appliedRecord) error {
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+			if err := m.apply(ctx, mig); err != nil {
+				return err
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down rolls back the n most recently applied migrations
+func (m *Migrator) Down(ctx context.Context, n int) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(applied map[int64]appliedRecord) error {
+		for i := len(m.migrations) - 1; i >= 0 && len(done) < n; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+			if err := m.revert(ctx, mig); err != nil {
+				return err
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// To migrates up or down so that exactly the migrations up to and
+// including version are applied. Version 0 rolls everything back.
+func (m *Migrator) To(ctx context.Context, version int64) ([]Migration, error) {
+	if version != 0 && m.find(version) < 0 {
+		return nil, fmt.Errorf("migrate: %w: %d", ErrUnknownVersion, version)
+	}
+
+	var done []Migration
+	err := m.withLock(ctx, func(applied map[int64]appliedRecord) error {
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; !ok || mig.Version <= version {
+				continue
+			}
+			if err := m.revert(ctx, mig); err != nil {
+				return err
+			}
+			done = append(done, mig)
+		}
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; ok || mig.Version > version {
+				continue
+			}
+			if err := m.apply(ctx, mig); err != nil {
+				return err
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Redo rolls back the most recently applied migration and applies it again
+func (m *Migrator) Redo(ctx context.Context) (*Migration, error) {
+	var redone *Migration
+	err := m.withLock(ctx, func(applied map[int64]appliedRecord) error {
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+			if err := m.revert(ctx, mig); err != nil {
+				return err
+			}
+			if err := m.apply(ctx, mig); err != nil {
+				return err
+			}
+			redone = &mig
+			return nil
+		}
+		return nil
+	})
+	return redone, err
+}
+
+// Status lists every known migration, including applied versions whose files are missing
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	if err := m.ensureTables(ctx); err != nil {
+		return nil, err
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		st := Status{Version: mig.Version, Name: mig.Name}
+		if rec, ok := applied[mig.Version]; ok {
+			st.Applied = true
+			st.AppliedAt = rec.appliedAt
+			st.Modified = rec.checksum != mig.Checksum
+			delete(applied, mig.Version)
+		}
+		statuses = append(statuses, st)
+	}
+	for version, rec := range applied {
+		statuses = append(statuses, Status{
+			Version: version, Name: rec.name, Applied: true, AppliedAt: rec.appliedAt, Missing: true,
+		})
+	}
+	return statuses, nil
+}
+
+// Version returns the highest applied version, or 0 when nothing is applied.
+// It only reads, so readiness probes can call it: a database that was never
+// migrated has no bookkeeping table and is at version 0.
+func (m *Migrator) Version(ctx context.Context) (int64, error) {
+	exists, err := m.tableExists(ctx, "schema_migrations")
+	if err != nil || !exists {
+		return 0, err
+	}
+	var version sql.NullInt64
+	err = m.db.QueryRowContext(ctx, "SELECT MAX(version) FROM schema_migrations").Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("migrate: read version: %w", err)
+	}
+	return version.Int64, nil
+}
+
+// Latest returns the highest version among the loaded migrations
+func (m *Migrator) Latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// withLock takes the migration lock, verifies applied checksums and runs fn
+func (m *Migrator) withLock(ctx context.Context, fn func(applied map[int64]appliedRecord) error) error {
+	if err := m.ensureTables(ctx); err != nil {
+		return err
+	}
+
+	unlock, err := m.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+	if err := m.verify(applied); err != nil {
+		return err
+	}
+	return fn(applied)
+}
+
+// verify refuses to run when applied migrations were edited or removed
+func (m *Migrator) verify(applied map[int64]appliedRecord) error {
+	var errs []error
+	for version, rec := range applied {
+		i := m.find(version)
+		switch {
+		case i < 0:
+			errs = append(errs, fmt.Errorf("%w: %d_%s", ErrMissingMigration, version, rec.name))
+		case m.migrations[i].Checksum != rec.checksum:
+			errs = append(errs, fmt.Errorf("%w: %d_%s", ErrChecksumMismatch, version, rec.name))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("migrate: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func (m *Migrator) apply(ctx context.Context, mig Migration) error {
+	return m.inTx(ctx, mig, mig.Up, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, m.db.Rebind(
+			"INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)"),
+			mig.Version, mig.Name, mig.Checksum, time.Now().UTC())
+		return err
+	})
+}
+
+func (m *Migrator) revert(ctx context.Context, mig Migration) error {
+	if mig.Down == "" {
+		return fmt.Errorf("migrate: %w: %d_%s", ErrNoDownMigration, mig.Version, mig.Name)
+	}
+	return m.inTx(ctx, mig, mig.Down, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, m.db.Rebind("DELETE FROM schema_migrations WHERE version = ?"), mig.Version)
+		return err
+	})
+}
+
+// inTx runs the migration SQL and the bookkeeping statement in one transaction
+func (m *Migrator) inTx(ctx context.Context, mig Migration, query string, record func(*sql.Tx) error) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("migrate: begin %d_%s: %w", mig.Version, mig.Name, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("migrate: %d_%s: %w", mig.Version, mig.Name, err)
+	}
+	if err := record(tx); err != nil {
+		return fmt.Errorf("migrate: record %d_%s: %w", mig.Version, mig.Name, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("migrate: commit %d_%s: %w", mig.Version, mig.Name, err)
+	}
+	return nil
+}
+
+func (m *Migrator) ensureTables(ctx context.Context) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			checksum VARCHAR(64) NOT NULL,
+			applied_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS schema_migrations_lock (
+			id INTEGER PRIMARY KEY,
+			locked_by VARCHAR(255) NOT NULL,
+			locked_at TIMESTAMP NOT NULL
+		)`,
+	}
+	for _, stmt := range stmts {
+		if _, err := m.db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("migrate: create bookkeeping tables: %w", err)
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) tableExists(ctx context.Context, name string) (bool, error) {
+	query := "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?"
+	if m.db.Dialect == database.Postgres {
+		query = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = ?"
+	}
+	var count int
+	if err := m.db.QueryRowContext(ctx, m.db.Rebind(query), name).Scan(&count); err != nil {
+		return false, fmt.Errorf("migrate: look up %s: %w", name, err)
+	}
+	return count > 0, nil
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[int64]appliedRecord, error) {
+	rows, err := m.db.QueryContext(ctx, "SELECT version, name, checksum, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("migrate: read applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]appliedRecord)
+	for rows.Next() {
+		var version int64
+		var rec appliedRecord
+		if err := rows.Scan(&version, &rec.name, &rec.checksum, &rec.appliedAt); err != nil {
+			return nil, fmt.Errorf("migrate: scan applied migration: %w", err)
+		}
+		applied[version] = rec
+	}
+	return applied, rows.Err()
+}
+
+func (m *Migrator) find(version int64) int {
+	for i, mig := range m.migrations {
+		if mig.Version == version {
+			return i
+		}
+	}
+	return -1
+}
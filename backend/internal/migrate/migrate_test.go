@@ -0,0 +1,361 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/database"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/migrations"
+)
+
+func testSource() fstest.MapFS {
+	return fstest.MapFS{
+		"001_create_notes.sql": {Data: []byte(`-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE notes (id INTEGER PRIMARY KEY, body TEXT);
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE notes;
+`)},
+		"002_add_tags.sql": {Data: []byte(`-- +goose Up
+CREATE TABLE tags (id INTEGER PRIMARY KEY, name TEXT);
+CREATE INDEX idx_tags_name ON tags(name);
+
+-- +goose Down
+DROP INDEX idx_tags_name;
+DROP TABLE tags;
+`)},
+		"003_seed.postgres.sql": {Data: []byte("-- +goose Up\nSELECT pg_sleep(0);\n")},
+		"003_seed.sqlite3.sql": {Data: []byte(`-- +goose Up
+INSERT INTO tags (name) VALUES ('health');
+-- +goose Down
+DELETE FROM tags;
+`)},
+		"README.md": {Data: []byte("ignored")},
+	}
+}
+
+func openTestDB(t *testing.T) *database.DB {
+	t.Helper()
+	db, err := database.OpenURL("sqlite://" + filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func newTestMigrator(t *testing.T, db *database.DB, source fstest.MapFS) *Migrator {
+	t.Helper()
+	m, err := New(db, source)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	m.LockTimeout = 300 * time.Millisecond
+	return m
+}
+
+func tableExists(t *testing.T, db *database.DB, name string) bool {
+	t.Helper()
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&count)
+	if err != nil {
+		t.Fatalf("Failed to query sqlite_master: %v", err)
+	}
+	return count == 1
+}
+
+func TestLoadPicksDialectFiles(t *testing.T) {
+	migs, err := Load(testSource(), database.SQLite)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if len(migs) != 3 {
+		t.Fatalf("Expected 3 migrations, got %d", len(migs))
+	}
+	if !strings.Contains(migs[2].Up, "INSERT INTO tags") {
+		t.Errorf("Expected sqlite3 variant for version 3, got %q", migs[2].Up)
+	}
+	if strings.Contains(migs[0].Up, "+goose") {
+		t.Errorf("Expected goose annotations to be stripped, got %q", migs[0].Up)
+	}
+
+	pg, err := Load(testSource(), database.Postgres)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if !strings.Contains(pg[2].Up, "pg_sleep") {
+		t.Errorf("Expected postgres variant for version 3, got %q", pg[2].Up)
+	}
+}
+
+func TestLoadRejectsBadFiles(t *testing.T) {
+	tests := []struct {
+		name   string
+		source fstest.MapFS
+	}{
+		{"missing up section", fstest.MapFS{"001_x.sql": {Data: []byte("CREATE TABLE x (id INT);")}}},
+		{"duplicate version", fstest.MapFS{
+			"001_a.sql": {Data: []byte("-- +goose Up\nSELECT 1;")},
+			"001_b.sql": {Data: []byte("-- +goose Up\nSELECT 1;")},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Load(tt.source, database.SQLite); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestUpDownStatus(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	m := newTestMigrator(t, db, testSource())
+
+	applied, err := m.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up returned error: %v", err)
+	}
+	if len(applied) != 3 {
+		t.Errorf("Expected 3 applied, got %d", len(applied))
+	}
+	if !tableExists(t, db, "notes") || !tableExists(t, db, "tags") {
+		t.Error("Expected notes and tags tables after Up")
+	}
+
+	// Running again is a no-op
+	if applied, err = m.Up(ctx); err != nil || len(applied) != 0 {
+		t.Errorf("Expected second Up to apply nothing, got %d, %v", len(applied), err)
+	}
+
+	reverted, err := m.Down(ctx, 2)
+	if err != nil {
+		t.Fatalf("Down returned error: %v", err)
+	}
+	if len(reverted) != 2 || reverted[0].Version != 3 || reverted[1].Version != 2 {
+		t.Errorf("Expected versions 3 and 2 reverted, got %+v", reverted)
+	}
+	if tableExists(t, db, "tags") {
+		t.Error("Expected tags table to be dropped")
+	}
+
+	version, err := m.Version(ctx)
+	if err != nil || version != 1 {
+		t.Errorf("Expected version 1, got %d, %v", version, err)
+	}
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status returned error: %v", err)
+	}
+	if !statuses[0].Applied || statuses[1].Applied || statuses[2].Applied {
+		t.Errorf("Unexpected status: %+v", statuses)
+	}
+}
+
+func TestVersionDoesNotCreateTables(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	m := newTestMigrator(t, db, testSource())
+
+	version, err := m.Version(ctx)
+	if err != nil || version != 0 {
+		t.Errorf("Expected version 0 on a fresh database, got %d, %v", version, err)
+	}
+	if tableExists(t, db, "schema_migrations") || tableExists(t, db, "schema_migrations_lock") {
+		t.Error("Expected Version to leave the schema untouched")
+	}
+}
+
+func TestToAndRedo(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	m := newTestMigrator(t, db, testSource())
+
+	if _, err := m.To(ctx, 2); err != nil {
+		t.Fatalf("To(2) returned error: %v", err)
+	}
+	if version, _ := m.Version(ctx); version != 2 {
+		t.Errorf("Expected version 2, got %d", version)
+	}
+
+	redone, err := m.Redo(ctx)
+	if err != nil || redone == nil || redone.Version != 2 {
+		t.Fatalf("Expected version 2 redone, got %+v, %v", redone, err)
+	}
+
+	if _, err := m.To(ctx, 0); err != nil {
+		t.Fatalf("To(0) returned error: %v", err)
+	}
+	if tableExists(t, db, "notes") {
+		t.Error("Expected everything rolled back")
+	}
+
+	if _, err := m.To(ctx, 42); !errors.Is(err, ErrUnknownVersion) {
+		t.Errorf("Expected ErrUnknownVersion, got %v", err)
+	}
+}
+
+func TestChecksumMismatch(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	source := testSource()
+
+	if _, err := newTestMigrator(t, db, source).Up(ctx); err != nil {
+		t.Fatalf("Up returned error: %v", err)
+	}
+
+	source["001_create_notes.sql"] = &fstest.MapFile{Data: []byte("-- +goose Up\nCREATE TABLE notes (id INTEGER);\n")}
+	m := newTestMigrator(t, db, source)
+
+	if _, err := m.Up(ctx); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("Expected ErrChecksumMismatch, got %v", err)
+	}
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status returned error: %v", err)
+	}
+	if !statuses[0].Modified {
+		t.Error("Expected first migration to be reported as modified")
+	}
+}
+
+func TestFailedMigrationRollsBack(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	source := fstest.MapFS{
+		"001_broken.sql": {Data: []byte("-- +goose Up\nCREATE TABLE half (id INTEGER);\nINSERT INTO nowhere VALUES (1);\n")},
+	}
+	m := newTestMigrator(t, db, source)
+
+	if _, err := m.Up(ctx); err == nil {
+		t.Fatal("Expected error from broken migration")
+	}
+	if tableExists(t, db, "half") {
+		t.Error("Expected transaction to roll back partial changes")
+	}
+	if version, _ := m.Version(ctx); version != 0 {
+		t.Errorf("Expected nothing recorded, got version %d", version)
+	}
+}
+
+func TestLockBlocksSecondMigrator(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	m := newTestMigrator(t, db, testSource())
+	if err := m.ensureTables(ctx); err != nil {
+		t.Fatalf("ensureTables returned error: %v", err)
+	}
+
+	unlock, err := m.lock(ctx)
+	if err != nil {
+		t.Fatalf("lock returned error: %v", err)
+	}
+
+	if _, err := newTestMigrator(t, db, testSource()).Up(ctx); !errors.Is(err, ErrLocked) {
+		t.Errorf("Expected ErrLocked while lock is held, got %v", err)
+	}
+
+	unlock()
+	if _, err := m.Up(ctx); err != nil {
+		t.Errorf("Expected Up to succeed after unlock, got %v", err)
+	}
+}
+
+// holdLock leaves a lock row behind as if a migrator crashed at lockedAt
+func holdLock(t *testing.T, m *Migrator, lockedAt time.Time) {
+	t.Helper()
+	ctx := context.Background()
+	if err := m.ensureTables(ctx); err != nil {
+		t.Fatalf("ensureTables returned error: %v", err)
+	}
+	_, err := m.db.ExecContext(ctx,
+		"INSERT INTO schema_migrations_lock (id, locked_by, locked_at) VALUES (1, 'crashed', ?)", lockedAt.UTC())
+	if err != nil {
+		t.Fatalf("Failed to insert lock row: %v", err)
+	}
+}
+
+func TestStaleLockIsTakenOver(t *testing.T) {
+	ctx := context.Background()
+	m := newTestMigrator(t, openTestDB(t), testSource())
+	m.StaleLockAfter = time.Minute
+
+	holdLock(t, m, time.Now().Add(-time.Hour))
+	if _, err := m.Up(ctx); err != nil {
+		t.Errorf("Expected a stale lock to be taken over, got %v", err)
+	}
+
+	m.StaleLockAfter = 0
+	holdLock(t, m, time.Now().Add(-time.Hour))
+	if _, err := m.Down(ctx, 1); !errors.Is(err, ErrLocked) {
+		t.Errorf("Expected ErrLocked with takeover disabled, got %v", err)
+	}
+}
+
+func TestForceUnlock(t *testing.T) {
+	ctx := context.Background()
+	m := newTestMigrator(t, openTestDB(t), testSource())
+
+	if cleared, err := m.ForceUnlock(ctx); err != nil || cleared {
+		t.Errorf("Expected nothing to clear on a fresh database, got %v, %v", cleared, err)
+	}
+
+	holdLock(t, m, time.Now())
+	if _, err := m.Up(ctx); !errors.Is(err, ErrLocked) {
+		t.Fatalf("Expected ErrLocked while the lock is held, got %v", err)
+	}
+	if cleared, err := m.ForceUnlock(ctx); err != nil || !cleared {
+		t.Fatalf("Expected the lock to be cleared, got %v, %v", cleared, err)
+	}
+	if _, err := m.Up(ctx); err != nil {
+		t.Errorf("Expected Up to succeed after ForceUnlock, got %v", err)
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+
+	m, err := New(db, migrations.FS)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("Up returned error: %v", err)
+	}
+	if _, err := m.To(ctx, 0); err != nil {
+		t.Fatalf("Rolling back embedded migrations returned error: %v", err)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2025, 8, 1, 9, 30, 0, 0, time.UTC)
+
+	path, err := Create(dir, "Add Water Logs", now)
+	if err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	if filepath.Base(path) != "20250801093000_add_water_logs.sql" {
+		t.Errorf("Unexpected file name %s", filepath.Base(path))
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "-- +goose Up") {
+		t.Error("Expected goose template in new migration")
+	}
+
+	if _, err := Create(dir, "Add Water Logs", now); err == nil {
+		t.Error("Expected error when file already exists")
+	}
+}
@@ -0,0 +1,131 @@
+package migrate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/database"
+)
+
+// Migration is a single versioned schema change
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// fileName matches "<version>_<name>.sql" and the dialect-specific
+// "<version>_<name>.<dialect>.sql" (dialect is postgres or sqlite3)
+var fileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+?)(?:\.(postgres|sqlite3))?\.sql$`)
+
+// Load reads migrations from the root of source for the given dialect.
+// Files use the goose layout from lab04: "-- +goose Up" and "-- +goose Down"
+// sections. A dialect-specific file replaces the generic one for its dialect.
+func Load(source fs.FS, dialect database.Dialect) ([]Migration, error) {
+	entries, err := fs.ReadDir(source, ".")
+	if err != nil {
+		return nil, fmt.Errorf("migrate: read migrations: %w", err)
+	}
+
+	type candidate struct {
+		file     string
+		specific bool
+	}
+	chosen := make(map[int64]candidate)
+	names := make(map[int64]string)
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		if match[3] != "" && match[3] != string(dialect) {
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migrate: bad version in %s: %w", entry.Name(), err)
+		}
+		if name, ok := names[version]; ok && name != match[2] {
+			return nil, fmt.Errorf("migrate: version %d used by both %q and %q", version, name, match[2])
+		}
+		names[version] = match[2]
+
+		specific := match[3] != ""
+		if prev, ok := chosen[version]; ok && prev.specific == specific {
+			return nil, fmt.Errorf("migrate: duplicate migration files for version %d", version)
+		}
+		if prev, ok := chosen[version]; !ok || specific && !prev.specific {
+			chosen[version] = candidate{file: entry.Name(), specific: specific}
+		}
+	}
+
+	migrations := make([]Migration, 0, len(chosen))
+	for version, c := range chosen {
+		data, err := fs.ReadFile(source, c.file)
+		if err != nil {
+			return nil, fmt.Errorf("migrate: read %s: %w", c.file, err)
+		}
+		up, down, err := parse(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("migrate: %s: %w", c.file, err)
+		}
+
+		sum := sha256.Sum256(data)
+		migrations = append(migrations, Migration{
+			Version:  version,
+			Name:     names[version],
+			Up:       up,
+			Down:     down,
+			Checksum: hex.EncodeToString(sum[:]),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// parse splits a migration file into its Up and Down SQL
+func parse(content string) (up, down string, err error) {
+	var upLines, downLines []string
+	var section *[]string
+
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "-- +goose Up"):
+			section = &upLines
+			continue
+		case strings.HasPrefix(trimmed, "-- +goose Down"):
+			section = &downLines
+			continue
+		case strings.HasPrefix(trimmed, "-- +goose"):
+			// StatementBegin/End only matter to goose's statement splitter
+			continue
+		}
+		if section != nil {
+			*section = append(*section, line)
+		}
+	}
+
+	up = strings.TrimSpace(strings.Join(upLines, "\n"))
+	down = strings.TrimSpace(strings.Join(downLines, "\n"))
+	if up == "" {
+		return "", "", fmt.Errorf("missing -- +goose Up section")
+	}
+	return up, down, nil
+}
@@ -0,0 +1,92 @@
+package openapi
+
+import (
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Group registers routes on a gin router group and documents them in the
+// spec at the same time, so the two cannot drift apart
+type Group struct {
+	rg      *gin.RouterGroup
+	spec    *Spec
+	tags    []string
+	secured bool
+}
+
+// Group wraps rg, whose base path must start with the spec's base path
+func (s *Spec) Group(rg *gin.RouterGroup) *Group {
+	return &Group{rg: rg, spec: s}
+}
+
+// Group creates a sub-group that inherits tags and security
+func (g *Group) Group(relativePath string, handlers ...gin.HandlerFunc) *Group {
+	return &Group{
+		rg:      g.rg.Group(relativePath, handlers...),
+		spec:    g.spec,
+		tags:    g.tags,
+		secured: g.secured,
+	}
+}
+
+// Use adds middleware to the underlying gin group
+func (g *Group) Use(middleware ...gin.HandlerFunc) *Group {
+	g.rg.Use(middleware...)
+	return g
+}
+
+// Tags sets the default tags for operations registered on this group
+func (g *Group) Tags(tags ...string) *Group {
+	g.tags = tags
+	return g
+}
+
+// Secured marks operations on this group as requiring a bearer token
+func (g *Group) Secured() *Group {
+	g.secured = true
+	return g
+}
+
+// RouterGroup exposes the underlying gin group
+func (g *Group) RouterGroup() *gin.RouterGroup {
+	return g.rg
+}
+
+// Handle registers handlers for method and path and documents op
+func (g *Group) Handle(method, relativePath string, op Operation, handlers ...gin.HandlerFunc) {
+	g.rg.Handle(method, relativePath, handlers...)
+
+	if len(op.Tags) == 0 {
+		op.Tags = g.tags
+	}
+	full := path.Join(g.rg.BasePath(), relativePath)
+	g.spec.Add(method, strings.TrimPrefix(full, g.spec.BasePath()), op, g.secured)
+}
+
+// GET is a shortcut for Handle(http.MethodGet, ...)
+func (g *Group) GET(relativePath string, op Operation, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodGet, relativePath, op, handlers...)
+}
+
+// POST is a shortcut for Handle(http.MethodPost, ...)
+func (g *Group) POST(relativePath string, op Operation, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodPost, relativePath, op, handlers...)
+}
+
+// PUT is a shortcut for Handle(http.MethodPut, ...)
+func (g *Group) PUT(relativePath string, op Operation, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodPut, relativePath, op, handlers...)
+}
+
+// PATCH is a shortcut for Handle(http.MethodPatch, ...)
+func (g *Group) PATCH(relativePath string, op Operation, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodPatch, relativePath, op, handlers...)
+}
+
+// DELETE is a shortcut for Handle(http.MethodDelete, ...)
+func (g *Group) DELETE(relativePath string, op Operation, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodDelete, relativePath, op, handlers...)
+}
@@ -0,0 +1,59 @@
+package openapi
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// The page, its init script and the vendored swagger-ui-dist files in
+// ui/dist (scripts/vendor-swagger-ui.sh) are all embedded in the binary, so
+// the docs work offline and load no third-party code at runtime
+//
+//go:embed ui
+var uiFiles embed.FS
+
+var uiTemplate = template.Must(template.ParseFS(uiFiles, "ui/index.html"))
+
+// JSONHandler serves the document as JSON
+func (s *Spec) JSONHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, s.Document())
+	}
+}
+
+// UIHandler serves Swagger UI pointed at specURL, loading its scripts and
+// styles from assetsURL, where AssetsHandler is mounted
+func (s *Spec) UIHandler(specURL, assetsURL string) gin.HandlerFunc {
+	var page bytes.Buffer
+	uiTemplate.Execute(&page, struct{ Title, SpecURL, AssetsURL string }{s.doc.Info.Title, specURL, assetsURL})
+	body := page.Bytes()
+
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", body)
+	}
+}
+
+// AssetsHandler serves the embedded Swagger UI files; mount it on a route
+// ending in *file
+func AssetsHandler() gin.HandlerFunc {
+	assets, _ := fs.Sub(uiFiles, "ui")
+	server := http.FileServer(http.FS(assets))
+
+	return func(c *gin.Context) {
+		name := strings.TrimPrefix(c.Param("file"), "/")
+		if info, err := fs.Stat(assets, name); err != nil || info.IsDir() || name == "index.html" {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		c.Header("Cache-Control", "public, max-age=86400")
+		req := c.Request.Clone(c.Request.Context())
+		req.URL.Path = "/" + name
+		server.ServeHTTP(c.Writer, req)
+	}
+}
@@ -0,0 +1,132 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type address struct {
+	City string `json:"city"`
+}
+
+type createUserRequest struct {
+	Email    string   `json:"email" binding:"required,email"`
+	Name     string   `json:"name,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Address  *address `json:"address,omitempty"`
+	Internal string   `json:"-"`
+}
+
+type userResponse struct {
+	ID        int       `json:"id"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+	Manager   *userResponse
+}
+
+type listQuery struct {
+	Page  int    `form:"page"`
+	Query string `form:"q" binding:"required"`
+}
+
+func TestSpecFromRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	spec := NewSpec("Test API", "1.0", "/api/v1")
+	router := gin.New()
+	api := spec.Group(router.Group("/api/v1")).Tags("users")
+
+	noop := func(c *gin.Context) {}
+	api.POST("/users", Operation{
+		Summary:   "Create user",
+		Request:   createUserRequest{},
+		Responses: map[int]any{http.StatusCreated: userResponse{}, http.StatusConflict: nil},
+	}, noop)
+	api.Group("/users").Secured().GET("/:id", Operation{
+		Query:     listQuery{},
+		Responses: map[int]any{http.StatusOK: userResponse{}},
+	}, noop)
+
+	if !spec.Has("POST", "/users") || !spec.Has("GET", "/users/:id") {
+		t.Fatalf("Expected both operations to be documented, got %v", spec.Paths())
+	}
+	if len(router.Routes()) != 2 {
+		t.Errorf("Expected both routes registered on gin, got %d", len(router.Routes()))
+	}
+
+	doc := spec.Document()
+	get := (*doc.Paths["/users/{id}"])["get"]
+	if get.OperationID != "getUsersById" {
+		t.Errorf("Unexpected operation ID %q", get.OperationID)
+	}
+	if len(get.Security) != 1 || len(get.Parameters) != 3 {
+		t.Errorf("Expected security and path+query parameters, got %+v", get)
+	}
+	if post := (*doc.Paths["/users"])["post"]; post.Security != nil || post.Tags[0] != "users" {
+		t.Errorf("Expected unsecured, tagged create operation, got %+v", post)
+	}
+
+	req := doc.Components.Schemas["createUserRequest"]
+	if req == nil || len(req.Required) != 1 || req.Required[0] != "email" {
+		t.Fatalf("Expected only email to be required, got %+v", req)
+	}
+	if _, ok := req.Properties["Internal"]; ok {
+		t.Error("Expected json:\"-\" field to be skipped")
+	}
+	if req.Properties["address"].Ref != "#/components/schemas/address" {
+		t.Errorf("Expected nested struct reference, got %+v", req.Properties["address"])
+	}
+
+	resp := doc.Components.Schemas["userResponse"]
+	if resp.Properties["created_at"].Format != "date-time" {
+		t.Errorf("Expected time.Time as date-time, got %+v", resp.Properties["created_at"])
+	}
+	if resp.Properties["Manager"].Ref != "#/components/schemas/userResponse" {
+		t.Errorf("Expected self reference, got %+v", resp.Properties["Manager"])
+	}
+}
+
+func TestHandlers(t *testing.T) {
+	spec := NewSpec("Test API", "1.0", "/api/v1")
+	spec.Add("GET", "/ping", Operation{Responses: map[int]any{200: map[string]string{}}}, false)
+
+	router := gin.New()
+	router.GET("/openapi.json", spec.JSONHandler())
+	router.GET("/docs", spec.UIHandler("/openapi.json", "/docs/assets"))
+	router.GET("/docs/assets/*file", AssetsHandler())
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	var doc Document
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil || doc.OpenAPI != "3.0.3" {
+		t.Errorf("Expected OpenAPI JSON, got %v: %s", err, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	page := w.Body.String()
+	if !strings.Contains(page, `data-spec-url="/openapi.json"`) || !strings.Contains(page, `src="/docs/assets/dist/swagger-ui-bundle.js"`) {
+		t.Errorf("Expected Swagger UI page pointing at the spec, got %s", page)
+	}
+	if strings.Contains(page, "://") || strings.Contains(page, "<script>") {
+		t.Errorf("Expected only embedded scripts and no inline code, got %s", page)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs/assets/init.js", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "SwaggerUIBundle") {
+		t.Errorf("Expected the embedded init script, got %d: %s", w.Code, w.Body.String())
+	}
+	for _, path := range []string{"/docs/assets/", "/docs/assets/index.html", "/docs/assets/missing.js"} {
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404 for %s, got %d", path, w.Code)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Schema is a JSON Schema object as used by OpenAPI 3.0
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Example              any                `json:"example,omitempty"`
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemaRegistry turns Go types into schemas, storing named structs as components
+type schemaRegistry struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{
+		schemas: make(map[string]*Schema),
+		names:   make(map[reflect.Type]string),
+	}
+}
+
+func (r *schemaRegistry) schemaFor(v any) *Schema {
+	return r.schemaOf(reflect.TypeOf(v))
+}
+
+func (r *schemaRegistry) components() map[string]*Schema {
+	out := make(map[string]*Schema, len(r.schemas))
+	for name, s := range r.schemas {
+		out[name] = s
+	}
+	return out
+}
+
+func (r *schemaRegistry) schemaOf(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+
+	nullable := false
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		nullable = true
+	}
+
+	var s *Schema
+	switch {
+	case t == timeType:
+		s = &Schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		s = &Schema{}
+	default:
+		s = r.schemaOfKind(t)
+	}
+	if nullable && s.Ref == "" {
+		s.Nullable = true
+	}
+	return s
+}
+
+func (r *schemaRegistry) schemaOfKind(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: r.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + r.register(t)}
+	default:
+		// interface{} and friends accept anything
+		return &Schema{}
+	}
+}
+
+// register stores a named struct as a component and returns its name
+func (r *schemaRegistry) register(t reflect.Type) string {
+	if name, ok := r.names[t]; ok {
+		return name
+	}
+
+	name := t.Name()
+	if _, taken := r.schemas[name]; taken {
+		// Same type name in two packages: qualify the newcomer
+		pkg := t.PkgPath()
+		pkg = pkg[strings.LastIndex(pkg, "/")+1:]
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+
+	// Reserve the name before recursing so self-referencing types terminate
+	r.names[t] = name
+	r.schemas[name] = &Schema{}
+	*r.schemas[name] = *r.structSchema(t)
+	return name
+}
+
+func (r *schemaRegistry) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	r.addFields(s, t)
+	return s
+}
+
+func (r *schemaRegistry) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, omitempty, skip := jsonName(f)
+		if skip {
+			continue
+		}
+
+		// Embedded structs without a JSON name are flattened like encoding/json does
+		if f.Anonymous && f.Tag.Get("json") == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				r.addFields(s, ft)
+				continue
+			}
+		}
+
+		prop := r.schemaOf(f.Type)
+		if desc := f.Tag.Get("description"); desc != "" && prop.Ref == "" {
+			prop.Description = desc
+		}
+		if ex := f.Tag.Get("example"); ex != "" && prop.Ref == "" {
+			prop.Example = ex
+		}
+		s.Properties[name] = prop
+
+		if hasRule(f.Tag.Get("binding"), "required") || hasRule(f.Tag.Get("validate"), "required") ||
+			(!omitempty && f.Type.Kind() != reflect.Pointer && f.Tag.Get("binding") == "" && f.Tag.Get("validate") == "") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// queryParameters describes the `form`-tagged fields of a query struct
+func (r *schemaRegistry) queryParameters(v any) []Parameter {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var params []Parameter
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("form"), ",")
+		if !f.IsExported() || name == "" || name == "-" {
+			continue
+		}
+		params = append(params, Parameter{
+			Name:        name,
+			In:          "query",
+			Required:    hasRule(f.Tag.Get("binding"), "required"),
+			Description: f.Tag.Get("description"),
+			Schema:      r.schemaOf(f.Type),
+		})
+	}
+	return params
+}
+
+func jsonName(f reflect.StructField) (name string, omitempty, skip bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = f.Name
+	}
+	return name, strings.Contains(opts, "omitempty"), false
+}
+
+func hasRule(tag, rule string) bool {
+	for _, r := range strings.Split(tag, ",") {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
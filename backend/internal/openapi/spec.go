@@ -0,0 +1,252 @@
+// Package openapi builds an OpenAPI 3 document from gin route
+// registrations and the Go types used for request and response bodies.
+package openapi
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Document is the subset of the OpenAPI 3.0 object model the API uses
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Server is a base URL the paths are relative to
+type Server struct {
+	URL string `json:"url"`
+}
+
+// PathItem holds the operations of one path keyed by lowercase method
+type PathItem map[string]*OperationObject
+
+// OperationObject is a single documented endpoint
+type OperationObject struct {
+	Summary     string                    `json:"summary,omitempty"`
+	Description string                    `json:"description,omitempty"`
+	OperationID string                    `json:"operationId"`
+	Tags        []string                  `json:"tags,omitempty"`
+	Parameters  []Parameter               `json:"parameters,omitempty"`
+	RequestBody *RequestBody              `json:"requestBody,omitempty"`
+	Responses   map[string]ResponseObject `json:"responses"`
+	Security    []map[string][]string     `json:"security,omitempty"`
+}
+
+// Parameter is a path, query or header parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Required    bool    `json:"required"`
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody describes the JSON body of a request
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// ResponseObject describes one response status
+type ResponseObject struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType wraps the schema of a body
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds reusable schemas and security schemes
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme describes how requests authenticate
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// Operation is what a route registration tells the spec about an endpoint
+type Operation struct {
+	Summary     string
+	Description string
+	Tags        []string
+	// Request is a value of the JSON request body type, e.g. LoginRequest{}
+	Request any
+	// Query is a struct whose `form` tags describe query parameters
+	Query any
+	// Responses maps status codes to a value of the body type, or nil for no body
+	Responses map[int]any
+	// ContentTypes overrides the response media type per status. The default is
+	// the body's ContentType() method when it has one, else application/json.
+	ContentTypes map[int]string
+}
+
+// BearerAuth is the security scheme name used for JWT-protected operations
+const BearerAuth = "bearerAuth"
+
+// Spec accumulates operations and renders the Document
+type Spec struct {
+	mu      sync.Mutex
+	doc     Document
+	schemas *schemaRegistry
+}
+
+// NewSpec creates an empty spec whose paths are relative to basePath (e.g. "/api/v1")
+func NewSpec(title, version, basePath string) *Spec {
+	return &Spec{
+		doc: Document{
+			OpenAPI: "3.0.3",
+			Info:    Info{Title: title, Version: version},
+			Servers: []Server{{URL: basePath}},
+			Paths:   make(map[string]*PathItem),
+			Components: Components{
+				Schemas: make(map[string]*Schema),
+				SecuritySchemes: map[string]SecurityScheme{
+					BearerAuth: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+				},
+			},
+		},
+		schemas: newSchemaRegistry(),
+	}
+}
+
+// BasePath returns the server URL the paths are relative to
+func (s *Spec) BasePath() string {
+	return s.doc.Servers[0].URL
+}
+
+var ginParam = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+
+// Add documents an operation. path uses gin syntax relative to the base path
+// ("/users/:id"); secured marks the operation as requiring a bearer token.
+func (s *Spec) Add(method, path string, op Operation, secured bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	oasPath := ginParam.ReplaceAllString(path, "{$1}")
+	obj := &OperationObject{
+		Summary:     op.Summary,
+		Description: op.Description,
+		OperationID: operationID(method, path),
+		Tags:        op.Tags,
+		Responses:   make(map[string]ResponseObject),
+	}
+
+	for _, match := range ginParam.FindAllStringSubmatch(path, -1) {
+		obj.Parameters = append(obj.Parameters, Parameter{
+			Name: match[1], In: "path", Required: true, Schema: &Schema{Type: "string"},
+		})
+	}
+	if op.Query != nil {
+		obj.Parameters = append(obj.Parameters, s.schemas.queryParameters(op.Query)...)
+	}
+
+	if op.Request != nil {
+		obj.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{"application/json": {Schema: s.schemas.schemaFor(op.Request)}},
+		}
+	}
+
+	for status, body := range op.Responses {
+		resp := ResponseObject{Description: http.StatusText(status)}
+		if body != nil {
+			contentType := "application/json"
+			if typed, ok := body.(interface{ ContentType() string }); ok {
+				contentType = typed.ContentType()
+			}
+			if ct, ok := op.ContentTypes[status]; ok {
+				contentType = ct
+			}
+			resp.Content = map[string]MediaType{contentType: {Schema: s.schemas.schemaFor(body)}}
+		}
+		obj.Responses[strconv.Itoa(status)] = resp
+	}
+	if len(obj.Responses) == 0 {
+		obj.Responses["200"] = ResponseObject{Description: http.StatusText(http.StatusOK)}
+	}
+
+	if secured {
+		obj.Security = []map[string][]string{{BearerAuth: {}}}
+	}
+
+	item, ok := s.doc.Paths[oasPath]
+	if !ok {
+		item = &PathItem{}
+		s.doc.Paths[oasPath] = item
+	}
+	(*item)[strings.ToLower(method)] = obj
+}
+
+// Has reports whether method and gin-style path are documented
+func (s *Spec) Has(method, path string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.doc.Paths[ginParam.ReplaceAllString(path, "{$1}")]
+	if !ok {
+		return false
+	}
+	_, ok = (*item)[strings.ToLower(method)]
+	return ok
+}
+
+// Document returns a snapshot of the document with all referenced schemas
+func (s *Spec) Document() Document {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc := s.doc
+	doc.Components.Schemas = s.schemas.components()
+	return doc
+}
+
+// operationID derives a stable ID such as "getUsersById" for client generators
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '-' || r == '_' }) {
+		if strings.HasPrefix(part, ":") || strings.HasPrefix(part, "*") {
+			b.WriteString("By")
+			part = part[1:]
+		}
+		if part != "" {
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return b.String()
+}
+
+// Paths returns the documented paths in sorted order
+func (s *Spec) Paths() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	paths := make([]string, 0, len(s.doc.Paths))
+	for p := range s.doc.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
@@ -0,0 +1,9 @@
+// Kept out of index.html so the page works under a CSP without 'unsafe-inline'
+window.onload = () => {
+  const root = document.getElementById("swagger-ui");
+  window.ui = SwaggerUIBundle({
+    url: root.dataset.specUrl,
+    dom_id: "#swagger-ui",
+    persistAuthorization: true,
+  });
+};
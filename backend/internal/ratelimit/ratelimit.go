@@ -0,0 +1,142 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit is a token bucket: Burst tokens at most, refilled at Rate tokens per second
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// PerMinute allows n requests per minute with bursts of up to burst requests
+func PerMinute(n, burst int) Limit {
+	return Limit{Rate: float64(n) / 60, Burst: burst}
+}
+
+// PerSecond allows n requests per second with bursts of up to burst requests
+func PerSecond(n, burst int) Limit {
+	return Limit{Rate: float64(n), Burst: burst}
+}
+
+// Result describes the bucket after a Take
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// ResetAfter is how long until the bucket is full again
+	ResetAfter time.Duration
+	// RetryAfter is how long until the next token, set when not allowed
+	RetryAfter time.Duration
+}
+
+// Store keeps bucket state. Implementations must be safe for concurrent
+// use; a shared store (e.g. Redis) can replace MemoryStore across replicas.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	// expires is when the bucket would be full again, so forgetting it changes nothing
+	expires time.Time
+}
+
+// MemoryStore keeps buckets in process memory and drops full ones periodically
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	done    chan struct{}
+	once    sync.Once
+}
+
+// NewMemoryStore creates a store that sweeps expired buckets every cleanupInterval
+func NewMemoryStore(cleanupInterval time.Duration) *MemoryStore {
+	s := &MemoryStore{
+		buckets: make(map[string]*bucket),
+		done:    make(chan struct{}),
+	}
+	if cleanupInterval > 0 {
+		go s.sweepLoop(cleanupInterval)
+	}
+	return s
+}
+
+// Take removes one token from the bucket for key if one is available
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	burst := float64(limit.Burst)
+	b, ok := s.buckets[key]
+	if !ok || !now.Before(b.expires) {
+		b = &bucket{tokens: burst, last: now}
+		s.buckets[key] = b
+	}
+
+	// Refill for the time since the last request
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(burst, b.tokens+elapsed*limit.Rate)
+		b.last = now
+	}
+
+	res := Result{Limit: limit.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = secondsToDuration((1 - b.tokens) / limit.Rate)
+	}
+
+	res.Remaining = int(math.Floor(b.tokens))
+	res.ResetAfter = secondsToDuration((burst - b.tokens) / limit.Rate)
+	b.expires = now.Add(res.ResetAfter)
+	return res, nil
+}
+
+// Len returns the number of tracked buckets
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.buckets)
+}
+
+// Close stops the background sweeper
+func (s *MemoryStore) Close() {
+	s.once.Do(func() { close(s.done) })
+}
+
+func (s *MemoryStore) sweep(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, b := range s.buckets {
+		if !now.Before(b.expires) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func (s *MemoryStore) sweepLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			s.sweep(now)
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func secondsToDuration(s float64) time.Duration {
+	if math.IsInf(s, 0) || math.IsNaN(s) {
+		return 0
+	}
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreTokenBucket(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(0)
+	defer store.Close()
+
+	limit := PerSecond(1, 3)
+	now := time.Unix(1_700_000_000, 0)
+
+	for i := 0; i < 3; i++ {
+		res, _ := store.Take(ctx, "k", limit, now)
+		if !res.Allowed {
+			t.Fatalf("Expected request %d within burst to be allowed", i+1)
+		}
+		if res.Remaining != 2-i {
+			t.Errorf("Expected %d remaining, got %d", 2-i, res.Remaining)
+		}
+	}
+
+	res, _ := store.Take(ctx, "k", limit, now)
+	if res.Allowed {
+		t.Fatal("Expected request beyond burst to be rejected")
+	}
+	if res.RetryAfter != time.Second {
+		t.Errorf("Expected retry after 1s, got %v", res.RetryAfter)
+	}
+
+	res, _ = store.Take(ctx, "k", limit, now.Add(time.Second))
+	if !res.Allowed {
+		t.Error("Expected a token after one second of refill")
+	}
+
+	res, _ = store.Take(ctx, "other", limit, now)
+	if !res.Allowed {
+		t.Error("Expected separate keys to have separate buckets")
+	}
+}
+
+func TestMemoryStoreExpiry(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(0)
+	defer store.Close()
+
+	limit := PerMinute(60, 2)
+	now := time.Unix(1_700_000_000, 0)
+	store.Take(ctx, "a", limit, now)
+	store.Take(ctx, "b", limit, now)
+
+	store.sweep(now.Add(500 * time.Millisecond))
+	if store.Len() != 2 {
+		t.Errorf("Expected buckets still refilling to be kept, got %d", store.Len())
+	}
+
+	store.sweep(now.Add(time.Second))
+	if store.Len() != 0 {
+		t.Errorf("Expected full buckets to be dropped, got %d", store.Len())
+	}
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/database"
+)
+
+// RefreshToken records an issued refresh token by its jti so it can be revoked
+type RefreshToken struct {
+	ID        string
+	UserID    int
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+// Active reports whether the token is neither revoked nor expired at now
+func (t *RefreshToken) Active(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
+
+// RefreshTokenRepository stores refresh tokens in the refresh_tokens table
+type RefreshTokenRepository struct {
+	db *database.DB
+}
+
+// NewRefreshTokenRepository creates a RefreshTokenRepository on db
+func NewRefreshTokenRepository(db *database.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+// Create records a newly issued token
+func (r *RefreshTokenRepository) Create(ctx context.Context, t *RefreshToken) error {
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now().UTC()
+	}
+	query := r.db.Rebind("INSERT INTO refresh_tokens (id, user_id, expires_at, created_at) VALUES (?, ?, ?, ?)")
+	if _, err := r.db.ExecContext(ctx, query, t.ID, t.UserID, t.ExpiresAt.UTC(), t.CreatedAt); err != nil {
+		return fmt.Errorf("repository: create refresh token: %w", err)
+	}
+	return nil
+}
+
+// Get returns the token with id
+func (r *RefreshTokenRepository) Get(ctx context.Context, id string) (*RefreshToken, error) {
+	var (
+		t         RefreshToken
+		revokedAt sql.NullTime
+	)
+	query := r.db.Rebind("SELECT id, user_id, expires_at, revoked_at, created_at FROM refresh_tokens WHERE id = ?")
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&t.ID, &t.UserID, &t.ExpiresAt, &revokedAt, &t.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("repository: get refresh token: %w", err)
+	}
+	if revokedAt.Valid {
+		t.RevokedAt = &revokedAt.Time
+	}
+	return &t, nil
+}
+
+// Revoke marks the token revoked. It reports false when the token was
+// already revoked or does not exist, which lets callers detect reuse.
+func (r *RefreshTokenRepository) Revoke(ctx context.Context, id string, at time.Time) (bool, error) {
+	query := r.db.Rebind("UPDATE refresh_tokens SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL")
+	res, err := r.db.ExecContext(ctx, query, at.UTC(), id)
+	if err != nil {
+		return false, fmt.Errorf("repository: revoke refresh token: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("repository: revoke refresh token: %w", err)
+	}
+	return n == 1, nil
+}
+
+// RevokeAllForUser revokes every active token of the user and returns how many it revoked
+func (r *RefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID int, at time.Time) (int64, error) {
+	query := r.db.Rebind("UPDATE refresh_tokens SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL")
+	res, err := r.db.ExecContext(ctx, query, at.UTC(), userID)
+	if err != nil {
+		return 0, fmt.Errorf("repository: revoke refresh tokens: %w", err)
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,12 @@
+// Package repository stores domain objects in the main database. Queries
+// are written with "?" placeholders and rebound for the connection's dialect.
+package repository
+
+import "errors"
+
+var (
+	// ErrNotFound is returned when no (non-deleted) row matches
+	ErrNotFound = errors.New("repository: not found")
+	// ErrDuplicateEmail is returned when another user already has the email
+	ErrDuplicateEmail = errors.New("repository: email already registered")
+)
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/database/dbtest"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/userdomain"
+)
+
+func createUser(t *testing.T, repo *UserRepository, email string) *userdomain.User {
+	t.Helper()
+	u, err := userdomain.NewUser(email, "Test User", "Password123")
+	if err != nil {
+		t.Fatalf("NewUser() error = %v", err)
+	}
+	u.PasswordHash = "hash"
+	if err := repo.Create(context.Background(), u); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	return u
+}
+
+func TestUserRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewUserRepository(dbtest.Open(t))
+
+	u := createUser(t, repo, "alice@example.com")
+	if u.ID == 0 {
+		t.Fatal("Expected Create to set the ID")
+	}
+
+	got, err := repo.GetByEmail(ctx, "ALICE@example.com")
+	if err != nil {
+		t.Fatalf("GetByEmail() error = %v", err)
+	}
+	if got.ID != u.ID || got.Role != userdomain.RoleUser || got.PasswordHash != "hash" {
+		t.Errorf("Expected stored user %+v, got %+v", u, got)
+	}
+
+	if _, err := repo.GetByID(ctx, u.ID); err != nil {
+		t.Errorf("GetByID() error = %v", err)
+	}
+	if _, err := repo.GetByID(ctx, u.ID+100); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	dup, _ := userdomain.NewUser("alice@example.com", "Other", "Password123")
+	dup.PasswordHash = "hash"
+	if err := repo.Create(ctx, dup); !errors.Is(err, ErrDuplicateEmail) {
+		t.Errorf("Expected ErrDuplicateEmail, got %v", err)
+	}
+}
+
+func TestRefreshTokenRepository(t *testing.T) {
+	ctx := context.Background()
+	db := dbtest.Open(t)
+	user := createUser(t, NewUserRepository(db), "bob@example.com")
+	repo := NewRefreshTokenRepository(db)
+
+	now := time.Now().UTC()
+	for _, id := range []string{"a", "b"} {
+		err := repo.Create(ctx, &RefreshToken{ID: id, UserID: user.ID, ExpiresAt: now.Add(time.Hour)})
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	tok, err := repo.Get(ctx, "a")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !tok.Active(now) || tok.Active(now.Add(2*time.Hour)) {
+		t.Errorf("Expected token active until %v", tok.ExpiresAt)
+	}
+
+	if ok, err := repo.Revoke(ctx, "a", now); err != nil || !ok {
+		t.Fatalf("Expected first Revoke to succeed, got %v, %v", ok, err)
+	}
+	if ok, _ := repo.Revoke(ctx, "a", now); ok {
+		t.Error("Expected second Revoke to report the token was already revoked")
+	}
+	if tok, _ := repo.Get(ctx, "a"); tok.RevokedAt == nil {
+		t.Error("Expected RevokedAt to be set")
+	}
+
+	if n, err := repo.RevokeAllForUser(ctx, user.ID, now); err != nil || n != 1 {
+		t.Errorf("Expected RevokeAllForUser to revoke 1 token, got %d (err %v)", n, err)
+	}
+	if _, err := repo.Get(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestUserRepositoryUpdatesAndPurge(t *testing.T) {
+	ctx := context.Background()
+	db := dbtest.Open(t)
+	repo := NewUserRepository(db)
+	u := createUser(t, repo, "carol@example.com")
+
+	if err := repo.UpdateRole(ctx, u.ID, userdomain.RoleAdmin); err != nil {
+		t.Fatalf("UpdateRole() error = %v", err)
+	}
+	if err := repo.UpdatePassword(ctx, u.ID, "new-hash"); err != nil {
+		t.Fatalf("UpdatePassword() error = %v", err)
+	}
+	got, _ := repo.GetByID(ctx, u.ID)
+	if got.Role != userdomain.RoleAdmin || got.PasswordHash != "new-hash" {
+		t.Errorf("Expected updated role and hash, got %+v", got)
+	}
+	if err := repo.UpdateRole(ctx, u.ID+100, userdomain.RoleAdmin); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	old := createUser(t, repo, "old@example.com")
+	recent := createUser(t, repo, "recent@example.com")
+	now := time.Now().UTC()
+	for id, at := range map[int]time.Time{old.ID: now.Add(-48 * time.Hour), recent.ID: now.Add(-time.Hour)} {
+		if _, err := db.ExecContext(ctx, "UPDATE users SET deleted_at = ? WHERE id = ?", at, id); err != nil {
+			t.Fatalf("Failed to soft-delete: %v", err)
+		}
+	}
+	NewRefreshTokenRepository(db).Create(ctx, &RefreshToken{ID: "t", UserID: old.ID, ExpiresAt: now.Add(time.Hour)})
+
+	n, err := repo.PurgeDeleted(ctx, now.Add(-24*time.Hour))
+	if err != nil || n != 1 {
+		t.Fatalf("Expected 1 purged user, got %d (err %v)", n, err)
+	}
+	var left int
+	db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users").Scan(&left)
+	if left != 2 {
+		t.Errorf("Expected carol and the recently deleted user to remain, got %d rows", left)
+	}
+	if _, err := NewRefreshTokenRepository(db).Get(ctx, "t"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected the purged user's tokens to be gone, got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/database"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/userdomain"
+)
+
+const userColumns = "id, email, name, role, password_hash, created_at, updated_at, deleted_at"
+
+// UserRepository stores users in the users table. Soft-deleted users are
+// invisible to every lookup.
+type UserRepository struct {
+	db *database.DB
+}
+
+// NewUserRepository creates a UserRepository on db
+func NewUserRepository(db *database.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+// Create inserts u and sets its ID. A taken email gives ErrDuplicateEmail.
+func (r *UserRepository) Create(ctx context.Context, u *userdomain.User) error {
+	now := time.Now().UTC()
+	if u.CreatedAt.IsZero() {
+		u.CreatedAt = now
+	}
+	if u.UpdatedAt.IsZero() {
+		u.UpdatedAt = now
+	}
+
+	query := r.db.Rebind(`INSERT INTO users (email, name, role, password_hash, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?) RETURNING id`)
+	err := r.db.QueryRowContext(ctx, query,
+		u.Email, u.Name, u.Role, u.PasswordHash, u.CreatedAt, u.UpdatedAt,
+	).Scan(&u.ID)
+	if database.IsUniqueViolation(err) {
+		return ErrDuplicateEmail
+	}
+	if err != nil {
+		return fmt.Errorf("repository: create user: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns the user with id
+func (r *UserRepository) GetByID(ctx context.Context, id int) (*userdomain.User, error) {
+	query := r.db.Rebind("SELECT " + userColumns + " FROM users WHERE id = ? AND deleted_at IS NULL")
+	return r.scanOne(r.db.QueryRowContext(ctx, query, id))
+}
+
+// GetByEmail returns the user with email, compared case-insensitively
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*userdomain.User, error) {
+	query := r.db.Rebind("SELECT " + userColumns + " FROM users WHERE email = ? AND deleted_at IS NULL")
+	return r.scanOne(r.db.QueryRowContext(ctx, query, userdomain.NormalizeEmail(email)))
+}
+
+// UpdateRole sets the role of the user with id
+func (r *UserRepository) UpdateRole(ctx context.Context, id int, role string) error {
+	return r.update(ctx, id, "role", role)
+}
+
+// UpdatePassword replaces the password hash of the user with id
+func (r *UserRepository) UpdatePassword(ctx context.Context, id int, hash string) error {
+	return r.update(ctx, id, "password_hash", hash)
+}
+
+// update sets one column of a live user; column is never user input
+func (r *UserRepository) update(ctx context.Context, id int, column string, value any) error {
+	query := r.db.Rebind("UPDATE users SET " + column + " = ?, updated_at = ? WHERE id = ? AND deleted_at IS NULL")
+	res, err := r.db.ExecContext(ctx, query, value, time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("repository: update user %s: %w", column, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// PurgeDeleted permanently removes users soft-deleted before cutoff,
+// together with their refresh tokens, and returns how many it removed
+func (r *UserRepository) PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error) {
+	query := r.db.Rebind("DELETE FROM users WHERE deleted_at IS NOT NULL AND deleted_at < ?")
+	res, err := r.db.ExecContext(ctx, query, cutoff.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("repository: purge deleted users: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+func (r *UserRepository) scanOne(row *sql.Row) (*userdomain.User, error) {
+	var (
+		u         userdomain.User
+		deletedAt sql.NullTime
+	)
+	err := row.Scan(&u.ID, &u.Email, &u.Name, &u.Role, &u.PasswordHash, &u.CreatedAt, &u.UpdatedAt, &deletedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("repository: scan user: %w", err)
+	}
+	if deletedAt.Valid {
+		u.DeletedAt = &deletedAt.Time
+	}
+	return &u, nil
+}
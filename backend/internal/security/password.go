@@ -0,0 +1,90 @@
+package security
+
+import (
+	"errors"
+	"regexp"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// DefaultCost is the bcrypt cost used by NewPasswordService
+const DefaultCost = 10
+
+// PasswordService handles password operations
+type PasswordService struct {
+	cost int
+	// dummyHash lets VerifyDummy spend the same time as a real comparison
+	dummyHash []byte
+}
+
+// NewPasswordService creates a new password service using DefaultCost
+func NewPasswordService() *PasswordService {
+	return NewPasswordServiceWithCost(DefaultCost)
+}
+
+// NewPasswordServiceWithCost creates a password service with a custom bcrypt cost;
+// tests use bcrypt.MinCost to stay fast
+func NewPasswordServiceWithCost(cost int) *PasswordService {
+	dummy, err := bcrypt.GenerateFromPassword([]byte("dummy-password-for-timing"), cost)
+	if err != nil {
+		panic("security: cannot create dummy hash: " + err.Error())
+	}
+	return &PasswordService{cost: cost, dummyHash: dummy}
+}
+
+// HashPassword hashes a password using bcrypt
+func (p *PasswordService) HashPassword(password string) (string, error) {
+	if password == "" {
+		return "", errors.New("password cannot be empty")
+	}
+
+	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), p.cost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hashedBytes), nil
+}
+
+// VerifyPassword checks if password matches hash
+func (p *PasswordService) VerifyPassword(password, hash string) bool {
+	if password == "" || hash == "" {
+		return false
+	}
+
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	return err == nil
+}
+
+// VerifyDummy runs a comparison that always fails, so that a login for an
+// unknown email takes as long as one with a wrong password
+func (p *PasswordService) VerifyDummy(password string) bool {
+	bcrypt.CompareHashAndPassword(p.dummyHash, []byte(password))
+	return false
+}
+
+// ValidatePassword checks if password meets basic requirements
+// Requirements:
+// - At least 6 characters
+// - Contains at least one letter and one number
+func ValidatePassword(password string) error {
+	if password == "" {
+		return errors.New("password cannot be empty")
+	}
+
+	if len(password) < 6 {
+		return errors.New("password must be at least 6 characters long")
+	}
+
+	hasLetter := regexp.MustCompile(`[a-zA-Z]`).MatchString(password)
+	if !hasLetter {
+		return errors.New("password must contain at least one letter")
+	}
+
+	hasNumber := regexp.MustCompile(`[0-9]`).MatchString(password)
+	if !hasNumber {
+		return errors.New("password must contain at least one number")
+	}
+
+	return nil
+}
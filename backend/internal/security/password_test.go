@@ -0,0 +1,86 @@
+package security
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPasswordService_HashAndVerify(t *testing.T) {
+	service := NewPasswordServiceWithCost(bcrypt.MinCost)
+
+	if _, err := service.HashPassword(""); err == nil {
+		t.Error("HashPassword() should reject empty password")
+	}
+
+	hash, err := service.HashPassword("password123")
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+	if hash == "password123" {
+		t.Error("HashPassword() should not return the original password")
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		hash     string
+		want     bool
+	}{
+		{"correct password", "password123", hash, true},
+		{"wrong password", "wrongpassword", hash, false},
+		{"empty password", "", hash, false},
+		{"empty hash", "password123", "", false},
+		{"invalid hash", "password123", "invalid-hash", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := service.VerifyPassword(tt.password, tt.hash); got != tt.want {
+				t.Errorf("VerifyPassword() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPasswordService_VerifyDummyTakesComparableTime(t *testing.T) {
+	service := NewPasswordServiceWithCost(DefaultCost)
+	hash, _ := service.HashPassword("password123")
+
+	start := time.Now()
+	service.VerifyPassword("wrong-password", hash)
+	real := time.Since(start)
+
+	start = time.Now()
+	if service.VerifyDummy("wrong-password") {
+		t.Error("VerifyDummy() must always fail")
+	}
+	dummy := time.Since(start)
+
+	if dummy < real/4 {
+		t.Errorf("Expected dummy comparison (%v) to cost about as much as a real one (%v)", dummy, real)
+	}
+}
+
+func TestValidatePassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		wantErr  bool
+	}{
+		{"valid password", "password123", false},
+		{"empty password", "", true},
+		{"too short", "abc12", true},
+		{"no letters", "123456", true},
+		{"no numbers", "password", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidatePassword(tt.password); (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePassword() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package userdomain
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Roles a user can hold
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+var (
+	emailPattern = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+	hasUpper     = regexp.MustCompile(`[A-Z]`)
+	hasLower     = regexp.MustCompile(`[a-z]`)
+	hasDigit     = regexp.MustCompile(`[0-9]`)
+)
+
+// User represents a user entity in the domain
+type User struct {
+	ID           int        `json:"id"`
+	Email        string     `json:"email"`
+	Name         string     `json:"name"`
+	Role         string     `json:"role"`
+	PasswordHash string     `json:"-"` // Never serialize password hashes
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	DeletedAt    *time.Time `json:"-"`
+}
+
+// FieldError describes why a single input field was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError collects every rejected field so clients can show them all at once
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = f.Field + ": " + f.Message
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+// add records err against field when it is non-nil
+func (e *ValidationError) add(field string, err error) {
+	if err != nil {
+		e.Fields = append(e.Fields, FieldError{Field: field, Message: err.Error()})
+	}
+}
+
+// orNil returns e only when it holds at least one field error
+func (e *ValidationError) orNil() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// NewUser validates the registration input and returns an unsaved user with
+// the default role. Every invalid field is reported in a *ValidationError.
+// The password is only validated; hashing it is up to the caller.
+func NewUser(email, name, password string) (*User, error) {
+	verr := &ValidationError{}
+	verr.add("email", ValidateEmail(email))
+	verr.add("name", ValidateName(name))
+	verr.add("password", ValidatePassword(password))
+	if err := verr.orNil(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	return &User{
+		Email:     NormalizeEmail(email),
+		Name:      strings.TrimSpace(name),
+		Role:      RoleUser,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// Validate checks if the user data is valid
+func (u *User) Validate() error {
+	verr := &ValidationError{}
+	verr.add("email", ValidateEmail(u.Email))
+	verr.add("name", ValidateName(u.Name))
+	verr.add("role", ValidateRole(u.Role))
+	return verr.orNil()
+}
+
+// HasRole reports whether the user holds role; admins hold every role
+func (u *User) HasRole(role string) bool {
+	return u.Role == role || u.Role == RoleAdmin
+}
+
+// Roles returns the roles to put into access tokens
+func (u *User) Roles() []string {
+	if u.Role == RoleAdmin {
+		return []string{RoleUser, RoleAdmin}
+	}
+	return []string{u.Role}
+}
+
+// NormalizeEmail lower-cases and trims an email so lookups are case-insensitive
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// ValidateEmail checks if email format is valid
+func ValidateEmail(email string) error {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return errors.New("email cannot be empty")
+	}
+	if len(email) > 255 {
+		return errors.New("email must be at most 255 characters")
+	}
+	if !emailPattern.MatchString(email) {
+		return errors.New("invalid email format")
+	}
+	return nil
+}
+
+// ValidateName checks if name is valid
+func ValidateName(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("name cannot be empty")
+	}
+	if len(name) < 2 {
+		return errors.New("name must be at least 2 characters")
+	}
+	if len(name) > 50 {
+		return errors.New("name must be at most 50 characters")
+	}
+	return nil
+}
+
+// ValidatePassword checks if password meets security requirements
+func ValidatePassword(password string) error {
+	if len(password) < 8 {
+		return errors.New("password must be at least 8 characters")
+	}
+	// bcrypt ignores everything past 72 bytes
+	if len(password) > 72 {
+		return errors.New("password must be at most 72 bytes")
+	}
+	if !hasUpper.MatchString(password) {
+		return errors.New("password must contain at least one uppercase letter")
+	}
+	if !hasLower.MatchString(password) {
+		return errors.New("password must contain at least one lowercase letter")
+	}
+	if !hasDigit.MatchString(password) {
+		return errors.New("password must contain at least one number")
+	}
+	return nil
+}
+
+// ValidateRole checks that role is one the API knows about
+func ValidateRole(role string) error {
+	switch role {
+	case RoleUser, RoleAdmin:
+		return nil
+	}
+	return errors.New("role must be one of user, admin")
+}
+
+// UpdateName updates the user's name with validation
+func (u *User) UpdateName(name string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	u.Name = strings.TrimSpace(name)
+	u.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// UpdateEmail updates the user's email with validation
+func (u *User) UpdateEmail(email string) error {
+	if err := ValidateEmail(email); err != nil {
+		return err
+	}
+	u.Email = NormalizeEmail(email)
+	u.UpdatedAt = time.Now().UTC()
+	return nil
+}
@@ -0,0 +1,94 @@
+package userdomain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewUser(t *testing.T) {
+	tests := []struct {
+		name       string
+		email      string
+		userName   string
+		password   string
+		wantFields []string
+	}{
+		{"valid user", "Test@Example.com ", " John Doe ", "Password123", nil},
+		{"invalid email", "invalid-email", "John Doe", "Password123", []string{"email"}},
+		{"name too short", "test@example.com", "J", "Password123", []string{"name"}},
+		{"password too short", "test@example.com", "John Doe", "Pa1", []string{"password"}},
+		{"password without uppercase", "test@example.com", "John Doe", "password123", []string{"password"}},
+		{"password without digit", "test@example.com", "John Doe", "Passwordabc", []string{"password"}},
+		{"everything invalid", "", "", "", []string{"email", "name", "password"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := NewUser(tt.email, tt.userName, tt.password)
+			if tt.wantFields == nil {
+				if err != nil {
+					t.Fatalf("NewUser() error = %v", err)
+				}
+				if user.Email != "test@example.com" || user.Name != "John Doe" {
+					t.Errorf("Expected normalized email and name, got %q and %q", user.Email, user.Name)
+				}
+				if user.Role != RoleUser {
+					t.Errorf("Expected role %q, got %q", RoleUser, user.Role)
+				}
+				if user.CreatedAt.IsZero() {
+					t.Error("Expected CreatedAt to be set")
+				}
+				return
+			}
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Expected *ValidationError, got %v", err)
+			}
+			if len(verr.Fields) != len(tt.wantFields) {
+				t.Fatalf("Expected fields %v, got %+v", tt.wantFields, verr.Fields)
+			}
+			for i, field := range tt.wantFields {
+				if verr.Fields[i].Field != field {
+					t.Errorf("Expected field %q at %d, got %q", field, i, verr.Fields[i].Field)
+				}
+			}
+		})
+	}
+}
+
+func TestRoles(t *testing.T) {
+	admin := &User{Role: RoleAdmin}
+	if !admin.HasRole(RoleUser) || len(admin.Roles()) != 2 {
+		t.Errorf("Expected admin to hold every role, got %v", admin.Roles())
+	}
+
+	user := &User{Role: RoleUser}
+	if user.HasRole(RoleAdmin) {
+		t.Error("Expected plain user not to hold admin")
+	}
+
+	if err := ValidateRole("root"); err == nil {
+		t.Error("Expected unknown role to be rejected")
+	}
+}
+
+func TestUpdateEmailAndName(t *testing.T) {
+	user, err := NewUser("test@example.com", "John Doe", "Password123")
+	if err != nil {
+		t.Fatalf("NewUser() error = %v", err)
+	}
+
+	if err := user.UpdateEmail("bad"); err == nil {
+		t.Error("Expected invalid email to be rejected")
+	}
+	if err := user.UpdateEmail(" NEW@example.com"); err != nil || user.Email != "new@example.com" {
+		t.Errorf("Expected new@example.com, got %q (err %v)", user.Email, err)
+	}
+	if err := user.UpdateName("X"); err == nil {
+		t.Error("Expected short name to be rejected")
+	}
+	if err := user.UpdateName("Jane Doe"); err != nil || user.Name != "Jane Doe" {
+		t.Errorf("Expected Jane Doe, got %q (err %v)", user.Name, err)
+	}
+}
@@ -0,0 +1,255 @@
+package validation
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/apierror"
+)
+
+// Lang is a language messages are available in
+type Lang string
+
+// Supported languages; English is the fallback
+const (
+	English Lang = "en"
+	Russian Lang = "ru"
+)
+
+// Keys of the fixed messages returned by Text
+const (
+	MsgValidationFailed = "validation_failed"
+	MsgInvalidBody      = "invalid_body"
+	MsgInvalidQuery     = "invalid_query"
+)
+
+var texts = map[Lang]map[string]string{
+	English: {
+		MsgValidationFailed: "request validation failed",
+		MsgInvalidBody:      "request body is not valid JSON",
+		MsgInvalidQuery:     "query parameters are not valid",
+	},
+	Russian: {
+		MsgValidationFailed: "запрос не прошёл проверку",
+		MsgInvalidBody:      "тело запроса не является корректным JSON",
+		MsgInvalidQuery:     "некорректные параметры запроса",
+	},
+}
+
+// domainRussian translates the messages userdomain validators return. They
+// are written in English for logs, so the English text doubles as the key.
+var domainRussian = map[string]string{
+	"email cannot be empty":                               "адрес электронной почты не может быть пустым",
+	"email must be at most 255 characters":                "адрес электронной почты должен быть не длиннее 255 символов",
+	"invalid email format":                                "некорректный адрес электронной почты",
+	"name cannot be empty":                                "имя не может быть пустым",
+	"name must be at least 2 characters":                  "имя должно быть не короче 2 символов",
+	"name must be at most 50 characters":                  "имя должно быть не длиннее 50 символов",
+	"password must be at least 8 characters":              "пароль должен быть не короче 8 символов",
+	"password must be at most 72 bytes":                   "пароль должен быть не длиннее 72 байт",
+	"password must contain at least one uppercase letter": "пароль должен содержать заглавную букву",
+	"password must contain at least one lowercase letter": "пароль должен содержать строчную букву",
+	"password must contain at least one number":           "пароль должен содержать цифру",
+	"role must be one of user, admin":                     "роль должна быть одной из: user, admin",
+}
+
+// LanguageFrom picks the preferred supported language from an
+// Accept-Language header, honouring q-values; English when none matches
+func LanguageFrom(header string) Lang {
+	type candidate struct {
+		lang Lang
+		q    float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		primary, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		lang := Lang(primary)
+		if _, ok := texts[lang]; !ok {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q > 0 {
+			candidates = append(candidates, candidate{lang, q})
+		}
+	}
+	if len(candidates) == 0 {
+		return English
+	}
+	// Stable keeps header order between equal weights
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	return candidates[0].lang
+}
+
+// Text returns one of the Msg* messages in lang
+func Text(lang Lang, key string) string {
+	if msg, ok := texts[lang][key]; ok {
+		return msg
+	}
+	return texts[English][key]
+}
+
+// DomainMessage returns a userdomain validation message in lang, falling
+// back to the original English text
+func DomainMessage(lang Lang, msg string) string {
+	if lang == Russian {
+		if translated, ok := domainRussian[msg]; ok {
+			return translated
+		}
+	}
+	return msg
+}
+
+// FieldErrors converts rule violations into per-field errors in lang. Fields
+// are named by their path below the request struct, e.g. "address.city".
+func FieldErrors(errs validator.ValidationErrors, lang Lang) []apierror.FieldError {
+	fields := make([]apierror.FieldError, len(errs))
+	for i, fe := range errs {
+		field := fe.Namespace()
+		if _, rest, ok := strings.Cut(field, "."); ok {
+			field = rest
+		}
+		fields[i] = apierror.FieldError{Field: field, Message: Message(fe, lang)}
+	}
+	return fields
+}
+
+// Message describes why fe's rule rejected the value, in lang
+func Message(fe validator.FieldError, lang Lang) string {
+	if lang == Russian {
+		return russian(fe)
+	}
+	return english(fe)
+}
+
+// sizeKind tells how min, max and len apply: to a number's value, a
+// string's length or a collection's item count
+type sizeKind int
+
+const (
+	sizeNumber sizeKind = iota
+	sizeString
+	sizeItems
+)
+
+func kindOf(fe validator.FieldError) sizeKind {
+	switch k := fe.Kind(); {
+	case k == reflect.String:
+		return sizeString
+	case k == reflect.Slice || k == reflect.Array || k == reflect.Map:
+		return sizeItems
+	default:
+		return sizeNumber
+	}
+}
+
+func english(fe validator.FieldError) string {
+	p := fe.Param()
+	var unit string
+	switch kindOf(fe) {
+	case sizeString:
+		unit = " characters"
+	case sizeItems:
+		unit = " items"
+	}
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email"
+	case "url":
+		return "must be a valid URL"
+	case "min", "gte":
+		return "must be at least " + p + unit
+	case "max", "lte":
+		return "must be at most " + p + unit
+	case "len":
+		return "must be exactly " + p + unit
+	case "gt":
+		return "must be greater than " + p + unit
+	case "lt":
+		return "must be less than " + p + unit
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(p, " ", ", ")
+	case HexColor:
+		return "must be a hex color such as #1e90ff"
+	case StrongPassword:
+		return "must be 8-72 characters with an uppercase letter, a lowercase letter and a digit"
+	case Timezone:
+		return "must be an IANA time zone such as Europe/Moscow"
+	case E164:
+		return "must be a phone number in E.164 format such as +79991234567"
+	}
+	return "failed the " + fe.Tag() + " rule"
+}
+
+func russian(fe validator.FieldError) string {
+	p := fe.Param()
+	// Comparisons take the genitive ("не меньше 2 символов"), exact counts
+	// the counting forms ("ровно 2 символа")
+	var than, exactly string
+	switch kindOf(fe) {
+	case sizeString:
+		than = " " + ruPlural(p, "символа", "символов", "символов")
+		exactly = " " + ruPlural(p, "символ", "символа", "символов")
+	case sizeItems:
+		than = " " + ruPlural(p, "элемента", "элементов", "элементов")
+		exactly = " " + ruPlural(p, "элемент", "элемента", "элементов")
+	}
+	switch fe.Tag() {
+	case "required":
+		return "обязательное поле"
+	case "email":
+		return "должно быть корректным адресом электронной почты"
+	case "url":
+		return "должно быть корректным URL"
+	case "min", "gte":
+		return "должно быть не меньше " + p + than
+	case "max", "lte":
+		return "должно быть не больше " + p + than
+	case "len":
+		return "должно быть ровно " + p + exactly
+	case "gt":
+		return "должно быть больше " + p + than
+	case "lt":
+		return "должно быть меньше " + p + than
+	case "oneof":
+		return "должно быть одним из: " + strings.ReplaceAll(p, " ", ", ")
+	case HexColor:
+		return "должно быть цветом в шестнадцатеричном формате, например #1e90ff"
+	case StrongPassword:
+		return "должно содержать от 8 до 72 символов, заглавную и строчную букву и цифру"
+	case Timezone:
+		return "должно быть часовым поясом IANA, например Europe/Moscow"
+	case E164:
+		return "должно быть номером телефона в формате E.164, например +79991234567"
+	}
+	return "не прошло проверку " + fe.Tag()
+}
+
+// ruPlural picks the Russian noun form for the number n: 1 символ,
+// 2 символа, 5 символов, 21 символ
+func ruPlural(n, one, few, many string) string {
+	v, err := strconv.Atoi(n)
+	if err != nil {
+		return many
+	}
+	v %= 100
+	switch {
+	case v >= 11 && v <= 14:
+		return many
+	case v%10 == 1:
+		return one
+	case v%10 >= 2 && v%10 <= 4:
+		return few
+	}
+	return many
+}
@@ -0,0 +1,50 @@
+package validation
+
+import (
+	"regexp"
+	"time"
+	// Embedded zone data, so timezone does not depend on the host's tzdata
+	_ "time/tzdata"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/timur-harin/sum25-go-flutter-course/backend/internal/userdomain"
+)
+
+// Custom rule tags, usable in `binding` tags like any built-in rule
+const (
+	// HexColor accepts #rgb, #rgba, #rrggbb and #rrggbbaa
+	HexColor = "hex_color"
+	// StrongPassword applies the account password policy
+	StrongPassword = "strong_password"
+	// Timezone accepts IANA zone names such as Europe/Moscow; stricter than
+	// the built-in rule, which also accepts "Local"
+	Timezone = "timezone"
+	// E164 accepts phone numbers like +79991234567; the built-in rule lets
+	// a zero follow the plus sign
+	E164 = "e164"
+)
+
+var (
+	hexColorPattern = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3,4}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`)
+	e164Pattern     = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+)
+
+var rules = map[string]validator.Func{
+	HexColor: func(fl validator.FieldLevel) bool {
+		return hexColorPattern.MatchString(fl.Field().String())
+	},
+	StrongPassword: func(fl validator.FieldLevel) bool {
+		return userdomain.ValidatePassword(fl.Field().String()) == nil
+	},
+	Timezone: func(fl validator.FieldLevel) bool {
+		name := fl.Field().String()
+		if name == "" || name == "Local" {
+			return false
+		}
+		_, err := time.LoadLocation(name)
+		return err == nil
+	},
+	E164: func(fl validator.FieldLevel) bool {
+		return e164Pattern.MatchString(fl.Field().String())
+	},
+}
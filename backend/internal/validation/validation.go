@@ -0,0 +1,58 @@
+// Package validation configures request validation: the custom rules
+// available in `binding` tags and the translation of rule violations into
+// per-field messages in English or Russian.
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// Register adds the custom rules to v and makes it name fields by their
+// json tag, falling back to the form tag, so errors use the names clients send
+func Register(v *validator.Validate) error {
+	v.RegisterTagNameFunc(fieldName)
+	for tag, fn := range rules {
+		if err := v.RegisterValidation(tag, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var (
+	installOnce sync.Once
+	installErr  error
+)
+
+// Install registers the custom rules on gin's binding validator. It is safe
+// to call more than once; the rules must be in place before requests are bound.
+func Install() error {
+	installOnce.Do(func() {
+		v, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			installErr = errors.New("validation: gin's binding validator is not go-playground/validator")
+			return
+		}
+		installErr = Register(v)
+	})
+	return installErr
+}
+
+func fieldName(f reflect.StructField) string {
+	for _, key := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(f.Tag.Get(key), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return f.Name
+}
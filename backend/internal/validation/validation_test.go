@@ -0,0 +1,182 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type address struct {
+	City string `json:"city" binding:"required"`
+}
+
+type profile struct {
+	Color    string   `json:"color" binding:"omitempty,hex_color"`
+	Password string   `json:"password" binding:"omitempty,strong_password"`
+	Zone     string   `json:"timezone" binding:"omitempty,timezone"`
+	Phone    string   `json:"phone" binding:"omitempty,e164"`
+	Name     string   `json:"name" binding:"omitempty,min=2,max=5"`
+	Age      int      `json:"age" binding:"omitempty,min=18"`
+	Tags     []string `json:"tags" binding:"omitempty,max=2"`
+	Sort     string   `form:"sort" binding:"omitempty,oneof=asc desc"`
+	Code     string   `json:"code" binding:"omitempty,len=3"`
+	Address  *address `json:"address" binding:"omitempty"`
+}
+
+func newValidator(t *testing.T) *validator.Validate {
+	t.Helper()
+	v := validator.New()
+	v.SetTagName("binding")
+	if err := Register(v); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	return v
+}
+
+func validationErrors(t *testing.T, v *validator.Validate, p profile) validator.ValidationErrors {
+	t.Helper()
+	var verrs validator.ValidationErrors
+	if err := v.Struct(p); err != nil && !errors.As(err, &verrs) {
+		t.Fatalf("Expected ValidationErrors, got %v", err)
+	}
+	return verrs
+}
+
+func TestRules(t *testing.T) {
+	v := newValidator(t)
+
+	tests := []struct {
+		name    string
+		profile profile
+		wantTag string
+	}{
+		{"short hex color", profile{Color: "#fff"}, ""},
+		{"hex color with alpha", profile{Color: "#1E90FF80"}, ""},
+		{"named color", profile{Color: "red"}, HexColor},
+		{"five digit color", profile{Color: "#12345"}, HexColor},
+		{"strong password", profile{Password: "Password123"}, ""},
+		{"no digit", profile{Password: "Password"}, StrongPassword},
+		{"too short", profile{Password: "Pa1"}, StrongPassword},
+		{"iana zone", profile{Zone: "Europe/Moscow"}, ""},
+		{"utc", profile{Zone: "UTC"}, ""},
+		{"local zone", profile{Zone: "Local"}, Timezone},
+		{"unknown zone", profile{Zone: "Mars/Olympus"}, Timezone},
+		{"e164", profile{Phone: "+79991234567"}, ""},
+		{"leading zero", profile{Phone: "+09991234567"}, E164},
+		{"no plus", profile{Phone: "79991234567"}, E164},
+		{"too long", profile{Phone: "+1234567890123456"}, E164},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verrs := validationErrors(t, v, tt.profile)
+			if tt.wantTag == "" {
+				if len(verrs) != 0 {
+					t.Errorf("Expected valid, got %v", verrs)
+				}
+				return
+			}
+			if len(verrs) != 1 || verrs[0].Tag() != tt.wantTag {
+				t.Errorf("Expected a %s error, got %v", tt.wantTag, verrs)
+			}
+		})
+	}
+}
+
+func TestFieldErrors(t *testing.T) {
+	v := newValidator(t)
+	verrs := validationErrors(t, v, profile{
+		Color:   "blue",
+		Name:    "A",
+		Age:     16,
+		Tags:    []string{"a", "b", "c"},
+		Sort:    "up",
+		Code:    "ab",
+		Address: &address{},
+	})
+
+	tests := []struct {
+		field  string
+		wantEN string
+		wantRU string
+	}{
+		{"color", "must be a hex color such as #1e90ff", "должно быть цветом в шестнадцатеричном формате, например #1e90ff"},
+		{"name", "must be at least 2 characters", "должно быть не меньше 2 символов"},
+		{"age", "must be at least 18", "должно быть не меньше 18"},
+		{"tags", "must be at most 2 items", "должно быть не больше 2 элементов"},
+		{"sort", "must be one of asc, desc", "должно быть одним из: asc, desc"},
+		{"code", "must be exactly 3 characters", "должно быть ровно 3 символа"},
+		{"address.city", "is required", "обязательное поле"},
+	}
+
+	en := FieldErrors(verrs, English)
+	ru := FieldErrors(verrs, Russian)
+	if len(en) != len(tests) || len(ru) != len(tests) {
+		t.Fatalf("Expected %d field errors, got %v", len(tests), en)
+	}
+	for i, tt := range tests {
+		if en[i].Field != tt.field || ru[i].Field != tt.field {
+			t.Errorf("Expected field %q, got %q / %q", tt.field, en[i].Field, ru[i].Field)
+		}
+		if en[i].Message != tt.wantEN {
+			t.Errorf("%s: expected %q, got %q", tt.field, tt.wantEN, en[i].Message)
+		}
+		if ru[i].Message != tt.wantRU {
+			t.Errorf("%s: expected %q, got %q", tt.field, tt.wantRU, ru[i].Message)
+		}
+	}
+}
+
+func TestRuPlural(t *testing.T) {
+	tests := map[string]string{
+		"1": "символ", "2": "символа", "4": "символа", "5": "символов", "11": "символов",
+		"12": "символов", "21": "символ", "22": "символа", "111": "символов", "x": "символов",
+	}
+	for n, want := range tests {
+		if got := ruPlural(n, "символ", "символа", "символов"); got != want {
+			t.Errorf("ruPlural(%s): expected %q, got %q", n, want, got)
+		}
+	}
+}
+
+func TestLanguageFrom(t *testing.T) {
+	tests := []struct {
+		header string
+		want   Lang
+	}{
+		{"", English},
+		{"ru", Russian},
+		{"ru-RU,ru;q=0.9,en-US;q=0.8", Russian},
+		{"en-US,en;q=0.9,ru;q=0.8", English},
+		{"de-DE,ru;q=0.5", Russian},
+		{"de-DE,fr;q=0.5", English},
+		{"en;q=0.3, RU;q=0.7", Russian},
+		{"ru;q=0,en;q=0.1", English},
+		{"ru, en", Russian},
+	}
+
+	for _, tt := range tests {
+		if got := LanguageFrom(tt.header); got != tt.want {
+			t.Errorf("LanguageFrom(%q): expected %s, got %s", tt.header, tt.want, got)
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	if got := Text(Russian, MsgValidationFailed); got != "запрос не прошёл проверку" {
+		t.Errorf("Expected the Russian text, got %q", got)
+	}
+	if got := Text(Lang("de"), MsgInvalidBody); got != "request body is not valid JSON" {
+		t.Errorf("Expected the English fallback, got %q", got)
+	}
+}
+
+func TestInstall(t *testing.T) {
+	if err := Install(); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if err := Install(); err != nil {
+		t.Errorf("Expected a second Install to succeed, got %v", err)
+	}
+}
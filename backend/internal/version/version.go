@@ -0,0 +1,15 @@
+// Package version holds build metadata injected at link time:
+//
+//	go build -ldflags "-X github.com/timur-harin/sum25-go-flutter-course/backend/internal/version.Version=1.2.0 \
+//	  -X github.com/timur-harin/sum25-go-flutter-course/backend/internal/version.Commit=$(git rev-parse --short HEAD)"
+package version
+
+// Set via -ldflags -X; the defaults mark a local development build
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
+
+// Service is the name reported by health endpoints
+const Service = "sum25-go-flutter-course-backend"
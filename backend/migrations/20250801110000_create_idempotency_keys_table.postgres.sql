@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE idempotency_keys (
+    id VARCHAR(64) PRIMARY KEY,
+    fingerprint VARCHAR(64) NOT NULL,
+    completed BOOLEAN NOT NULL DEFAULT FALSE,
+    status INTEGER NOT NULL DEFAULT 0,
+    headers TEXT NULL,
+    body BYTEA NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_idempotency_keys_expires_at;
+DROP TABLE idempotency_keys;
+-- +goose StatementEnd
@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    actor_id BIGINT NULL,
+    method VARCHAR(10) NOT NULL,
+    route VARCHAR(255) NOT NULL,
+    path VARCHAR(2048) NOT NULL,
+    resource_id VARCHAR(255) NULL,
+    status INTEGER NOT NULL,
+    outcome VARCHAR(16) NOT NULL,
+    changes TEXT NULL,
+    ip VARCHAR(64) NOT NULL DEFAULT '',
+    user_agent VARCHAR(512) NOT NULL DEFAULT '',
+    request_id VARCHAR(128) NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_audit_log_actor_id ON audit_log(actor_id);
+CREATE INDEX idx_audit_log_resource_id ON audit_log(resource_id);
+CREATE INDEX idx_audit_log_occurred_at ON audit_log(occurred_at);
+
+-- Records are evidence: refuse to change or remove them
+CREATE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+DROP FUNCTION IF EXISTS audit_log_append_only();
+DROP INDEX IF EXISTS idx_audit_log_occurred_at;
+DROP INDEX IF EXISTS idx_audit_log_resource_id;
+DROP INDEX IF EXISTS idx_audit_log_actor_id;
+DROP TABLE audit_log;
+-- +goose StatementEnd
@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE audit_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    occurred_at DATETIME NOT NULL,
+    actor_id INTEGER NULL,
+    method VARCHAR(10) NOT NULL,
+    route VARCHAR(255) NOT NULL,
+    path VARCHAR(2048) NOT NULL,
+    resource_id VARCHAR(255) NULL,
+    status INTEGER NOT NULL,
+    outcome VARCHAR(16) NOT NULL,
+    changes TEXT NULL,
+    ip VARCHAR(64) NOT NULL DEFAULT '',
+    user_agent VARCHAR(512) NOT NULL DEFAULT '',
+    request_id VARCHAR(128) NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_audit_log_actor_id ON audit_log(actor_id);
+CREATE INDEX idx_audit_log_resource_id ON audit_log(resource_id);
+CREATE INDEX idx_audit_log_occurred_at ON audit_log(occurred_at);
+
+-- Records are evidence: refuse to change or remove them
+CREATE TRIGGER audit_log_no_update BEFORE UPDATE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+
+CREATE TRIGGER audit_log_no_delete BEFORE DELETE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS audit_log_no_delete;
+DROP TRIGGER IF EXISTS audit_log_no_update;
+DROP INDEX IF EXISTS idx_audit_log_occurred_at;
+DROP INDEX IF EXISTS idx_audit_log_resource_id;
+DROP INDEX IF EXISTS idx_audit_log_actor_id;
+DROP TABLE audit_log;
+-- +goose StatementEnd
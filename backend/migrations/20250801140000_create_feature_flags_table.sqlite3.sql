@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE feature_flags (
+    name VARCHAR(100) PRIMARY KEY,
+    definition TEXT NOT NULL,
+    updated_at DATETIME NOT NULL,
+    updated_by INTEGER NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE feature_flags;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+// Package migrations embeds the SQL migrations applied by cmd/migrate.
+//
+// Files are named <timestamp>_<name>.sql and use goose-style
+// "-- +goose Up" / "-- +goose Down" sections. When a schema needs
+// dialect-specific SQL, provide <timestamp>_<name>.postgres.sql and
+// <timestamp>_<name>.sqlite3.sql instead of the generic file.
+package migrations
+
+import "embed"
+
+// FS holds every migration file in this directory
+//
+//go:embed *.sql
+var FS embed.FS
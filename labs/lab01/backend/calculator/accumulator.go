@@ -0,0 +1,192 @@
+package calculator
+
+import (
+	"fmt"
+	"math"
+)
+
+// Accumulator computes count, mean, variance, min and max of a stream in
+// constant memory using Welford's algorithm, which stays accurate where
+// the textbook sum-of-squares formula cancels catastrophically. The zero
+// value is ready to use.
+type Accumulator struct {
+	n        int
+	mean, m2 float64
+	min, max float64
+}
+
+// Add records x; NaN and ±Inf are rejected and leave the accumulator unchanged
+func (a *Accumulator) Add(x float64) error {
+	if err := checkValue(x); err != nil {
+		return err
+	}
+	a.n++
+	if a.n == 1 {
+		a.min, a.max = x, x
+	} else {
+		a.min, a.max = math.Min(a.min, x), math.Max(a.max, x)
+	}
+	delta := x - a.mean
+	a.mean += delta / float64(a.n)
+	a.m2 += delta * (x - a.mean)
+	return nil
+}
+
+// Merge folds b into a, as if a had seen every value added to b (Chan et
+// al.'s parallel form of Welford's update)
+func (a *Accumulator) Merge(b *Accumulator) {
+	if b.n == 0 {
+		return
+	}
+	if a.n == 0 {
+		*a = *b
+		return
+	}
+	n := a.n + b.n
+	delta := b.mean - a.mean
+	a.mean += delta * float64(b.n) / float64(n)
+	a.m2 += b.m2 + delta*delta*float64(a.n)*float64(b.n)/float64(n)
+	a.min, a.max = math.Min(a.min, b.min), math.Max(a.max, b.max)
+	a.n = n
+}
+
+// Count returns how many values were added
+func (a *Accumulator) Count() int {
+	return a.n
+}
+
+// Mean returns the mean of the values added
+func (a *Accumulator) Mean() (float64, error) {
+	if a.n == 0 {
+		return 0, ErrEmpty
+	}
+	return a.mean, nil
+}
+
+// Variance returns the population variance
+func (a *Accumulator) Variance() (float64, error) {
+	if a.n == 0 {
+		return 0, ErrEmpty
+	}
+	return a.m2 / float64(a.n), nil
+}
+
+// SampleVariance returns the sample variance; it needs at least two values
+func (a *Accumulator) SampleVariance() (float64, error) {
+	if a.n < 2 {
+		return 0, fmt.Errorf("%w: sample variance needs at least 2 values, have %d", ErrTooFew, a.n)
+	}
+	return a.m2 / float64(a.n-1), nil
+}
+
+// StdDev returns the population standard deviation
+func (a *Accumulator) StdDev() (float64, error) {
+	v, err := a.Variance()
+	return math.Sqrt(v), err
+}
+
+// SampleStdDev returns the sample standard deviation
+func (a *Accumulator) SampleStdDev() (float64, error) {
+	v, err := a.SampleVariance()
+	return math.Sqrt(v), err
+}
+
+// Min returns the smallest value added
+func (a *Accumulator) Min() (float64, error) {
+	if a.n == 0 {
+		return 0, ErrEmpty
+	}
+	return a.min, nil
+}
+
+// Max returns the largest value added
+func (a *Accumulator) Max() (float64, error) {
+	if a.n == 0 {
+		return 0, ErrEmpty
+	}
+	return a.max, nil
+}
+
+// MovingAverage is the streaming form of SimpleMovingAverage
+type MovingAverage struct {
+	window []float64
+	next   int
+	full   bool
+	sum    float64
+}
+
+// NewMovingAverage creates an average over the last window values
+func NewMovingAverage(window int) (*MovingAverage, error) {
+	if window < 1 {
+		return nil, fmt.Errorf("%w: window %d", ErrOutOfRange, window)
+	}
+	return &MovingAverage{window: make([]float64, window)}, nil
+}
+
+// Add records x and returns the mean of the last window values, or of all
+// values while fewer than window have been added. NaN and ±Inf are
+// rejected and leave the average unchanged.
+func (s *MovingAverage) Add(x float64) (float64, error) {
+	if err := checkValue(x); err != nil {
+		return s.Value(), err
+	}
+	s.sum += x - s.window[s.next]
+	s.window[s.next] = x
+	s.next = (s.next + 1) % len(s.window)
+	if s.next == 0 {
+		// Resum once per lap so rounding errors in the running sum cannot build up
+		s.full = true
+		s.sum = 0
+		for _, v := range s.window {
+			s.sum += v
+		}
+	}
+	return s.Value(), nil
+}
+
+// Value returns the current average, 0 before any value is added
+func (s *MovingAverage) Value() float64 {
+	n := len(s.window)
+	if !s.full {
+		n = s.next
+	}
+	if n == 0 {
+		return 0
+	}
+	return s.sum / float64(n)
+}
+
+// ExpMovingAverage is the streaming form of ExponentialMovingAverage. It
+// weights each new value by alpha and the previous average by 1-alpha.
+type ExpMovingAverage struct {
+	alpha  float64
+	value  float64
+	seeded bool
+}
+
+// NewExpMovingAverage creates an EMA; alpha must be in (0, 1]
+func NewExpMovingAverage(alpha float64) (*ExpMovingAverage, error) {
+	if !(alpha > 0 && alpha <= 1) {
+		return nil, fmt.Errorf("%w: alpha %v is not in (0, 1]", ErrOutOfRange, alpha)
+	}
+	return &ExpMovingAverage{alpha: alpha}, nil
+}
+
+// Add records x and returns the updated average; the first value seeds
+// it. NaN and ±Inf are rejected and leave the average unchanged.
+func (e *ExpMovingAverage) Add(x float64) (float64, error) {
+	if err := checkValue(x); err != nil {
+		return e.value, err
+	}
+	if e.seeded {
+		e.value += e.alpha * (x - e.value)
+	} else {
+		e.value, e.seeded = x, true
+	}
+	return e.value, nil
+}
+
+// Value returns the current average, 0 before any value is added
+func (e *ExpMovingAverage) Value() float64 {
+	return e.value
+}
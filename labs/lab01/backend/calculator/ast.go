@@ -0,0 +1,430 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// constants are available to every expression unless a variable shadows them
+var constants = map[string]float64{"pi": math.Pi, "e": math.E}
+
+// node is a parsed expression tree. eval works on float64; evalDecimal
+// works on Decimal under c and falls back to float64 only where no exact
+// result exists, such as sqrt or a fractional power.
+type node interface {
+	eval(vars map[string]float64) (float64, error)
+	evalDecimal(vars map[string]Decimal, c Context) (Decimal, error)
+	walk(fn func(node))
+	String() string
+}
+
+type numNode struct {
+	val  float64
+	text string
+	col  int
+}
+
+func (n *numNode) eval(map[string]float64) (float64, error) { return n.val, nil }
+func (n *numNode) walk(fn func(node))                       { fn(n) }
+func (n *numNode) String() string                           { return strconv.FormatFloat(n.val, 'g', -1, 64) }
+
+// evalDecimal reparses the literal so 0.1 is exactly one tenth
+func (n *numNode) evalDecimal(map[string]Decimal, Context) (Decimal, error) {
+	d, err := ParseDecimal(n.text)
+	if err != nil {
+		return Decimal{}, &ExprError{Column: n.col, Msg: "number " + n.text + " is out of range", Err: ErrDomain}
+	}
+	return d, nil
+}
+
+type varNode struct {
+	name string
+	col  int
+}
+
+func (n *varNode) eval(vars map[string]float64) (float64, error) {
+	if v, ok := vars[n.name]; ok {
+		return v, nil
+	}
+	if v, ok := constants[n.name]; ok {
+		return v, nil
+	}
+	return 0, n.unknown()
+}
+
+func (n *varNode) evalDecimal(vars map[string]Decimal, _ Context) (Decimal, error) {
+	if v, ok := vars[n.name]; ok {
+		return v, nil
+	}
+	if v, ok := constants[n.name]; ok {
+		return DecimalFromFloat(v)
+	}
+	return Decimal{}, n.unknown()
+}
+
+func (n *varNode) unknown() error {
+	return &ExprError{Column: n.col, Msg: "unknown variable " + strconv.Quote(n.name), Err: ErrUnknownVariable}
+}
+
+func (n *varNode) walk(fn func(node)) { fn(n) }
+func (n *varNode) String() string     { return n.name }
+
+type negNode struct {
+	col     int
+	operand node
+}
+
+func (n *negNode) eval(vars map[string]float64) (float64, error) {
+	v, err := n.operand.eval(vars)
+	return -v, err
+}
+
+func (n *negNode) evalDecimal(vars map[string]Decimal, c Context) (Decimal, error) {
+	v, err := n.operand.evalDecimal(vars, c)
+	return v.Neg(), err
+}
+
+func (n *negNode) walk(fn func(node)) {
+	fn(n)
+	n.operand.walk(fn)
+}
+
+func (n *negNode) String() string { return "(-" + n.operand.String() + ")" }
+
+type binaryNode struct {
+	op          string
+	col         int
+	left, right node
+}
+
+func (n *binaryNode) eval(vars map[string]float64) (float64, error) {
+	a, err := n.left.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	b, err := n.right.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	return n.apply(a, b)
+}
+
+func (n *binaryNode) apply(a, b float64) (float64, error) {
+	switch n.op {
+	case "+":
+		return Add(a, b), nil
+	case "-":
+		return Subtract(a, b), nil
+	case "*":
+		return Multiply(a, b), nil
+	case "/":
+		q, err := Divide(a, b)
+		if err != nil {
+			return 0, &ExprError{Column: n.col, Msg: err.Error(), Err: err}
+		}
+		return q, nil
+	case "^":
+		if a == 0 && b < 0 {
+			return 0, n.zeroToNegative()
+		}
+		r := math.Pow(a, b)
+		if math.IsNaN(r) {
+			return 0, &ExprError{Column: n.col, Msg: fmt.Sprintf("%g^%g is not a real number", a, b), Err: ErrDomain}
+		}
+		return r, nil
+	}
+	return 0, &ExprError{Column: n.col, Msg: "unknown operator " + strconv.Quote(n.op)}
+}
+
+func (n *binaryNode) evalDecimal(vars map[string]Decimal, c Context) (Decimal, error) {
+	a, err := n.left.evalDecimal(vars, c)
+	if err != nil {
+		return Decimal{}, err
+	}
+	b, err := n.right.evalDecimal(vars, c)
+	if err != nil {
+		return Decimal{}, err
+	}
+
+	switch n.op {
+	case "+":
+		return c.Add(a, b), nil
+	case "-":
+		return c.Sub(a, b), nil
+	case "*":
+		return c.Mul(a, b), nil
+	case "/":
+		q, err := c.Div(a, b)
+		if err != nil {
+			return Decimal{}, &ExprError{Column: n.col, Msg: err.Error(), Err: err}
+		}
+		return q, nil
+	case "^":
+		if exp, ok := wholeNumber(b); ok {
+			return n.powDecimal(a, exp, c)
+		}
+	}
+	r, err := n.apply(a.Float64(), b.Float64())
+	if err != nil {
+		return Decimal{}, err
+	}
+	return fromFloat(r, n.col, c)
+}
+
+// maxPowerDigits caps the size of an exact power so (9^4096)^4096 fails
+// fast instead of building a number with millions of digits
+const maxPowerDigits = 4 * maxExponent
+
+// powDecimal raises a to a whole power exactly, by repeated squaring
+func (n *binaryNode) powDecimal(a Decimal, exp int, c Context) (Decimal, error) {
+	if a.IsZero() && exp < 0 {
+		return Decimal{}, n.zeroToNegative()
+	}
+	e := max(exp, -exp)
+	// a^e has at most e times the digits of a; a negative power also turns
+	// the fractional digits into whole ones
+	size := len(a.Abs().int().String())
+	if exp < 0 {
+		size = max(size, a.scale)
+	}
+	if e > 1 && size > maxPowerDigits/e {
+		return Decimal{}, &ExprError{Column: n.col, Msg: "result is out of range", Err: ErrDomain}
+	}
+	r, base := NewDecimal(1, 0), a
+	for ; e > 0; e >>= 1 {
+		if e&1 == 1 {
+			r = r.Mul(base)
+		}
+		if e > 1 {
+			base = base.Mul(base)
+		}
+	}
+	if exp < 0 {
+		return c.Div(NewDecimal(1, 0), r)
+	}
+	return r.Round(c.Places, c.Rounding), nil
+}
+
+func (n *binaryNode) zeroToNegative() error {
+	return &ExprError{Column: n.col, Msg: "zero raised to a negative power", Err: ErrDivisionByZero}
+}
+
+func (n *binaryNode) walk(fn func(node)) {
+	fn(n)
+	n.left.walk(fn)
+	n.right.walk(fn)
+}
+
+func (n *binaryNode) String() string {
+	return "(" + n.left.String() + " " + n.op + " " + n.right.String() + ")"
+}
+
+type callNode struct {
+	name string
+	col  int
+	fn   function
+	args []node
+}
+
+func (n *callNode) eval(vars map[string]float64) (float64, error) {
+	args := make([]float64, len(n.args))
+	for i, arg := range n.args {
+		v, err := arg.eval(vars)
+		if err != nil {
+			return 0, err
+		}
+		args[i] = v
+	}
+	r, err := n.fn.call(args)
+	if err != nil {
+		return 0, n.domainError(err)
+	}
+	return r, nil
+}
+
+func (n *callNode) evalDecimal(vars map[string]Decimal, c Context) (Decimal, error) {
+	args := make([]Decimal, len(n.args))
+	for i, arg := range n.args {
+		v, err := arg.evalDecimal(vars, c)
+		if err != nil {
+			return Decimal{}, err
+		}
+		args[i] = v
+	}
+
+	if n.fn.decimal != nil {
+		r, err := n.fn.decimal(args)
+		if err != nil {
+			return Decimal{}, n.domainError(err)
+		}
+		return r.Round(c.Places, c.Rounding), nil
+	}
+
+	floats := make([]float64, len(args))
+	for i, arg := range args {
+		floats[i] = arg.Float64()
+	}
+	r, err := n.fn.call(floats)
+	if err != nil {
+		return Decimal{}, n.domainError(err)
+	}
+	return fromFloat(r, n.col, c)
+}
+
+func (n *callNode) domainError(err error) error {
+	return &ExprError{Column: n.col, Msg: n.name + ": " + err.Error(), Err: ErrDomain}
+}
+
+func (n *callNode) walk(fn func(node)) {
+	fn(n)
+	for _, arg := range n.args {
+		arg.walk(fn)
+	}
+}
+
+func (n *callNode) String() string {
+	args := make([]string, len(n.args))
+	for i, arg := range n.args {
+		args[i] = arg.String()
+	}
+	return n.name + "(" + strings.Join(args, ", ") + ")"
+}
+
+// fromFloat brings a float64 result back into decimal evaluation
+func fromFloat(f float64, col int, c Context) (Decimal, error) {
+	d, err := DecimalFromFloat(f)
+	if err != nil {
+		return Decimal{}, &ExprError{Column: col, Msg: "result is out of range", Err: ErrDomain}
+	}
+	return d.Round(c.Places, c.Rounding), nil
+}
+
+// wholeNumber returns d as an int when it is a whole number of modest size
+func wholeNumber(d Decimal) (int, bool) {
+	if d.Round(0, Floor).Cmp(d) != 0 || d.Abs().Cmp(NewDecimal(maxExponent, 0)) > 0 {
+		return 0, false
+	}
+	return int(d.Float64()), true
+}
+
+// function is a built-in; maxArgs -1 means any number of arguments. decimal,
+// when set, computes the result exactly; otherwise decimal evaluation goes
+// through call.
+type function struct {
+	minArgs, maxArgs int
+	call             func(args []float64) (float64, error)
+	decimal          func(args []Decimal) (Decimal, error)
+}
+
+func (f function) arity() string {
+	switch {
+	case f.maxArgs < 0:
+		return fmt.Sprintf("at least %d argument(s)", f.minArgs)
+	case f.minArgs == f.maxArgs:
+		return fmt.Sprintf("%d argument(s)", f.minArgs)
+	}
+	return fmt.Sprintf("%d to %d arguments", f.minArgs, f.maxArgs)
+}
+
+var errPlaces = errors.New("decimal places must be a whole number")
+
+// maxFloatPlaces bounds round's places in float evaluation; 10^n overflows
+// or underflows a float64 beyond it
+const maxFloatPlaces = 308
+
+var errFloatPlaces = fmt.Errorf("decimal places must be between %d and %d", -maxFloatPlaces, maxFloatPlaces)
+
+var functions = map[string]function{
+	"sqrt": {1, 1, func(a []float64) (float64, error) {
+		if a[0] < 0 {
+			return 0, errors.New("negative argument")
+		}
+		return math.Sqrt(a[0]), nil
+	}, nil},
+	"abs": {1, 1, func(a []float64) (float64, error) {
+		return math.Abs(a[0]), nil
+	}, func(a []Decimal) (Decimal, error) {
+		return a[0].Abs(), nil
+	}},
+	"min": {1, -1, func(a []float64) (float64, error) {
+		m := a[0]
+		for _, v := range a[1:] {
+			m = math.Min(m, v)
+		}
+		return m, nil
+	}, func(a []Decimal) (Decimal, error) {
+		m := a[0]
+		for _, v := range a[1:] {
+			if v.Cmp(m) < 0 {
+				m = v
+			}
+		}
+		return m, nil
+	}},
+	"max": {1, -1, func(a []float64) (float64, error) {
+		m := a[0]
+		for _, v := range a[1:] {
+			m = math.Max(m, v)
+		}
+		return m, nil
+	}, func(a []Decimal) (Decimal, error) {
+		m := a[0]
+		for _, v := range a[1:] {
+			if v.Cmp(m) > 0 {
+				m = v
+			}
+		}
+		return m, nil
+	}},
+	// round(x) rounds half away from zero; round(x, n) keeps n decimal places
+	"round": {1, 2, func(a []float64) (float64, error) {
+		if len(a) == 1 {
+			return math.Round(a[0]), nil
+		}
+		if a[1] != math.Trunc(a[1]) {
+			return 0, errPlaces
+		}
+		if math.Abs(a[1]) > maxFloatPlaces {
+			return 0, errFloatPlaces
+		}
+		scale := math.Pow(10, a[1])
+		scaled := a[0] * scale
+		if math.IsInf(scaled, 0) {
+			// too large to have digits that far right of the point
+			return a[0], nil
+		}
+		return math.Round(scaled) / scale, nil
+	}, func(a []Decimal) (Decimal, error) {
+		places := 0
+		if len(a) == 2 {
+			n, ok := wholeNumber(a[1])
+			if !ok {
+				return Decimal{}, errPlaces
+			}
+			places = n
+		}
+		if places >= 0 {
+			return a[0].Round(places, HalfUp), nil
+		}
+		// round(1234, -2) is 1200
+		unit := NewDecimal(1, places)
+		q, _ := Context{Rounding: HalfUp}.Div(a[0], unit)
+		return q.Mul(unit), nil
+	}},
+	// log(x) is the natural logarithm; log(x, b) uses base b
+	"log": {1, 2, func(a []float64) (float64, error) {
+		if a[0] <= 0 {
+			return 0, errors.New("argument must be positive")
+		}
+		if len(a) == 1 {
+			return math.Log(a[0]), nil
+		}
+		if a[1] <= 0 || a[1] == 1 {
+			return 0, errors.New("base must be positive and not 1")
+		}
+		return math.Log(a[0]) / math.Log(a[1]), nil
+	}, nil},
+}
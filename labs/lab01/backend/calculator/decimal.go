@@ -0,0 +1,344 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidDecimal is returned when a string is not a decimal number
+var ErrInvalidDecimal = errors.New("invalid decimal")
+
+// maxExponent bounds the exponent accepted by ParseDecimal so "1e999999999"
+// cannot allocate an enormous integer
+const maxExponent = 4096
+
+// RoundingMode selects how a result is rounded to a Context's places
+type RoundingMode int
+
+const (
+	// HalfEven rounds to nearest, ties to the even digit (banker's rounding)
+	HalfEven RoundingMode = iota
+	// HalfUp rounds to nearest, ties away from zero
+	HalfUp
+	// Floor rounds toward negative infinity
+	Floor
+	// Ceil rounds toward positive infinity
+	Ceil
+)
+
+func (m RoundingMode) String() string {
+	switch m {
+	case HalfEven:
+		return "half-even"
+	case HalfUp:
+		return "half-up"
+	case Floor:
+		return "floor"
+	case Ceil:
+		return "ceil"
+	}
+	return "RoundingMode(" + strconv.Itoa(int(m)) + ")"
+}
+
+// Decimal is an exact base-10 number: unscaled × 10^-scale. The zero value
+// is 0. Decimals are immutable; every operation returns a new value.
+type Decimal struct {
+	unscaled *big.Int
+	scale    int
+}
+
+// NewDecimal returns unscaled × 10^-scale, e.g. NewDecimal(1999, 2) is 19.99.
+// A negative scale multiplies by a power of ten.
+func NewDecimal(unscaled int64, scale int) Decimal {
+	return newDecimal(big.NewInt(unscaled), scale)
+}
+
+// newDecimal takes ownership of u and keeps the scale non-negative
+func newDecimal(u *big.Int, scale int) Decimal {
+	if scale < 0 {
+		u.Mul(u, pow10(-scale))
+		scale = 0
+	}
+	return Decimal{unscaled: u, scale: scale}
+}
+
+// ParseDecimal parses s exactly. It accepts an optional sign, digits with an
+// optional decimal point and an optional exponent: "-12.50", ".5", "1.2e-3".
+func ParseDecimal(s string) (Decimal, error) {
+	invalid := fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+
+	mantissa, exp := s, 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		mantissa = s[:i]
+		e, err := strconv.Atoi(s[i+1:])
+		if err != nil || e < -maxExponent || e > maxExponent {
+			return Decimal{}, invalid
+		}
+		exp = e
+	}
+
+	neg := false
+	if mantissa != "" && (mantissa[0] == '-' || mantissa[0] == '+') {
+		neg = mantissa[0] == '-'
+		mantissa = mantissa[1:]
+	}
+	intPart, fracPart, _ := strings.Cut(mantissa, ".")
+	digits := intPart + fracPart
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return Decimal{}, invalid
+	}
+
+	u, _ := new(big.Int).SetString(digits, 10)
+	if neg {
+		u.Neg(u)
+	}
+	return newDecimal(u, len(fracPart)-exp), nil
+}
+
+// DecimalFromFloat converts f through its shortest decimal representation,
+// so DecimalFromFloat(0.1) is exactly 0.1 rather than the binary value
+func DecimalFromFloat(f float64) (Decimal, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Decimal{}, fmt.Errorf("%w: %v", ErrInvalidDecimal, f)
+	}
+	return ParseDecimal(strconv.FormatFloat(f, 'g', -1, 64))
+}
+
+func (d Decimal) int() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+	return d.unscaled
+}
+
+// Scale returns the number of digits after the decimal point
+func (d Decimal) Scale() int {
+	return d.scale
+}
+
+// Sign returns -1, 0 or +1
+func (d Decimal) Sign() int {
+	return d.int().Sign()
+}
+
+// IsZero reports whether d is 0 at any scale
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Neg returns -d
+func (d Decimal) Neg() Decimal {
+	return Decimal{unscaled: new(big.Int).Neg(d.int()), scale: d.scale}
+}
+
+// Abs returns |d|
+func (d Decimal) Abs() Decimal {
+	return Decimal{unscaled: new(big.Int).Abs(d.int()), scale: d.scale}
+}
+
+// Cmp compares d and e numerically, ignoring scale: 1.50 equals 1.5
+func (d Decimal) Cmp(e Decimal) int {
+	a, b := align(d, e)
+	return a.Cmp(b)
+}
+
+// Add returns the exact sum d + e
+func (d Decimal) Add(e Decimal) Decimal {
+	a, b := align(d, e)
+	return Decimal{unscaled: a.Add(a, b), scale: max(d.scale, e.scale)}
+}
+
+// Sub returns the exact difference d - e
+func (d Decimal) Sub(e Decimal) Decimal {
+	a, b := align(d, e)
+	return Decimal{unscaled: a.Sub(a, b), scale: max(d.scale, e.scale)}
+}
+
+// Mul returns the exact product d × e
+func (d Decimal) Mul(e Decimal) Decimal {
+	return Decimal{unscaled: new(big.Int).Mul(d.int(), e.int()), scale: d.scale + e.scale}
+}
+
+// Div returns d / e rounded with DefaultContext
+func (d Decimal) Div(e Decimal) (Decimal, error) {
+	return DefaultContext.Div(d, e)
+}
+
+// Round returns d rounded to places digits after the decimal point. A
+// value that already has no more digits than that is returned unchanged.
+func (d Decimal) Round(places int, mode RoundingMode) Decimal {
+	places = max(places, 0)
+	if d.scale <= places {
+		return d
+	}
+	q := roundQuo(d.int(), pow10(d.scale-places), mode)
+	return Decimal{unscaled: q, scale: places}
+}
+
+// Float64 returns the nearest float64
+func (d Decimal) Float64() float64 {
+	f, _ := new(big.Rat).SetFrac(d.int(), pow10(d.scale)).Float64()
+	return f
+}
+
+// String formats d exactly in plain notation, keeping trailing zeros: "-12.50"
+func (d Decimal) String() string {
+	digits := new(big.Int).Abs(d.int()).String()
+	if d.scale > 0 {
+		if len(digits) <= d.scale {
+			digits = strings.Repeat("0", d.scale-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-d.scale] + "." + digits[len(digits)-d.scale:]
+	}
+	if d.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+// trim drops trailing fractional zeros down to minScale
+func (d Decimal) trim(minScale int) Decimal {
+	u, scale := new(big.Int).Set(d.int()), d.scale
+	ten, r := big.NewInt(10), new(big.Int)
+	for scale > minScale {
+		q, m := new(big.Int).QuoRem(u, ten, r)
+		if m.Sign() != 0 {
+			break
+		}
+		u, scale = q, scale-1
+	}
+	return Decimal{unscaled: u, scale: scale}
+}
+
+// Context fixes the precision and rounding of arithmetic. Places is the
+// number of digits kept after the decimal point; negative means zero.
+type Context struct {
+	Places   int
+	Rounding RoundingMode
+}
+
+// DefaultContext is used by Decimal.Div and the *Decimal functions
+var DefaultContext = Context{Places: 16, Rounding: HalfEven}
+
+// Add returns a + b rounded to c.Places
+func (c Context) Add(a, b Decimal) Decimal {
+	return a.Add(b).Round(c.Places, c.Rounding)
+}
+
+// Sub returns a - b rounded to c.Places
+func (c Context) Sub(a, b Decimal) Decimal {
+	return a.Sub(b).Round(c.Places, c.Rounding)
+}
+
+// Mul returns a × b rounded to c.Places
+func (c Context) Mul(a, b Decimal) Decimal {
+	return a.Mul(b).Round(c.Places, c.Rounding)
+}
+
+// Div returns a / b rounded to c.Places. Trailing zeros beyond the scale
+// an exact quotient needs are dropped, so 1/4 is 0.25 rather than
+// 0.2500000000000000.
+func (c Context) Div(a, b Decimal) (Decimal, error) {
+	if b.IsZero() {
+		return Decimal{}, ErrDivisionByZero
+	}
+	places := max(c.Places, 0)
+
+	// a/b × 10^places = A × 10^(places + sb - sa) / B
+	num, den := new(big.Int).Set(a.int()), new(big.Int).Set(b.int())
+	if k := places + b.scale - a.scale; k >= 0 {
+		num.Mul(num, pow10(k))
+	} else {
+		den.Mul(den, pow10(-k))
+	}
+	q := Decimal{unscaled: roundQuo(num, den, c.Rounding), scale: places}
+	return q.trim(min(max(a.scale-b.scale, 0), places)), nil
+}
+
+// roundQuo returns num/den rounded to an integer with mode
+func roundQuo(num, den *big.Int, mode RoundingMode) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+
+	// QuoRem truncates toward zero; step away from zero when the mode asks
+	sign := num.Sign() * den.Sign()
+	away := false
+	switch mode {
+	case Floor:
+		away = sign < 0
+	case Ceil:
+		away = sign > 0
+	case HalfUp, HalfEven:
+		half := new(big.Int).Abs(r)
+		half.Lsh(half, 1)
+		switch half.CmpAbs(den) {
+		case 1:
+			away = true
+		case 0:
+			away = mode == HalfUp || q.Bit(0) == 1
+		}
+	}
+	if away {
+		q.Add(q, big.NewInt(int64(sign)))
+	}
+	return q
+}
+
+// align returns copies of the unscaled values of a and b at a common scale
+func align(a, b Decimal) (*big.Int, *big.Int) {
+	x, y := new(big.Int).Set(a.int()), new(big.Int).Set(b.int())
+	switch {
+	case a.scale < b.scale:
+		x.Mul(x, pow10(b.scale-a.scale))
+	case b.scale < a.scale:
+		y.Mul(y, pow10(a.scale-b.scale))
+	}
+	return x, y
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// AddDecimal is the exact counterpart of Add
+func AddDecimal(a, b Decimal) Decimal {
+	return a.Add(b)
+}
+
+// SubtractDecimal is the exact counterpart of Subtract
+func SubtractDecimal(a, b Decimal) Decimal {
+	return a.Sub(b)
+}
+
+// MultiplyDecimal is the exact counterpart of Multiply
+func MultiplyDecimal(a, b Decimal) Decimal {
+	return a.Mul(b)
+}
+
+// DivideDecimal is the counterpart of Divide, rounded with DefaultContext
+func DivideDecimal(a, b Decimal) (Decimal, error) {
+	return DefaultContext.Div(a, b)
+}
+
+// StringToDecimal is the exact counterpart of StringToFloat
+func StringToDecimal(s string) (Decimal, error) {
+	return ParseDecimal(s)
+}
+
+// DecimalToString is the counterpart of FloatToString. Ties round to even
+// like fmt does, but on the decimal value: "2.675" becomes "2.68" where
+// FloatToString(2.675, 2) gives "2.67".
+func DecimalToString(d Decimal, precision int) string {
+	precision = max(precision, 0)
+	r := d.Round(precision, HalfEven)
+	if r.scale < precision {
+		r = Decimal{unscaled: new(big.Int).Mul(r.int(), pow10(precision-r.scale)), scale: precision}
+	}
+	return r.String()
+}
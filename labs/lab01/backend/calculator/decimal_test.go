@@ -0,0 +1,342 @@
+package calculator
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+)
+
+func mustDecimal(t *testing.T, s string) Decimal {
+	t.Helper()
+	d, err := ParseDecimal(s)
+	if err != nil {
+		t.Fatalf("ParseDecimal(%q) error = %v", s, err)
+	}
+	return d
+}
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{"0", "0", false},
+		{"12.50", "12.50", false},
+		{"-0.001", "-0.001", false},
+		{"+7", "7", false},
+		{".5", "0.5", false},
+		{"5.", "5", false},
+		{"1.2e-3", "0.0012", false},
+		{"1.5E2", "150", false},
+		{"123456789012345678901234567890.123456789", "123456789012345678901234567890.123456789", false},
+		{"", "", true},
+		{"-", "", true},
+		{".", "", true},
+		{"1.2.3", "", true},
+		{"abc", "", true},
+		{"1e", "", true},
+		{"1e99999", "", true},
+		{"NaN", "", true},
+		{"Inf", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseDecimal(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidDecimal) {
+					t.Errorf("ParseDecimal(%q) error = %v, want ErrInvalidDecimal", tt.input, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseDecimal(%q) error = %v", tt.input, err)
+			}
+			if got.String() != tt.expected {
+				t.Errorf("ParseDecimal(%q) = %s, want %s", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	tests := []struct {
+		name     string
+		op       func(a, b Decimal) Decimal
+		a, b     string
+		expected string
+	}{
+		{"add float trap", AddDecimal, "0.1", "0.2", "0.3"},
+		{"add keeps scale", AddDecimal, "1.10", "2.2", "3.30"},
+		{"add negative", AddDecimal, "-5", "3.25", "-1.75"},
+		{"subtract", SubtractDecimal, "1", "0.9", "0.1"},
+		{"subtract below zero", SubtractDecimal, "0.3", "0.7", "-0.4"},
+		{"multiply", MultiplyDecimal, "1.1", "1.1", "1.21"},
+		{"multiply large", MultiplyDecimal, "99999999999999999999", "99999999999999999999", "9999999999999999999800000000000000000001"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.op(mustDecimal(t, tt.a), mustDecimal(t, tt.b)); got.String() != tt.expected {
+				t.Errorf("%s %s = %s, want %s", tt.a, tt.b, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDivideDecimal(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		expected string
+		wantErr  bool
+	}{
+		{"exact", "1", "4", "0.25", false},
+		{"integer", "10", "2", "5", false},
+		{"keeps dividend scale", "10.00", "2", "5.00", false},
+		{"repeating", "1", "3", "0.3333333333333333", false},
+		{"rounds last digit", "2", "3", "0.6666666666666667", false},
+		{"negative", "-1", "8", "-0.125", false},
+		{"divide by zero", "1", "0", "", true},
+		{"divide by zero with scale", "1", "0.00", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DivideDecimal(mustDecimal(t, tt.a), mustDecimal(t, tt.b))
+			if tt.wantErr {
+				if err != ErrDivisionByZero {
+					t.Errorf("Expected ErrDivisionByZero, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got.String() != tt.expected {
+				t.Errorf("DivideDecimal(%s, %s) = %s, want %s", tt.a, tt.b, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRoundingModes(t *testing.T) {
+	tests := []struct {
+		input                         string
+		halfEven, halfUp, floor, ceil string
+	}{
+		{"2.5", "2", "3", "2", "3"},
+		{"3.5", "4", "4", "3", "4"},
+		{"-2.5", "-2", "-3", "-3", "-2"},
+		{"2.4", "2", "2", "2", "3"},
+		{"2.6", "3", "3", "2", "3"},
+		{"-2.6", "-3", "-3", "-3", "-2"},
+		{"7", "7", "7", "7", "7"},
+		{"0.0001", "0", "0", "0", "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			d := mustDecimal(t, tt.input)
+			for mode, want := range map[RoundingMode]string{HalfEven: tt.halfEven, HalfUp: tt.halfUp, Floor: tt.floor, Ceil: tt.ceil} {
+				if got := d.Round(0, mode); got.String() != want {
+					t.Errorf("Round(%s, 0, %v) = %s, want %s", tt.input, mode, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestContext(t *testing.T) {
+	money := Context{Places: 2, Rounding: HalfUp}
+	a, b := mustDecimal(t, "10"), mustDecimal(t, "3")
+
+	q, err := money.Div(a, b)
+	if err != nil || q.String() != "3.33" {
+		t.Errorf("Div(10, 3) = %s, %v, want 3.33", q, err)
+	}
+	if got := money.Mul(mustDecimal(t, "19.99"), mustDecimal(t, "0.075")); got.String() != "1.50" {
+		t.Errorf("Mul(19.99, 0.075) = %s, want 1.50", got)
+	}
+	if got := money.Add(mustDecimal(t, "0.005"), mustDecimal(t, "0.01")); got.String() != "0.02" {
+		t.Errorf("Add(0.005, 0.01) = %s, want 0.02", got)
+	}
+	if got := money.Sub(mustDecimal(t, "1"), mustDecimal(t, "0.333")); got.String() != "0.67" {
+		t.Errorf("Sub(1, 0.333) = %s, want 0.67", got)
+	}
+
+	floor := Context{Places: 0, Rounding: Floor}
+	if q, _ := floor.Div(mustDecimal(t, "-7"), mustDecimal(t, "2")); q.String() != "-4" {
+		t.Errorf("floor Div(-7, 2) = %s, want -4", q)
+	}
+}
+
+func TestDecimalToString(t *testing.T) {
+	tests := []struct {
+		input     string
+		precision int
+		expected  string
+	}{
+		{"2.675", 2, "2.68"},
+		{"2.665", 2, "2.66"},
+		{"3.14159", 2, "3.14"},
+		{"3", 2, "3.00"},
+		{"-1.005", 2, "-1.00"},
+		{"0.5", 0, "0"},
+		{"1.5", 0, "2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := DecimalToString(mustDecimal(t, tt.input), tt.precision); got != tt.expected {
+				t.Errorf("DecimalToString(%s, %d) = %s, want %s", tt.input, tt.precision, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDecimalZeroValue(t *testing.T) {
+	var zero Decimal
+	if zero.String() != "0" || !zero.IsZero() || zero.Float64() != 0 {
+		t.Errorf("zero value = %s, want 0", zero)
+	}
+	if got := zero.Add(NewDecimal(1999, 2)); got.String() != "19.99" {
+		t.Errorf("0 + 19.99 = %s", got)
+	}
+	if got := NewDecimal(5, -2); got.String() != "500" {
+		t.Errorf("NewDecimal(5, -2) = %s, want 500", got)
+	}
+	if mustDecimal(t, "1.50").Cmp(mustDecimal(t, "1.5")) != 0 {
+		t.Error("1.50 and 1.5 should compare equal")
+	}
+}
+
+// The property tests below check the decimal functions against their float
+// counterparts on random operands spanning several orders of magnitude.
+
+var quickConfig = &quick.Config{
+	MaxCount: 2000,
+	Values: func(args []reflect.Value, r *rand.Rand) {
+		for i := range args {
+			f := r.NormFloat64() * math.Pow(10, float64(r.Intn(13)-6))
+			args[i] = reflect.ValueOf(f)
+		}
+	},
+}
+
+func closeTo(got, want float64) bool {
+	return math.Abs(got-want) <= 1e-12*math.Abs(want)+1e-15
+}
+
+func decimalsOf(t *testing.T, fs ...float64) []Decimal {
+	t.Helper()
+	ds := make([]Decimal, len(fs))
+	for i, f := range fs {
+		d, err := DecimalFromFloat(f)
+		if err != nil {
+			t.Fatalf("DecimalFromFloat(%v) error = %v", f, err)
+		}
+		ds[i] = d
+	}
+	return ds
+}
+
+func TestDecimalMatchesFloat(t *testing.T) {
+	tests := []struct {
+		name  string
+		float func(a, b float64) (float64, error)
+		dec   func(a, b Decimal) (Decimal, error)
+	}{
+		{"add",
+			func(a, b float64) (float64, error) { return Add(a, b), nil },
+			func(a, b Decimal) (Decimal, error) { return AddDecimal(a, b), nil }},
+		{"subtract",
+			func(a, b float64) (float64, error) { return Subtract(a, b), nil },
+			func(a, b Decimal) (Decimal, error) { return SubtractDecimal(a, b), nil }},
+		{"multiply",
+			func(a, b float64) (float64, error) { return Multiply(a, b), nil },
+			func(a, b Decimal) (Decimal, error) { return MultiplyDecimal(a, b), nil }},
+		{"divide", Divide, DivideDecimal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			property := func(a, b float64) bool {
+				want, ferr := tt.float(a, b)
+				ds := decimalsOf(t, a, b)
+				got, derr := tt.dec(ds[0], ds[1])
+				if ferr != nil || derr != nil {
+					return ferr == derr
+				}
+				if !closeTo(got.Float64(), want) {
+					t.Logf("%s(%v, %v): decimal %s, float %v", tt.name, a, b, got, want)
+					return false
+				}
+				return true
+			}
+			if err := quick.Check(property, quickConfig); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestDecimalStringRoundTrip(t *testing.T) {
+	property := func(f float64) bool {
+		s := FloatToString(f, 6)
+		want, _ := StringToFloat(s)
+		expected := s
+		if want == 0 {
+			// Decimals have no negative zero
+			expected = strings.TrimPrefix(s, "-")
+		}
+		d, err := StringToDecimal(s)
+		if err != nil || d.String() != expected {
+			t.Logf("StringToDecimal(%q) = %s, %v", s, d, err)
+			return false
+		}
+		return d.Float64() == want
+	}
+	if err := quick.Check(property, quickConfig); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDecimalToStringMatchesFloat(t *testing.T) {
+	property := func(f float64) bool {
+		d := decimalsOf(t, f)[0]
+		for precision := 0; precision <= 4; precision++ {
+			got, _ := StringToFloat(DecimalToString(d, precision))
+			want, _ := StringToFloat(FloatToString(f, precision))
+			// The two may only disagree on a tie, by one unit in the last place
+			if math.Abs(got-want) > math.Pow(10, -float64(precision))*1.000001 {
+				t.Logf("precision %d of %v: decimal %v, float %v", precision, f, got, want)
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(property, quickConfig); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRoundingBounds(t *testing.T) {
+	property := func(f float64) bool {
+		d := decimalsOf(t, f)[0]
+		lo, hi := d.Round(2, Floor), d.Round(2, Ceil)
+		even, up := d.Round(2, HalfEven), d.Round(2, HalfUp)
+		half := NewDecimal(5, 3)
+		return lo.Cmp(d) <= 0 && d.Cmp(hi) <= 0 &&
+			hi.Sub(lo).Cmp(NewDecimal(1, 2)) <= 0 &&
+			even.Sub(d).Abs().Cmp(half) <= 0 && up.Sub(d).Abs().Cmp(half) <= 0
+	}
+	if err := quick.Check(property, quickConfig); err != nil {
+		t.Error(err)
+	}
+}
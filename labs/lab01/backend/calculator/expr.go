@@ -0,0 +1,105 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Errors wrapped by ExprError, for use with errors.Is
+var (
+	ErrUnknownVariable = errors.New("unknown variable")
+	ErrUnknownFunction = errors.New("unknown function")
+	ErrDomain          = errors.New("argument out of domain")
+)
+
+// ExprError is a parse or evaluation error at a 1-based column of the
+// expression. Err, when set, is a sentinel such as ErrDivisionByZero.
+type ExprError struct {
+	Column int
+	Msg    string
+	Err    error
+}
+
+func (e *ExprError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Column, e.Msg)
+}
+
+func (e *ExprError) Unwrap() error {
+	return e.Err
+}
+
+// Expr is a compiled expression that can be evaluated many times
+type Expr struct {
+	src  string
+	root node
+}
+
+// Compile parses src once. Unknown functions and wrong argument counts are
+// reported here; variables are only looked up by Eval.
+func Compile(src string) (*Expr, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseExpression(0)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, &ExprError{Column: tok.col, Msg: "unexpected " + tok.describe()}
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// Eval evaluates the expression with vars. The constants pi and e are
+// available unless vars defines them.
+func (e *Expr) Eval(vars map[string]float64) (float64, error) {
+	return e.root.eval(vars)
+}
+
+// EvalDecimal evaluates the expression with Decimal arithmetic, rounding
+// every step to c. sqrt, log and fractional powers are computed in float64
+// and converted back, since their results are not exact decimals anyway.
+func (e *Expr) EvalDecimal(vars map[string]Decimal, c Context) (Decimal, error) {
+	return e.root.evalDecimal(vars, c)
+}
+
+// Vars returns the names of the variables the expression uses, sorted
+func (e *Expr) Vars() []string {
+	seen := map[string]bool{}
+	e.root.walk(func(n node) {
+		if v, ok := n.(*varNode); ok {
+			seen[v.name] = true
+		}
+	})
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// String returns the expression fully parenthesised, showing how it was parsed
+func (e *Expr) String() string {
+	return e.root.String()
+}
+
+// IsBuiltin reports whether name is a built-in function or constant
+func IsBuiltin(name string) bool {
+	_, fn := functions[name]
+	_, constant := constants[name]
+	return fn || constant
+}
+
+// Eval parses and evaluates src in one step, e.g. Eval("2*(3+4)/x", vars).
+// Use Compile to evaluate the same expression repeatedly.
+func Eval(src string, vars map[string]float64) (float64, error) {
+	expr, err := Compile(src)
+	if err != nil {
+		return 0, err
+	}
+	return expr.Eval(vars)
+}
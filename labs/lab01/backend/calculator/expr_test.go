@@ -0,0 +1,205 @@
+package calculator
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	vars := map[string]float64{"x": 2, "y": -3}
+	tests := []struct {
+		name     string
+		src      string
+		expected float64
+	}{
+		{"example", "2*(3+4)/x", 7},
+		{"precedence", "1 + 2 * 3", 7},
+		{"left associative", "8 - 3 - 2", 3},
+		{"division left associative", "16 / 4 / 2", 2},
+		{"parentheses", "(1 + 2) * 3", 9},
+		{"unary minus", "-x + 5", 3},
+		{"double negation", "--x", 2},
+		{"unary plus", "+x", 2},
+		{"minus binds looser than power", "-2^2", -4},
+		{"power right associative", "2^3^2", 512},
+		{"negative exponent", "2^-1", 0.5},
+		{"variable in power", "y^2", 9},
+		{"decimals and exponents", "1.5e2 + .5", 150.5},
+		{"constants", "round(pi * 100)", 314},
+		{"sqrt", "sqrt(16)", 4},
+		{"abs", "abs(y)", 3},
+		{"min", "min(4, x, 7)", 2},
+		{"max", "max(y, -10)", -3},
+		{"round", "round(2.5)", 3},
+		{"round digits", "round(3.14159, 2)", 3.14},
+		{"round digits of a huge number", "round(1e300, 10)", 1e300},
+		{"natural log", "log(e^3)", 3},
+		{"log base", "log(8, 2)", 3},
+		{"nested calls", "max(sqrt(9), abs(y) + 1)", 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Eval(tt.src, vars)
+			if err != nil {
+				t.Fatalf("Eval(%q) error = %v", tt.src, err)
+			}
+			if math.Abs(got-tt.expected) > 1e-9 {
+				t.Errorf("Eval(%q) = %v, want %v", tt.src, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	vars := map[string]float64{"x": 0}
+	tests := []struct {
+		name   string
+		src    string
+		column int
+		target error
+	}{
+		{"division by zero", "1 + 4 / x", 7, ErrDivisionByZero},
+		{"division by zero in call", "abs(3/(x*2))", 6, ErrDivisionByZero},
+		{"zero to negative power", "x^-1", 2, ErrDivisionByZero},
+		{"unknown variable", "2 * y", 5, ErrUnknownVariable},
+		{"unknown function", "1 + foo(2)", 5, ErrUnknownFunction},
+		{"sqrt of negative", "sqrt(-1)", 1, ErrDomain},
+		{"log of zero", "2 + log(x)", 5, ErrDomain},
+		{"fractional power of negative", "(-8)^0.5", 5, ErrDomain},
+		{"unexpected character", "2 $ 3", 3, nil},
+		{"missing operand", "2 +", 4, nil},
+		{"unclosed parenthesis", "2 * (3 + 4", 5, nil},
+		{"trailing token", "2 3", 3, nil},
+		{"empty", "", 1, nil},
+		{"wrong arity", "sqrt(1, 2)", 1, nil},
+		{"min without arguments", "min()", 1, nil},
+		{"round past float precision", "round(1.5, 400)", 1, ErrDomain},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Eval(tt.src, vars)
+			var exprErr *ExprError
+			if !errors.As(err, &exprErr) {
+				t.Fatalf("Eval(%q) error = %v, want *ExprError", tt.src, err)
+			}
+			if exprErr.Column != tt.column {
+				t.Errorf("Eval(%q) error column = %d, want %d (%v)", tt.src, exprErr.Column, tt.column, err)
+			}
+			if tt.target != nil && !errors.Is(err, tt.target) {
+				t.Errorf("Eval(%q) error = %v, want %v", tt.src, err, tt.target)
+			}
+		})
+	}
+}
+
+func TestCompileOnce(t *testing.T) {
+	expr, err := Compile("x^2 + y*x - 1")
+	if err != nil {
+		t.Fatalf("Compile error = %v", err)
+	}
+
+	tests := []struct {
+		x, y     float64
+		expected float64
+	}{
+		{0, 0, -1},
+		{2, 1, 5},
+		{-3, 2, 2},
+	}
+	for _, tt := range tests {
+		got, err := expr.Eval(map[string]float64{"x": tt.x, "y": tt.y})
+		if err != nil {
+			t.Fatalf("Eval(x=%v, y=%v) error = %v", tt.x, tt.y, err)
+		}
+		if got != tt.expected {
+			t.Errorf("Eval(x=%v, y=%v) = %v, want %v", tt.x, tt.y, got, tt.expected)
+		}
+	}
+
+	if got, want := expr.Vars(), []string{"x", "y"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Vars() = %v, want %v", got, want)
+	}
+	if got, want := expr.String(), "(((x ^ 2) + (y * x)) - 1)"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestVariableShadowsConstant(t *testing.T) {
+	got, err := Eval("e * 2", map[string]float64{"e": 5})
+	if err != nil || got != 10 {
+		t.Errorf("Eval(\"e * 2\") = %v, %v, want 10", got, err)
+	}
+}
+
+func TestEvalDecimal(t *testing.T) {
+	vars := map[string]Decimal{"price": NewDecimal(1999, 2), "qty": NewDecimal(3, 0)}
+	tests := []struct {
+		name     string
+		src      string
+		places   int
+		expected string
+	}{
+		{"float trap", "0.1 + 0.2", 16, "0.3"},
+		{"money", "price * qty", 16, "59.97"},
+		{"division rounds to places", "price / qty", 2, "6.66"},
+		{"whole power is exact", "1.1^10", 16, "2.5937424601"},
+		{"negative power", "2^-2", 16, "0.25"},
+		{"large whole power", "2^4096 / 2^4095", 16, "2"},
+		{"fractional power", "4^0.5", 16, "2"},
+		{"sqrt through float", "sqrt(2)", 4, "1.4142"},
+		{"round half up", "round(2.5)", 16, "3"},
+		{"round negative places", "round(1250, -2)", 16, "1300"},
+		{"min and max", "max(min(3, 1.5), 1.25)", 16, "1.5"},
+		{"abs", "abs(-price)", 16, "19.99"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := Compile(tt.src)
+			if err != nil {
+				t.Fatalf("Compile(%q) error = %v", tt.src, err)
+			}
+			got, err := expr.EvalDecimal(vars, Context{Places: tt.places, Rounding: HalfEven})
+			if err != nil {
+				t.Fatalf("EvalDecimal(%q) error = %v", tt.src, err)
+			}
+			if got.String() != tt.expected {
+				t.Errorf("EvalDecimal(%q) = %s, want %s", tt.src, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEvalDecimalErrors(t *testing.T) {
+	tests := []struct {
+		src    string
+		column int
+		target error
+	}{
+		{"1 / (2 - 2)", 3, ErrDivisionByZero},
+		{"0^-1", 2, ErrDivisionByZero},
+		{"sqrt(-4)", 1, ErrDomain},
+		{"round(1, 0.5)", 1, ErrDomain},
+		{"(9^4096)^4096", 9, ErrDomain},
+		{"0.00001^-4096", 8, ErrDomain},
+		{"total * 2", 1, ErrUnknownVariable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			expr, err := Compile(tt.src)
+			if err != nil {
+				t.Fatalf("Compile(%q) error = %v", tt.src, err)
+			}
+			_, err = expr.EvalDecimal(nil, DefaultContext)
+			var exprErr *ExprError
+			if !errors.As(err, &exprErr) || exprErr.Column != tt.column || !errors.Is(err, tt.target) {
+				t.Errorf("EvalDecimal(%q) error = %v, want %v at column %d", tt.src, err, tt.target, tt.column)
+			}
+		})
+	}
+}
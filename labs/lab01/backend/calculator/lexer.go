@@ -0,0 +1,105 @@
+package calculator
+
+import (
+	"strconv"
+	"unicode"
+)
+
+// tokenKind classifies the pieces of an expression
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokIdent
+	tokOperator
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+// token is one lexeme with its 1-based column in the source
+type token struct {
+	kind tokenKind
+	text string
+	num  float64
+	col  int
+}
+
+func (t token) describe() string {
+	if t.kind == tokEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(t.text)
+}
+
+// tokenize splits src into tokens, ending with a tokEOF token
+func tokenize(src string) ([]token, error) {
+	runes := []rune(src)
+	var tokens []token
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		col := i + 1
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || r == '.':
+			start := i
+			i = scanNumber(runes, i)
+			text := string(runes[start:i])
+			num, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, &ExprError{Column: col, Msg: "invalid number " + strconv.Quote(text)}
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: text, num: num, col: col})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: string(runes[start:i]), col: col})
+		case r == '+' || r == '-' || r == '*' || r == '/' || r == '^':
+			tokens = append(tokens, token{kind: tokOperator, text: string(r), col: col})
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokLParen, text: "(", col: col})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokRParen, text: ")", col: col})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{kind: tokComma, text: ",", col: col})
+			i++
+		default:
+			return nil, &ExprError{Column: col, Msg: "unexpected character " + strconv.QuoteRune(r)}
+		}
+	}
+	return append(tokens, token{kind: tokEOF, col: len(runes) + 1}), nil
+}
+
+// scanNumber returns the end of the number starting at i: digits, an
+// optional fraction and an optional exponent such as 1.5e-3
+func scanNumber(runes []rune, i int) int {
+	digits := func() {
+		for i < len(runes) && unicode.IsDigit(runes[i]) {
+			i++
+		}
+	}
+	digits()
+	if i < len(runes) && runes[i] == '.' {
+		i++
+		digits()
+	}
+	if i < len(runes) && (runes[i] == 'e' || runes[i] == 'E') {
+		j := i + 1
+		if j < len(runes) && (runes[j] == '+' || runes[j] == '-') {
+			j++
+		}
+		// Only an exponent when digits follow, so "2e" is a number and a name
+		if j < len(runes) && unicode.IsDigit(runes[j]) {
+			i = j
+			digits()
+		}
+	}
+	return i
+}
@@ -0,0 +1,152 @@
+package calculator
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// parser is a precedence-climbing parser over a token slice. From loosest
+// to tightest: + and -, * and /, unary minus, ^ (right-associative), so
+// -2^2 is -4 and 2^3^2 is 2^9.
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// binaryPrecedence covers the left-associative operators; ^ is handled by parsePower
+var binaryPrecedence = map[string]int{"+": 1, "-": 1, "*": 2, "/": 2}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// parseExpression parses operands joined by operators of at least minPrec
+func (p *parser) parseExpression(minPrec int) (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		prec, ok := binaryPrecedence[tok.text]
+		if tok.kind != tokOperator || !ok || prec < minPrec {
+			return left, nil
+		}
+		p.next()
+		// prec+1 makes equal operators group to the left: 8-3-2 is (8-3)-2
+		right, err := p.parseExpression(prec + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: tok.text, col: tok.col, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	tok := p.peek()
+	if tok.kind == tokOperator && (tok.text == "-" || tok.text == "+") {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if tok.text == "+" {
+			return operand, nil
+		}
+		return &negNode{col: tok.col, operand: operand}, nil
+	}
+	return p.parsePower()
+}
+
+func (p *parser) parsePower() (node, error) {
+	base, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	tok := p.peek()
+	if tok.kind != tokOperator || tok.text != "^" {
+		return base, nil
+	}
+	p.next()
+	// The exponent may itself be negated or raised: 2^-1, 2^3^2
+	exp, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return &binaryNode{op: "^", col: tok.col, left: base, right: exp}, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokNumber:
+		return &numNode{val: tok.num, text: tok.text, col: tok.col}, nil
+	case tokIdent:
+		if p.peek().kind == tokLParen {
+			return p.parseCall(tok)
+		}
+		return &varNode{name: tok.text, col: tok.col}, nil
+	case tokLParen:
+		inner, err := p.parseExpression(0)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectClose(tok); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	}
+	return nil, &ExprError{Column: tok.col, Msg: "unexpected " + tok.describe()}
+}
+
+// parseCall parses the argument list of the function named by ident
+func (p *parser) parseCall(ident token) (node, error) {
+	fn, ok := functions[ident.text]
+	if !ok {
+		return nil, &ExprError{Column: ident.col, Msg: "unknown function " + strconv.Quote(ident.text), Err: ErrUnknownFunction}
+	}
+	open := p.next()
+
+	var args []node
+	if p.peek().kind != tokRParen {
+		for {
+			arg, err := p.parseExpression(0)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.peek().kind != tokComma {
+				break
+			}
+			p.next()
+		}
+	}
+	if err := p.expectClose(open); err != nil {
+		return nil, err
+	}
+
+	if len(args) < fn.minArgs || (fn.maxArgs >= 0 && len(args) > fn.maxArgs) {
+		return nil, &ExprError{Column: ident.col, Msg: fmt.Sprintf("%s takes %s, got %d", ident.text, fn.arity(), len(args))}
+	}
+	return &callNode{name: ident.text, col: ident.col, fn: fn, args: args}, nil
+}
+
+// expectClose consumes the ')' matching open
+func (p *parser) expectClose(open token) error {
+	tok := p.next()
+	switch tok.kind {
+	case tokRParen:
+		return nil
+	case tokEOF:
+		return &ExprError{Column: open.col, Msg: "unclosed parenthesis"}
+	}
+	return &ExprError{Column: tok.col, Msg: "expected \")\", found " + tok.describe()}
+}
@@ -0,0 +1,280 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// Predefined statistics errors
+var (
+	ErrEmpty          = errors.New("empty series")
+	ErrNaN            = errors.New("series contains NaN")
+	ErrInf            = errors.New("series contains Inf")
+	ErrTooFew         = errors.New("not enough values")
+	ErrLengthMismatch = errors.New("series lengths differ")
+	ErrOutOfRange     = errors.New("parameter out of range")
+)
+
+// check rejects empty series and series containing NaN or ±Inf; mixed
+// infinities would otherwise turn the mean and variance into NaN
+func check(xs []float64) error {
+	if len(xs) == 0 {
+		return ErrEmpty
+	}
+	for i, x := range xs {
+		if err := checkValue(x); err != nil {
+			return fmt.Errorf("%w at index %d", err, i)
+		}
+	}
+	return nil
+}
+
+func checkValue(x float64) error {
+	switch {
+	case math.IsNaN(x):
+		return ErrNaN
+	case math.IsInf(x, 0):
+		return ErrInf
+	}
+	return nil
+}
+
+// summarize feeds xs through an Accumulator
+func summarize(xs []float64) (*Accumulator, error) {
+	if err := check(xs); err != nil {
+		return nil, err
+	}
+	var acc Accumulator
+	for _, x := range xs {
+		acc.Add(x)
+	}
+	return &acc, nil
+}
+
+// Mean returns the arithmetic mean
+func Mean(xs []float64) (float64, error) {
+	acc, err := summarize(xs)
+	if err != nil {
+		return 0, err
+	}
+	return acc.Mean()
+}
+
+// Variance returns the population variance
+func Variance(xs []float64) (float64, error) {
+	acc, err := summarize(xs)
+	if err != nil {
+		return 0, err
+	}
+	return acc.Variance()
+}
+
+// SampleVariance returns the sample variance, dividing by n-1; it needs at
+// least two values
+func SampleVariance(xs []float64) (float64, error) {
+	acc, err := summarize(xs)
+	if err != nil {
+		return 0, err
+	}
+	return acc.SampleVariance()
+}
+
+// StdDev returns the population standard deviation
+func StdDev(xs []float64) (float64, error) {
+	v, err := Variance(xs)
+	return math.Sqrt(v), err
+}
+
+// SampleStdDev returns the sample standard deviation
+func SampleStdDev(xs []float64) (float64, error) {
+	v, err := SampleVariance(xs)
+	return math.Sqrt(v), err
+}
+
+// Min returns the smallest value
+func Min(xs []float64) (float64, error) {
+	if err := check(xs); err != nil {
+		return 0, err
+	}
+	return slices.Min(xs), nil
+}
+
+// Max returns the largest value
+func Max(xs []float64) (float64, error) {
+	if err := check(xs); err != nil {
+		return 0, err
+	}
+	return slices.Max(xs), nil
+}
+
+// Median returns the middle value, averaging the two middle values of an
+// even-length series
+func Median(xs []float64) (float64, error) {
+	return Percentile(xs, 50, Linear)
+}
+
+// Mode returns the most frequent values in ascending order; every value is
+// returned when none repeats
+func Mode(xs []float64) ([]float64, error) {
+	if err := check(xs); err != nil {
+		return nil, err
+	}
+	counts := make(map[float64]int, len(xs))
+	best := 0
+	for _, x := range xs {
+		counts[x]++
+		best = max(best, counts[x])
+	}
+	var modes []float64
+	for x, n := range counts {
+		if n == best {
+			modes = append(modes, x)
+		}
+	}
+	slices.Sort(modes)
+	return modes, nil
+}
+
+// Interpolation selects how Percentile picks a value that falls between
+// two elements of the sorted series. The names follow numpy.
+type Interpolation int
+
+const (
+	// Linear interpolates between the neighbours (Hyndman-Fan type 7, the
+	// default in numpy, R and spreadsheets)
+	Linear Interpolation = iota
+	// Lower takes the smaller neighbour
+	Lower
+	// Higher takes the larger neighbour
+	Higher
+	// Nearest takes the closer neighbour, the even index on a tie
+	Nearest
+	// Midpoint averages the neighbours
+	Midpoint
+)
+
+// Percentile returns the p-th percentile, 0 <= p <= 100, of xs. xs is not
+// modified.
+func Percentile(xs []float64, p float64, method Interpolation) (float64, error) {
+	if err := check(xs); err != nil {
+		return 0, err
+	}
+	if !(p >= 0 && p <= 100) {
+		return 0, fmt.Errorf("%w: percentile %v is not between 0 and 100", ErrOutOfRange, p)
+	}
+	sorted := slices.Clone(xs)
+	slices.Sort(sorted)
+
+	// h is the fractional index of the percentile in sorted
+	h := float64(len(sorted)-1) * p / 100
+	lo, hi := sorted[int(math.Floor(h))], sorted[int(math.Ceil(h))]
+	switch method {
+	case Linear:
+		return lo + (h-math.Floor(h))*(hi-lo), nil
+	case Lower:
+		return lo, nil
+	case Higher:
+		return hi, nil
+	case Nearest:
+		return sorted[int(math.RoundToEven(h))], nil
+	case Midpoint:
+		return (lo + hi) / 2, nil
+	}
+	return 0, fmt.Errorf("%w: unknown interpolation %d", ErrOutOfRange, method)
+}
+
+// SimpleMovingAverage returns the mean of each window of consecutive
+// values: len(xs)-window+1 results, the first covering xs[:window]
+func SimpleMovingAverage(xs []float64, window int) ([]float64, error) {
+	if err := check(xs); err != nil {
+		return nil, err
+	}
+	if window < 1 || window > len(xs) {
+		return nil, fmt.Errorf("%w: window %d for %d values", ErrOutOfRange, window, len(xs))
+	}
+	sma, _ := NewMovingAverage(window)
+	out := make([]float64, 0, len(xs)-window+1)
+	for i, x := range xs {
+		avg, _ := sma.Add(x) // check has vetted every value
+		if i >= window-1 {
+			out = append(out, avg)
+		}
+	}
+	return out, nil
+}
+
+// ExponentialMovingAverage returns the EMA after each value, seeded with
+// the first value. alpha, the weight of the newest value, is in (0, 1];
+// 2/(n+1) matches an n-period average.
+func ExponentialMovingAverage(xs []float64, alpha float64) ([]float64, error) {
+	if err := check(xs); err != nil {
+		return nil, err
+	}
+	ema, err := NewExpMovingAverage(alpha)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]float64, len(xs))
+	for i, x := range xs {
+		out[i], _ = ema.Add(x)
+	}
+	return out, nil
+}
+
+// Regression is a least-squares line y = Slope*x + Intercept
+type Regression struct {
+	Slope     float64
+	Intercept float64
+	// R2 is the coefficient of determination, 1 for a perfect fit
+	R2 float64
+}
+
+// LinearRegression fits a line to the points (xs[i], ys[i]). It needs at
+// least two points, and fails with ErrDivisionByZero when every x is equal.
+func LinearRegression(xs, ys []float64) (Regression, error) {
+	if len(xs) != len(ys) {
+		return Regression{}, fmt.Errorf("%w: %d x values, %d y values", ErrLengthMismatch, len(xs), len(ys))
+	}
+	if err := check(xs); err != nil {
+		return Regression{}, err
+	}
+	if err := check(ys); err != nil {
+		return Regression{}, err
+	}
+	if len(xs) < 2 {
+		return Regression{}, fmt.Errorf("%w: regression needs at least 2 points", ErrTooFew)
+	}
+
+	// Sums of deviations from the means are more accurate than raw sums
+	mx, _ := Mean(xs)
+	my, _ := Mean(ys)
+	var sxx, syy, sxy float64
+	for i := range xs {
+		dx, dy := xs[i]-mx, ys[i]-my
+		sxx += dx * dx
+		syy += dy * dy
+		sxy += dx * dy
+	}
+	slope, err := Divide(sxy, sxx)
+	if err != nil {
+		return Regression{}, fmt.Errorf("%w: every x is %v", err, mx)
+	}
+	r2 := 1.0
+	if syy != 0 {
+		r2 = sxy * sxy / (sxx * syy)
+	}
+	return Regression{Slope: slope, Intercept: my - slope*mx, R2: r2}, nil
+}
+
+// Trend returns the regression slope of ys against their indexes 0, 1, 2, ...
+// i.e. the average change per step of an evenly spaced series
+func Trend(ys []float64) (float64, error) {
+	xs := make([]float64, len(ys))
+	for i := range xs {
+		xs[i] = float64(i)
+	}
+	r, err := LinearRegression(xs, ys)
+	return r.Slope, err
+}
@@ -0,0 +1,302 @@
+package calculator
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(1, math.Abs(b))
+}
+
+func TestSummaryStatistics(t *testing.T) {
+	xs := []float64{2, 4, 4, 4, 5, 5, 7, 9}
+	tests := []struct {
+		name     string
+		fn       func([]float64) (float64, error)
+		expected float64
+	}{
+		{"mean", Mean, 5},
+		{"median", Median, 4.5},
+		{"variance", Variance, 4},
+		{"sample variance", SampleVariance, 32.0 / 7},
+		{"stddev", StdDev, 2},
+		{"sample stddev", SampleStdDev, math.Sqrt(32.0 / 7)},
+		{"min", Min, 2},
+		{"max", Max, 9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.fn(xs)
+			if err != nil {
+				t.Fatalf("%s error = %v", tt.name, err)
+			}
+			if !approx(got, tt.expected) {
+				t.Errorf("%s(%v) = %v, want %v", tt.name, xs, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestInvalidSeries(t *testing.T) {
+	fns := map[string]func([]float64) (float64, error){
+		"Mean": Mean, "Median": Median, "Variance": Variance, "SampleVariance": SampleVariance,
+		"StdDev": StdDev, "SampleStdDev": SampleStdDev, "Min": Min, "Max": Max, "Trend": Trend,
+	}
+	for name, fn := range fns {
+		if _, err := fn(nil); !errors.Is(err, ErrEmpty) {
+			t.Errorf("%s(nil) error = %v, want ErrEmpty", name, err)
+		}
+		if _, err := fn([]float64{1, math.NaN(), 3}); !errors.Is(err, ErrNaN) {
+			t.Errorf("%s with NaN error = %v, want ErrNaN", name, err)
+		}
+		if _, err := fn([]float64{math.Inf(1), 2, math.Inf(-1)}); !errors.Is(err, ErrInf) {
+			t.Errorf("%s with Inf error = %v, want ErrInf", name, err)
+		}
+	}
+
+	if _, err := Mode(nil); !errors.Is(err, ErrEmpty) {
+		t.Errorf("Mode(nil) error = %v, want ErrEmpty", err)
+	}
+	if _, err := SampleVariance([]float64{1}); !errors.Is(err, ErrTooFew) {
+		t.Errorf("SampleVariance of one value error = %v, want ErrTooFew", err)
+	}
+	if _, err := SimpleMovingAverage([]float64{math.NaN()}, 1); !errors.Is(err, ErrNaN) {
+		t.Errorf("SimpleMovingAverage with NaN error = %v, want ErrNaN", err)
+	}
+	if _, err := ExponentialMovingAverage(nil, 0.5); !errors.Is(err, ErrEmpty) {
+		t.Errorf("ExponentialMovingAverage(nil) error = %v, want ErrEmpty", err)
+	}
+}
+
+func TestMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		xs       []float64
+		expected []float64
+	}{
+		{"single mode", []float64{1, 2, 2, 3}, []float64{2}},
+		{"multimodal", []float64{3, 1, 3, 1, 2}, []float64{1, 3}},
+		{"no repeats", []float64{3, 1, 2}, []float64{1, 2, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Mode(tt.xs)
+			if err != nil || !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Mode(%v) = %v, %v, want %v", tt.xs, got, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	// Expected values match numpy.percentile([1, 2, 3, 4, 10], p, method=...)
+	xs := []float64{10, 1, 4, 2, 3}
+	tests := []struct {
+		p        float64
+		method   Interpolation
+		expected float64
+	}{
+		{0, Linear, 1},
+		{100, Linear, 10},
+		{50, Linear, 3},
+		{90, Linear, 7.6},
+		{40, Linear, 2.6},
+		{40, Lower, 2},
+		{40, Higher, 3},
+		{40, Nearest, 3},
+		{12.5, Nearest, 1}, // index 0.5 ties to the even index
+		{40, Midpoint, 2.5},
+		{90, Midpoint, 7},
+	}
+
+	for _, tt := range tests {
+		got, err := Percentile(xs, tt.p, tt.method)
+		if err != nil || !approx(got, tt.expected) {
+			t.Errorf("Percentile(%v, %v, %d) = %v, %v, want %v", xs, tt.p, tt.method, got, err, tt.expected)
+		}
+	}
+
+	if xs[0] != 10 {
+		t.Error("Percentile must not reorder its input")
+	}
+	for _, p := range []float64{-1, 101, math.NaN()} {
+		if _, err := Percentile(xs, p, Linear); !errors.Is(err, ErrOutOfRange) {
+			t.Errorf("Percentile(p=%v) error = %v, want ErrOutOfRange", p, err)
+		}
+	}
+	if _, err := Percentile(xs, 50, Interpolation(42)); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("unknown interpolation error = %v, want ErrOutOfRange", err)
+	}
+}
+
+func TestMovingAverages(t *testing.T) {
+	xs := []float64{1, 2, 3, 4, 5, 6}
+
+	sma, err := SimpleMovingAverage(xs, 3)
+	if want := []float64{2, 3, 4, 5}; err != nil || !reflect.DeepEqual(sma, want) {
+		t.Errorf("SimpleMovingAverage(%v, 3) = %v, %v, want %v", xs, sma, err, want)
+	}
+	for _, window := range []int{0, 7} {
+		if _, err := SimpleMovingAverage(xs, window); !errors.Is(err, ErrOutOfRange) {
+			t.Errorf("SimpleMovingAverage(window=%d) error = %v, want ErrOutOfRange", window, err)
+		}
+	}
+
+	ema, err := ExponentialMovingAverage([]float64{10, 20, 20, 0}, 0.5)
+	if want := []float64{10, 15, 17.5, 8.75}; err != nil || !reflect.DeepEqual(ema, want) {
+		t.Errorf("ExponentialMovingAverage = %v, %v, want %v", ema, err, want)
+	}
+	for _, alpha := range []float64{0, 1.5, math.NaN()} {
+		if _, err := ExponentialMovingAverage(xs, alpha); !errors.Is(err, ErrOutOfRange) {
+			t.Errorf("ExponentialMovingAverage(alpha=%v) error = %v, want ErrOutOfRange", alpha, err)
+		}
+	}
+}
+
+func TestStreamingMovingAverages(t *testing.T) {
+	ma, _ := NewMovingAverage(2)
+	if ma.Value() != 0 {
+		t.Errorf("empty Value() = %v, want 0", ma.Value())
+	}
+	for i, tt := range []struct{ x, expected float64 }{{4, 4}, {6, 5}, {10, 8}, {0, 5}} {
+		if got, err := ma.Add(tt.x); err != nil || got != tt.expected {
+			t.Errorf("step %d: Add(%v) = %v, %v, want %v", i, tt.x, got, err, tt.expected)
+		}
+	}
+	if got, err := ma.Add(math.NaN()); !errors.Is(err, ErrNaN) || got != 5 {
+		t.Errorf("Add(NaN) = %v, %v, want 5, ErrNaN", got, err)
+	}
+	if got, err := ma.Add(math.Inf(1)); !errors.Is(err, ErrInf) || got != 5 {
+		t.Errorf("Add(+Inf) = %v, %v, want 5, ErrInf", got, err)
+	}
+	if got, _ := ma.Add(2); got != 1 {
+		t.Errorf("Add(2) after rejected values = %v, want 1", got)
+	}
+
+	ema, _ := NewExpMovingAverage(0.25)
+	if _, err := ema.Add(math.NaN()); !errors.Is(err, ErrNaN) {
+		t.Errorf("EMA Add(NaN) error = %v, want ErrNaN", err)
+	}
+	ema.Add(8)
+	if got, err := ema.Add(math.Inf(-1)); !errors.Is(err, ErrInf) || got != 8 {
+		t.Errorf("EMA Add(-Inf) = %v, %v, want 8, ErrInf", got, err)
+	}
+	if got, _ := ema.Add(0); got != 6 {
+		t.Errorf("EMA = %v, want 6", got)
+	}
+	if _, err := NewMovingAverage(0); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("NewMovingAverage(0) error = %v, want ErrOutOfRange", err)
+	}
+}
+
+func TestAccumulator(t *testing.T) {
+	var acc Accumulator
+	if _, err := acc.Mean(); !errors.Is(err, ErrEmpty) {
+		t.Errorf("empty Mean() error = %v, want ErrEmpty", err)
+	}
+	for _, x := range []float64{2, 4, 4, 4, 5, 5, 7, 9} {
+		acc.Add(x)
+	}
+	if err := acc.Add(math.NaN()); !errors.Is(err, ErrNaN) {
+		t.Errorf("Add(NaN) error = %v, want ErrNaN", err)
+	}
+	if err := acc.Add(math.Inf(-1)); !errors.Is(err, ErrInf) {
+		t.Errorf("Add(-Inf) error = %v, want ErrInf", err)
+	}
+
+	mean, _ := acc.Mean()
+	sd, _ := acc.StdDev()
+	lo, _ := acc.Min()
+	hi, _ := acc.Max()
+	if acc.Count() != 8 || mean != 5 || sd != 2 || lo != 2 || hi != 9 {
+		t.Errorf("got count %d mean %v stddev %v min %v max %v", acc.Count(), mean, sd, lo, hi)
+	}
+}
+
+func TestAccumulatorMerge(t *testing.T) {
+	xs := []float64{1.5, 2.5, 8, -3, 4, 4, 10, 0.25, 7}
+	var whole, left, right, empty Accumulator
+	for i, x := range xs {
+		whole.Add(x)
+		if i < 4 {
+			left.Add(x)
+		} else {
+			right.Add(x)
+		}
+	}
+	left.Merge(&right)
+	left.Merge(&empty)
+	empty.Merge(&left)
+
+	for _, acc := range []*Accumulator{&left, &empty} {
+		wm, _ := whole.Mean()
+		wv, _ := whole.SampleVariance()
+		m, _ := acc.Mean()
+		v, _ := acc.SampleVariance()
+		lo, _ := acc.Min()
+		hi, _ := acc.Max()
+		if acc.Count() != len(xs) || !approx(m, wm) || !approx(v, wv) || lo != -3 || hi != 10 {
+			t.Errorf("merged = count %d mean %v var %v, want %d %v %v", acc.Count(), m, v, len(xs), wm, wv)
+		}
+	}
+}
+
+// Welford's update keeps its precision on values with a large common
+// offset, where sum(x²)/n - mean² would return 0 or a negative number
+func TestVarianceNumericalStability(t *testing.T) {
+	xs := []float64{1e9 + 4, 1e9 + 7, 1e9 + 13, 1e9 + 16}
+	got, err := SampleVariance(xs)
+	if err != nil || !approx(got, 30) {
+		t.Errorf("SampleVariance = %v, %v, want 30", got, err)
+	}
+}
+
+func TestLinearRegression(t *testing.T) {
+	tests := []struct {
+		name   string
+		xs, ys []float64
+		want   Regression
+	}{
+		{"perfect fit", []float64{1, 2, 3, 4}, []float64{3, 5, 7, 9}, Regression{Slope: 2, Intercept: 1, R2: 1}},
+		{"flat", []float64{1, 2, 3}, []float64{4, 4, 4}, Regression{Slope: 0, Intercept: 4, R2: 1}},
+		{"noisy", []float64{1, 2, 3, 4, 5}, []float64{2, 4, 5, 4, 5}, Regression{Slope: 0.6, Intercept: 2.2, R2: 0.6}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LinearRegression(tt.xs, tt.ys)
+			if err != nil {
+				t.Fatalf("LinearRegression error = %v", err)
+			}
+			if !approx(got.Slope, tt.want.Slope) || !approx(got.Intercept, tt.want.Intercept) || !approx(got.R2, tt.want.R2) {
+				t.Errorf("LinearRegression = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	errTests := []struct {
+		name   string
+		xs, ys []float64
+		target error
+	}{
+		{"length mismatch", []float64{1, 2}, []float64{1}, ErrLengthMismatch},
+		{"one point", []float64{1}, []float64{1}, ErrTooFew},
+		{"vertical", []float64{2, 2, 2}, []float64{1, 2, 3}, ErrDivisionByZero},
+		{"NaN", []float64{1, 2}, []float64{1, math.NaN()}, ErrNaN},
+		{"empty", nil, nil, ErrEmpty},
+	}
+	for _, tt := range errTests {
+		if _, err := LinearRegression(tt.xs, tt.ys); !errors.Is(err, tt.target) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.target)
+		}
+	}
+
+	if slope, err := Trend([]float64{10, 12, 14, 16}); err != nil || !approx(slope, 2) {
+		t.Errorf("Trend = %v, %v, want 2", slope, err)
+	}
+}
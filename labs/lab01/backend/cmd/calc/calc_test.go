@@ -0,0 +1,178 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"lab01/calculator"
+)
+
+func newTestSession(t *testing.T, mode string) *session {
+	t.Helper()
+	s, err := newSession(mode, 16)
+	if err != nil {
+		t.Fatalf("newSession error = %v", err)
+	}
+	return s
+}
+
+func TestSessionExec(t *testing.T) {
+	s := newTestSession(t, modeFloat)
+	tests := []struct {
+		line     string
+		expected string
+		wantErr  bool
+	}{
+		{"2*(3+4)/2", "7", false},
+		{"ans * 2", "14", false},
+		{"let x = 0.1", "0.1", false},
+		{"x + 0.2", "0.3", false},
+		{":precision 17", "", false},
+		{"x + 0.2", "0.30000000000000004", false},
+		{":mode decimal", "", false},
+		{"x + 0.2", "0.3", false},
+		{"let total = 1.10 + 2.20", "3.30", false},
+		{":precision 2", "", false},
+		{"total / 3", "1.10", false},
+		{"10 / 3", "3.33", false},
+		{"let ans = 1", "", true},
+		{"let pi = 3", "", true},
+		{"let 2x = 1", "", true},
+		{"let y", "", true},
+		{":mode hex", "", true},
+		{":precision -1", "", true},
+		{":bogus", "", true},
+	}
+
+	for _, tt := range tests {
+		res, err := s.exec(tt.line)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("exec(%q) = %+v, want error", tt.line, res)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("exec(%q) error = %v", tt.line, err)
+		}
+		if res.Result != tt.expected {
+			t.Errorf("exec(%q) = %q, want %q", tt.line, res.Result, tt.expected)
+		}
+	}
+
+	if got := s.vars["total"].String(); got != "3.30" {
+		t.Errorf("total = %s, want 3.30", got)
+	}
+	if res, _ := s.exec(":vars"); !strings.Contains(res.Output, "x = 0.1") {
+		t.Errorf(":vars = %q, want x listed", res.Output)
+	}
+	if _, err := s.exec(":quit"); !errors.Is(err, errQuit) {
+		t.Errorf(":quit error = %v, want errQuit", err)
+	}
+}
+
+func TestSessionErrorColumns(t *testing.T) {
+	s := newTestSession(t, modeDecimal)
+	tests := []struct {
+		line   string
+		column int
+		target error
+	}{
+		{"1 + 2/0", 6, calculator.ErrDivisionByZero},
+		{"let z = 1 + 2/0", 14, calculator.ErrDivisionByZero},
+		{"  let z=q", 9, calculator.ErrUnknownVariable},
+		{"let z = nope(1)", 9, calculator.ErrUnknownFunction},
+	}
+
+	for _, tt := range tests {
+		_, err := s.exec(tt.line)
+		var exprErr *calculator.ExprError
+		if !errors.As(err, &exprErr) || exprErr.Column != tt.column || !errors.Is(err, tt.target) {
+			t.Errorf("exec(%q) error = %v, want %v at column %d", tt.line, err, tt.target, tt.column)
+		}
+	}
+	if _, ok := s.vars["z"]; ok {
+		t.Error("a failed let must not assign the variable")
+	}
+}
+
+func TestBatchText(t *testing.T) {
+	s := newTestSession(t, modeFloat)
+	in := strings.NewReader("1 + 2\n\n# comment\nlet r = 2\npi * r^2 / 0\nr * 3\n")
+	var out strings.Builder
+
+	failed, err := batch(s, in, &out, "text")
+	if err != nil {
+		t.Fatalf("batch error = %v", err)
+	}
+	if failed != 1 {
+		t.Errorf("failed = %d, want 1", failed)
+	}
+	want := "3\nr = 2\npi * r^2 / 0\n         ^\nerror: column 10: division by zero\n6\n"
+	if out.String() != want {
+		t.Errorf("output =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestBatchJSON(t *testing.T) {
+	s := newTestSession(t, modeDecimal)
+	in := strings.NewReader("0.1 + 0.2\nlet n = (1\n")
+	var out strings.Builder
+
+	failed, err := batch(s, in, &out, "json")
+	if err != nil {
+		t.Fatalf("batch error = %v", err)
+	}
+	want := `{"input":"0.1 + 0.2","result":"0.3"}` + "\n" +
+		`{"input":"let n = (1","name":"n","error":"column 9: unclosed parenthesis","column":9}` + "\n"
+	if failed != 1 || out.String() != want {
+		t.Errorf("batch = %d,\n%s\nwant 1,\n%s", failed, out.String(), want)
+	}
+}
+
+func TestREPLHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	h, err := loadHistory(path)
+	if err != nil {
+		t.Fatalf("loadHistory error = %v", err)
+	}
+
+	var out strings.Builder
+	in := strings.NewReader("let a = 5\na * 2\n!1\n!9\n:history 2\n1/\n:quit\nnot reached\n")
+	if err := repl(newTestSession(t, modeFloat), h, in, &out); err != nil {
+		t.Fatalf("repl error = %v", err)
+	}
+
+	for _, want := range []string{
+		"> a = 5\n",
+		"> 10\n",
+		"> > let a = 5\na = 5\n",
+		`error: no history entry "9"`,
+		"    3  let a = 5\n    4  :history 2\n",
+		// Input is not echoed, so the caret follows the prompt here
+		">     ^\nerror: column 3: unexpected end of expression\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read history: %v", err)
+	}
+	if got, want := string(data), "let a = 5\na * 2\nlet a = 5\n:history 2\n1/\n:quit\n"; got != want {
+		t.Errorf("history file = %q, want %q", got, want)
+	}
+
+	reloaded, err := loadHistory(path)
+	if err != nil {
+		t.Fatalf("loadHistory error = %v", err)
+	}
+	if entry, ok := reloaded.entry(2); !ok || entry != "a * 2" {
+		t.Errorf("entry(2) = %q, %v, want a * 2", entry, ok)
+	}
+}
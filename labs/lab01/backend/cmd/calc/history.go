@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxHistory is how many entries are loaded from the history file
+const maxHistory = 1000
+
+// history is the list of REPL inputs, appended to a file as they are
+// entered so it survives restarts. An empty path keeps it in memory only.
+type history struct {
+	path    string
+	entries []string
+}
+
+// defaultHistoryPath is ~/.calc_history, or "" when there is no home directory
+func defaultHistoryPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".calc_history")
+}
+
+// loadHistory reads the last maxHistory entries of path; a missing file is
+// an empty history
+func loadHistory(path string) (*history, error) {
+	h := &history{path: path}
+	if path == "" {
+		return h, nil
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("calc: open history: %w", err)
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if line := sc.Text(); line != "" {
+			h.entries = append(h.entries, line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("calc: read history: %w", err)
+	}
+	if len(h.entries) > maxHistory {
+		h.entries = h.entries[len(h.entries)-maxHistory:]
+	}
+	return h, nil
+}
+
+// add records line and appends it to the file
+func (h *history) add(line string) error {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return nil
+	}
+	h.entries = append(h.entries, line)
+	if h.path == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("calc: write history: %w", err)
+	}
+	if _, err := fmt.Fprintln(f, line); err != nil {
+		f.Close()
+		return fmt.Errorf("calc: write history: %w", err)
+	}
+	return f.Close()
+}
+
+// entry returns input n, counting from 1
+func (h *history) entry(n int) (string, bool) {
+	if n < 1 || n > len(h.entries) {
+		return "", false
+	}
+	return h.entries[n-1], true
+}
+
+// last returns up to n of the most recent entries with their numbers
+func (h *history) last(n int) string {
+	start := max(len(h.entries)-n, 0)
+	var b strings.Builder
+	for i := start; i < len(h.entries); i++ {
+		fmt.Fprintf(&b, "%5d  %s\n", i+1, h.entries[i])
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
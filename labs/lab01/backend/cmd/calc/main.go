@@ -0,0 +1,69 @@
+// Command calc is an interactive calculator built on the calculator package.
+//
+// Run it in a terminal for a REPL with variables, ans and persistent
+// history. With -f, or when stdin is not a terminal, it evaluates one
+// expression per line and prints the results as text or JSON:
+//
+//	echo '2*(3+4)/2' | go run ./cmd/calc
+//	go run ./cmd/calc -mode decimal -format json -f prices.txt
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+)
+
+func main() {
+	mode := flag.String("mode", modeFloat, "arithmetic: float or decimal")
+	precision := flag.Int("precision", 16, "digits after the decimal point")
+	file := flag.String("f", "", "evaluate the expressions in this file instead of starting the REPL")
+	format := flag.String("format", "text", "batch output: text or json")
+	historyPath := flag.String("history", defaultHistoryPath(), "REPL history file; empty keeps history in memory")
+	flag.Parse()
+	log.SetFlags(0)
+	log.SetPrefix("calc: ")
+
+	if *format != "text" && *format != "json" {
+		log.Fatalf("format %q is not one of text, json", *format)
+	}
+	s, err := newSession(*mode, *precision)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var in io.Reader = os.Stdin
+	if *file != "" {
+		f, err := os.Open(*file)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		in = f
+	} else if isTerminal(os.Stdin) {
+		h, err := loadHistory(*historyPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("calc: type :help for commands, :quit or Ctrl-D to exit")
+		if err := repl(s, h, os.Stdin, os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	failed, err := batch(s, in, os.Stdout, *format)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
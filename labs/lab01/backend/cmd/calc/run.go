@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"lab01/calculator"
+)
+
+const prompt = "> "
+
+// repl reads lines from in until EOF or :quit. !n re-runs history entry n
+// and :history [n] lists entries; everything else goes to the session.
+func repl(s *session, h *history, in io.Reader, out io.Writer) error {
+	sc := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(out, prompt)
+		if !sc.Scan() {
+			fmt.Fprintln(out)
+			return sc.Err()
+		}
+		line := sc.Text()
+
+		if n, ok := strings.CutPrefix(strings.TrimSpace(line), "!"); ok {
+			i, err := strconv.Atoi(n)
+			entry, found := h.entry(i)
+			if err != nil || !found {
+				fmt.Fprintf(out, "error: no history entry %q\n", n)
+				continue
+			}
+			line = entry
+			fmt.Fprintln(out, prompt+line)
+		}
+		if err := h.add(line); err != nil {
+			fmt.Fprintf(out, "warning: %v\n", err)
+		}
+
+		if fields := strings.Fields(line); len(fields) > 0 && fields[0] == ":history" {
+			n := 20
+			if len(fields) > 1 {
+				if v, err := strconv.Atoi(fields[1]); err == nil && v > 0 {
+					n = v
+				}
+			}
+			if list := h.last(n); list != "" {
+				fmt.Fprintln(out, list)
+			}
+			continue
+		}
+
+		res, err := s.exec(line)
+		if errors.Is(err, errQuit) {
+			return nil
+		}
+		if err != nil {
+			writeError(out, len(prompt), line, err)
+			continue
+		}
+		writeResult(out, res)
+	}
+}
+
+// batch evaluates every line of in and writes one result per line as text
+// or JSON. It returns how many lines failed; :quit stops early.
+func batch(s *session, in io.Reader, out io.Writer, format string) (int, error) {
+	enc := json.NewEncoder(out)
+	failed := 0
+	sc := bufio.NewScanner(in)
+	for sc.Scan() {
+		line := sc.Text()
+		res, err := s.exec(line)
+		if errors.Is(err, errQuit) {
+			break
+		}
+		if err != nil {
+			failed++
+		} else if res.Result == "" && res.Output == "" {
+			continue // blank line or comment
+		}
+
+		if format == "json" {
+			if err := enc.Encode(jsonResult(res, err)); err != nil {
+				return failed, err
+			}
+			continue
+		}
+		if err != nil {
+			fmt.Fprintln(out, line)
+			writeError(out, 0, line, err)
+			continue
+		}
+		writeResult(out, res)
+	}
+	return failed, sc.Err()
+}
+
+// jsonLine is one batch result in JSON output; results are strings so
+// decimal mode keeps every digit
+type jsonLine struct {
+	result
+	Error  string `json:"error,omitempty"`
+	Column int    `json:"column,omitempty"`
+}
+
+func jsonResult(res result, err error) jsonLine {
+	line := jsonLine{result: res}
+	if err != nil {
+		line.Error = err.Error()
+		var exprErr *calculator.ExprError
+		if errors.As(err, &exprErr) {
+			line.Column = exprErr.Column
+		}
+	}
+	return line
+}
+
+func writeResult(out io.Writer, res result) {
+	switch {
+	case res.Output != "":
+		fmt.Fprintln(out, res.Output)
+	case res.Name != "":
+		fmt.Fprintf(out, "%s = %s\n", res.Name, res.Result)
+	case res.Result != "":
+		fmt.Fprintln(out, res.Result)
+	}
+}
+
+// writeError prints err; an expression error also gets a caret under the
+// failing column of line, which is shown indent runes to the right
+func writeError(out io.Writer, indent int, line string, err error) {
+	var exprErr *calculator.ExprError
+	if errors.As(err, &exprErr) {
+		// Keep tabs so the caret lines up with the echoed input
+		pad := []rune(strings.Repeat(" ", indent))
+		for i, r := range []rune(line) {
+			if i >= exprErr.Column-1 {
+				break
+			}
+			if r == '\t' {
+				pad = append(pad, '\t')
+			} else {
+				pad = append(pad, ' ')
+			}
+		}
+		fmt.Fprintln(out, string(pad)+"^")
+	}
+	fmt.Fprintf(out, "error: %v\n", err)
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"lab01/calculator"
+)
+
+// Evaluation modes
+const (
+	modeFloat   = "float"
+	modeDecimal = "decimal"
+)
+
+// ansVar holds the last result
+const ansVar = "ans"
+
+// errQuit is returned by exec for :quit
+var errQuit = errors.New("quit")
+
+// session is the calculator state shared by the REPL and batch mode.
+// Variables are kept as Decimals in both modes so switching modes keeps
+// them; float mode converts on the way in and out.
+type session struct {
+	mode      string
+	precision int
+	vars      map[string]calculator.Decimal
+}
+
+func newSession(mode string, precision int) (*session, error) {
+	s := &session{vars: map[string]calculator.Decimal{}}
+	if err := s.setMode(mode); err != nil {
+		return nil, err
+	}
+	if err := s.setPrecision(precision); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// result is the outcome of one input line. Name is set for assignments;
+// Output is set for commands that print something other than a value.
+type result struct {
+	Input  string `json:"input"`
+	Name   string `json:"name,omitempty"`
+	Result string `json:"result,omitempty"`
+	Output string `json:"output,omitempty"`
+}
+
+// exec runs one line: a :command, a "let name = expr" assignment or an
+// expression. Expression errors are *calculator.ExprError with the column
+// counted from the start of line.
+func (s *session) exec(line string) (result, error) {
+	res := result{Input: line}
+	text := strings.TrimSpace(line)
+	if text == "" || strings.HasPrefix(text, "#") {
+		return res, nil
+	}
+	if strings.HasPrefix(text, ":") {
+		out, err := s.command(strings.Fields(text[1:]))
+		res.Output = out
+		return res, err
+	}
+
+	// Columns are reported against line, so remember where src starts
+	src, offset := line, 0
+	if name, rest, ok := cutLet(line); ok {
+		if rest == "" {
+			return res, errors.New("usage: let name = expression")
+		}
+		if !validName(name) {
+			return res, fmt.Errorf("invalid variable name %q", name)
+		}
+		if name == ansVar || calculator.IsBuiltin(name) {
+			return res, fmt.Errorf("%q is reserved", name)
+		}
+		res.Name = name
+		offset = len([]rune(line)) - len([]rune(rest))
+		src = rest
+	}
+
+	v, err := s.eval(src)
+	if err != nil {
+		var exprErr *calculator.ExprError
+		if errors.As(err, &exprErr) && offset > 0 {
+			shifted := *exprErr
+			shifted.Column += offset
+			return res, &shifted
+		}
+		return res, err
+	}
+
+	s.vars[ansVar] = v
+	if res.Name != "" {
+		s.vars[res.Name] = v
+	}
+	res.Result = s.format(v)
+	return res, nil
+}
+
+func (s *session) eval(src string) (calculator.Decimal, error) {
+	expr, err := calculator.Compile(src)
+	if err != nil {
+		return calculator.Decimal{}, err
+	}
+	if s.mode == modeDecimal {
+		return expr.EvalDecimal(s.vars, calculator.Context{Places: s.precision, Rounding: calculator.HalfEven})
+	}
+
+	vars := make(map[string]float64, len(s.vars))
+	for name, v := range s.vars {
+		vars[name] = v.Float64()
+	}
+	f, err := expr.Eval(vars)
+	if err != nil {
+		return calculator.Decimal{}, err
+	}
+	d, err := calculator.DecimalFromFloat(f)
+	if err != nil {
+		return calculator.Decimal{}, fmt.Errorf("result %v is not a finite number", f)
+	}
+	return d, nil
+}
+
+// format shows v the way the current mode computed it. Float results are
+// rounded to the precision with trailing zeros dropped; decimal results
+// are already rounded and keep their scale, so 1.10 + 2.20 is 3.30.
+func (s *session) format(v calculator.Decimal) string {
+	if s.mode == modeDecimal {
+		return v.String()
+	}
+	out := v.Round(s.precision, calculator.HalfEven).String()
+	if strings.Contains(out, ".") {
+		out = strings.TrimRight(strings.TrimRight(out, "0"), ".")
+	}
+	return out
+}
+
+func (s *session) command(args []string) (string, error) {
+	if len(args) == 0 {
+		return "", errors.New("missing command, try :help")
+	}
+	switch args[0] {
+	case "help", "h":
+		return helpText, nil
+	case "quit", "q", "exit":
+		return "", errQuit
+	case "mode":
+		if len(args) == 1 {
+			return s.mode, nil
+		}
+		if err := s.setMode(args[1]); err != nil {
+			return "", err
+		}
+		return "mode " + s.mode, nil
+	case "precision":
+		if len(args) == 1 {
+			return strconv.Itoa(s.precision), nil
+		}
+		n, err := strconv.Atoi(args[1])
+		if err != nil {
+			return "", fmt.Errorf("precision %q is not a number", args[1])
+		}
+		if err := s.setPrecision(n); err != nil {
+			return "", err
+		}
+		return "precision " + args[1], nil
+	case "vars":
+		var b strings.Builder
+		for _, name := range slices.Sorted(maps.Keys(s.vars)) {
+			fmt.Fprintf(&b, "%s = %s\n", name, s.format(s.vars[name]))
+		}
+		return strings.TrimSuffix(b.String(), "\n"), nil
+	}
+	return "", fmt.Errorf("unknown command :%s, try :help", args[0])
+}
+
+func (s *session) setMode(mode string) error {
+	if mode != modeFloat && mode != modeDecimal {
+		return fmt.Errorf("mode %q is not one of float, decimal", mode)
+	}
+	s.mode = mode
+	return nil
+}
+
+func (s *session) setPrecision(n int) error {
+	if n < 0 || n > 100 {
+		return fmt.Errorf("precision %d is not between 0 and 100", n)
+	}
+	s.precision = n
+	return nil
+}
+
+const helpText = `Expressions: + - * / ^, parentheses, sqrt abs min max round log, pi, e
+  let x = expr   assign a variable; ans is the last result
+  :mode [float|decimal]
+  :precision [n] digits after the decimal point
+  :vars          list variables
+  :history [n]   show the last n inputs; !n runs input n again
+  :quit`
+
+// cutLet splits "let name = expr"; rest is empty when the "=" is missing
+func cutLet(line string) (name, rest string, ok bool) {
+	trimmed := strings.TrimLeftFunc(line, unicode.IsSpace)
+	after, found := strings.CutPrefix(trimmed, "let")
+	if !found || after == "" || !unicode.IsSpace(rune(after[0])) {
+		return "", "", false
+	}
+	name, rest, _ = strings.Cut(after, "=")
+	return strings.TrimSpace(name), rest, true
+}
+
+func validName(name string) bool {
+	for i, r := range name {
+		if !(unicode.IsLetter(r) || r == '_' || (i > 0 && unicode.IsDigit(r))) {
+			return false
+		}
+	}
+	return name != ""
+}
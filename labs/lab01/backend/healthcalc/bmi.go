@@ -0,0 +1,45 @@
+package healthcalc
+
+// BMICategory is the WHO adult weight status for a BMI
+type BMICategory string
+
+// WHO categories; obesity is split into classes I to III
+const (
+	Underweight   BMICategory = "underweight"
+	NormalWeight  BMICategory = "normal"
+	Overweight    BMICategory = "overweight"
+	ObeseClassI   BMICategory = "obese class I"
+	ObeseClassII  BMICategory = "obese class II"
+	ObeseClassIII BMICategory = "obese class III"
+)
+
+var bmiFormula = mustCompile("weight / (height / 100)^2")
+
+// BMI returns the body mass index, kg/m²
+func BMI(weight Kilograms, height Centimeters) (float64, error) {
+	if !validWeight(weight) {
+		return 0, ErrInvalidWeight
+	}
+	if !validHeight(height) {
+		return 0, ErrInvalidHeight
+	}
+	return evaluate(bmiFormula, map[string]float64{"weight": float64(weight), "height": float64(height)})
+}
+
+// CategoryOf returns the WHO category of an adult BMI. Boundaries belong
+// to the higher category, so 25 is overweight.
+func CategoryOf(bmi float64) BMICategory {
+	switch {
+	case bmi < 18.5:
+		return Underweight
+	case bmi < 25:
+		return NormalWeight
+	case bmi < 30:
+		return Overweight
+	case bmi < 35:
+		return ObeseClassI
+	case bmi < 40:
+		return ObeseClassII
+	}
+	return ObeseClassIII
+}
@@ -0,0 +1,91 @@
+package healthcalc
+
+import (
+	"errors"
+
+	"lab01/calculator"
+)
+
+// ErrUnknownFormula is returned for a BMRFormula or ActivityLevel outside the constants below
+var ErrUnknownFormula = errors.New("unknown formula")
+
+// BMRFormula selects an equation for basal metabolic rate
+type BMRFormula int
+
+const (
+	// MifflinStJeor is the 1990 Mifflin-St Jeor equation, the usual default
+	MifflinStJeor BMRFormula = iota
+	// HarrisBenedict is the Harris-Benedict equation as revised by Roza and Shizgal (1984)
+	HarrisBenedict
+)
+
+// Formulas per sex, with weight in kg, height in cm and age in years
+var bmrFormulas = map[BMRFormula]map[Sex]*calculator.Expr{
+	MifflinStJeor: {
+		Male:   mustCompile("10*weight + 6.25*height - 5*age + 5"),
+		Female: mustCompile("10*weight + 6.25*height - 5*age - 161"),
+	},
+	HarrisBenedict: {
+		Male:   mustCompile("88.362 + 13.397*weight + 4.799*height - 5.677*age"),
+		Female: mustCompile("447.593 + 9.247*weight + 3.098*height - 4.330*age"),
+	},
+}
+
+// BMR returns the basal metabolic rate in kcal/day
+func BMR(p Person, formula BMRFormula) (float64, error) {
+	if err := p.Validate(); err != nil {
+		return 0, err
+	}
+	exprs, ok := bmrFormulas[formula]
+	if !ok {
+		return 0, ErrUnknownFormula
+	}
+	return evaluate(exprs[p.Sex], map[string]float64{
+		"weight": float64(p.Weight),
+		"height": float64(p.Height),
+		"age":    float64(p.Age),
+	})
+}
+
+// ActivityLevel is how active someone is outside resting metabolism
+type ActivityLevel string
+
+// Activity levels with the usual TDEE multipliers
+const (
+	Sedentary        ActivityLevel = "sedentary"         // little or no exercise
+	LightlyActive    ActivityLevel = "lightly_active"    // exercise 1-3 days a week
+	ModeratelyActive ActivityLevel = "moderately_active" // exercise 3-5 days a week
+	VeryActive       ActivityLevel = "very_active"       // hard exercise 6-7 days a week
+	ExtraActive      ActivityLevel = "extra_active"      // physical job or training twice a day
+)
+
+var activityFactors = map[ActivityLevel]float64{
+	Sedentary:        1.2,
+	LightlyActive:    1.375,
+	ModeratelyActive: 1.55,
+	VeryActive:       1.725,
+	ExtraActive:      1.9,
+}
+
+// ActivityFactor returns the multiplier TDEE applies to BMR for level
+func ActivityFactor(level ActivityLevel) (float64, error) {
+	f, ok := activityFactors[level]
+	if !ok {
+		return 0, ErrUnknownFormula
+	}
+	return f, nil
+}
+
+// TDEE returns total daily energy expenditure in kcal/day: BMR times the
+// activity factor
+func TDEE(p Person, formula BMRFormula, level ActivityLevel) (float64, error) {
+	factor, err := ActivityFactor(level)
+	if err != nil {
+		return 0, err
+	}
+	bmr, err := BMR(p, formula)
+	if err != nil {
+		return 0, err
+	}
+	return calculator.Multiply(bmr, factor), nil
+}
@@ -0,0 +1,77 @@
+// Package healthcalc implements the health formulas used by the final
+// project app: BMI, BMR, TDEE, daily water intake and heart-rate zones.
+// Formulas are written out as calculator expressions so they read like the
+// published equations they come from.
+package healthcalc
+
+import (
+	"errors"
+	"fmt"
+
+	"lab01/calculator"
+)
+
+// Predefined errors
+var (
+	ErrInvalidAge    = errors.New("invalid age: must be between 1 and 120 years")
+	ErrInvalidWeight = errors.New("invalid weight: must be between 1 and 500 kg")
+	ErrInvalidHeight = errors.New("invalid height: must be between 30 and 280 cm")
+	ErrInvalidSex    = errors.New("invalid sex: must be male or female")
+)
+
+// Sex selects the sex-specific coefficients of the BMR formulas
+type Sex string
+
+// Sexes the formulas have coefficients for
+const (
+	Male   Sex = "male"
+	Female Sex = "female"
+)
+
+// Person holds the inputs shared by the BMR formulas
+type Person struct {
+	Sex    Sex
+	Age    int
+	Weight Kilograms
+	Height Centimeters
+}
+
+// Validate checks that every field is in a range the formulas were fitted on
+func (p Person) Validate() error {
+	if p.Sex != Male && p.Sex != Female {
+		return ErrInvalidSex
+	}
+	if !validAge(p.Age) {
+		return ErrInvalidAge
+	}
+	if !validWeight(p.Weight) {
+		return ErrInvalidWeight
+	}
+	if !validHeight(p.Height) {
+		return ErrInvalidHeight
+	}
+	return nil
+}
+
+func validAge(age int) bool          { return age >= 1 && age <= 120 }
+func validWeight(w Kilograms) bool   { return w >= 1 && w <= 500 }
+func validHeight(h Centimeters) bool { return h >= 30 && h <= 280 }
+
+// mustCompile compiles a formula at init; the sources are constants, so an
+// error is a bug
+func mustCompile(src string) *calculator.Expr {
+	expr, err := calculator.Compile(src)
+	if err != nil {
+		panic(fmt.Sprintf("healthcalc: formula %q: %v", src, err))
+	}
+	return expr
+}
+
+// evaluate runs a formula on inputs that have already been validated
+func evaluate(expr *calculator.Expr, vars map[string]float64) (float64, error) {
+	v, err := expr.Eval(vars)
+	if err != nil {
+		return 0, fmt.Errorf("healthcalc: %s: %w", expr, err)
+	}
+	return v, nil
+}
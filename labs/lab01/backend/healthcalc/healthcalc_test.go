@@ -0,0 +1,251 @@
+package healthcalc
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// Reference values below are worked from the published equations: WHO and
+// CDC for BMI, Mifflin et al. (1990), Roza and Shizgal (1984), Tanaka et
+// al. (2001) and the Karvonen method for heart-rate reserve.
+
+func TestBMI(t *testing.T) {
+	tests := []struct {
+		name     string
+		weight   Kilograms
+		height   Centimeters
+		expected float64
+		category BMICategory
+	}{
+		{"metric adult", 70, 175, 22.857, NormalWeight},
+		// CDC example: 150 lb at 5'5" is 24.96
+		{"CDC imperial example", Pounds(150).Kilograms(), Inches(65).Centimeters(), 24.96, NormalWeight},
+		{"underweight", 50, 170, 17.301, Underweight},
+		{"overweight", 85, 175, 27.755, Overweight},
+		{"obese class I", 95, 170, 32.872, ObeseClassI},
+		{"obese class II", 110, 172, 37.182, ObeseClassII},
+		{"obese class III", 130, 165, 47.750, ObeseClassIII},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BMI(tt.weight, tt.height)
+			if err != nil {
+				t.Fatalf("BMI(%v, %v) error = %v", tt.weight, tt.height, err)
+			}
+			if math.Abs(got-tt.expected) > 0.005 {
+				t.Errorf("BMI(%v, %v) = %.3f, want %.3f", tt.weight, tt.height, got, tt.expected)
+			}
+			if c := CategoryOf(got); c != tt.category {
+				t.Errorf("CategoryOf(%.3f) = %s, want %s", got, c, tt.category)
+			}
+		})
+	}
+
+	if _, err := BMI(0, 175); !errors.Is(err, ErrInvalidWeight) {
+		t.Errorf("BMI(0, 175) error = %v, want ErrInvalidWeight", err)
+	}
+	if _, err := BMI(70, 0); !errors.Is(err, ErrInvalidHeight) {
+		t.Errorf("BMI(70, 0) error = %v, want ErrInvalidHeight", err)
+	}
+}
+
+func TestCategoryBoundaries(t *testing.T) {
+	tests := []struct {
+		bmi      float64
+		expected BMICategory
+	}{
+		{18.49, Underweight},
+		{18.5, NormalWeight},
+		{24.99, NormalWeight},
+		{25, Overweight},
+		{30, ObeseClassI},
+		{35, ObeseClassII},
+		{40, ObeseClassIII},
+	}
+
+	for _, tt := range tests {
+		if got := CategoryOf(tt.bmi); got != tt.expected {
+			t.Errorf("CategoryOf(%v) = %s, want %s", tt.bmi, got, tt.expected)
+		}
+	}
+}
+
+func TestBMR(t *testing.T) {
+	man := Person{Sex: Male, Age: 25, Weight: 70, Height: 175}
+	woman := Person{Sex: Female, Age: 30, Weight: 60, Height: 165}
+	tests := []struct {
+		name     string
+		person   Person
+		formula  BMRFormula
+		expected float64
+	}{
+		{"Mifflin-St Jeor male", man, MifflinStJeor, 1673.75},
+		{"Mifflin-St Jeor female", woman, MifflinStJeor, 1320.25},
+		{"Harris-Benedict male", man, HarrisBenedict, 1724.052},
+		{"Harris-Benedict female", woman, HarrisBenedict, 1383.683},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BMR(tt.person, tt.formula)
+			if err != nil {
+				t.Fatalf("BMR error = %v", err)
+			}
+			if math.Abs(got-tt.expected) > 1e-6 {
+				t.Errorf("BMR(%+v) = %v, want %v", tt.person, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBMRInvalid(t *testing.T) {
+	valid := Person{Sex: Female, Age: 30, Weight: 60, Height: 165}
+	tests := []struct {
+		name    string
+		modify  func(p *Person)
+		wantErr error
+	}{
+		{"sex", func(p *Person) { p.Sex = "" }, ErrInvalidSex},
+		{"age", func(p *Person) { p.Age = 0 }, ErrInvalidAge},
+		{"weight", func(p *Person) { p.Weight = -1 }, ErrInvalidWeight},
+		{"height", func(p *Person) { p.Height = 500 }, ErrInvalidHeight},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := valid
+			tt.modify(&p)
+			if _, err := BMR(p, MifflinStJeor); err != tt.wantErr {
+				t.Errorf("BMR error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+	if _, err := BMR(valid, BMRFormula(9)); err != ErrUnknownFormula {
+		t.Errorf("BMR with unknown formula error = %v, want ErrUnknownFormula", err)
+	}
+}
+
+func TestTDEE(t *testing.T) {
+	p := Person{Sex: Male, Age: 25, Weight: 70, Height: 175}
+	tests := []struct {
+		level    ActivityLevel
+		expected float64
+	}{
+		{Sedentary, 2008.5},
+		{LightlyActive, 2301.40625},
+		{ModeratelyActive, 2594.3125},
+		{VeryActive, 2887.21875},
+		{ExtraActive, 3180.125},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.level), func(t *testing.T) {
+			got, err := TDEE(p, MifflinStJeor, tt.level)
+			if err != nil {
+				t.Fatalf("TDEE error = %v", err)
+			}
+			if math.Abs(got-tt.expected) > 1e-6 {
+				t.Errorf("TDEE(%s) = %v, want %v", tt.level, got, tt.expected)
+			}
+		})
+	}
+	if _, err := TDEE(p, MifflinStJeor, "couch"); err != ErrUnknownFormula {
+		t.Errorf("TDEE with unknown level error = %v, want ErrUnknownFormula", err)
+	}
+}
+
+func TestWaterIntake(t *testing.T) {
+	tests := []struct {
+		name     string
+		weight   Kilograms
+		exercise int
+		expected Milliliters
+		wantErr  error
+	}{
+		{"rest day", 70, 0, 2450, nil},
+		{"one hour of exercise", 70, 60, 3150, nil},
+		{"imperial input", Pounds(132).Kilograms(), 30, 2445.597, nil},
+		{"negative exercise", 70, -5, 0, ErrInvalidExercise},
+		{"invalid weight", 0, 0, 0, ErrInvalidWeight},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := WaterIntake(tt.weight, tt.exercise)
+			if err != tt.wantErr {
+				t.Fatalf("WaterIntake error = %v, want %v", err, tt.wantErr)
+			}
+			if math.Abs(float64(got-tt.expected)) > 0.01 {
+				t.Errorf("WaterIntake(%v, %d) = %v, want %v", tt.weight, tt.exercise, got, tt.expected)
+			}
+		})
+	}
+
+	if got := Milliliters(2450).FluidOunces(); math.Abs(float64(got)-82.844) > 0.001 {
+		t.Errorf("2450 ml = %v fl oz, want 82.844", got)
+	}
+}
+
+func TestMaxHeartRate(t *testing.T) {
+	tests := []struct {
+		age      int
+		formula  MaxHRFormula
+		expected int
+	}{
+		{40, Tanaka, 180},
+		{30, Tanaka, 187},
+		{20, Tanaka, 194},
+		{40, Fox, 180},
+		{30, Fox, 190},
+	}
+
+	for _, tt := range tests {
+		got, err := MaxHeartRate(tt.age, tt.formula)
+		if err != nil || got != tt.expected {
+			t.Errorf("MaxHeartRate(%d, %d) = %d, %v, want %d", tt.age, tt.formula, got, err, tt.expected)
+		}
+	}
+	if _, err := MaxHeartRate(0, Tanaka); err != ErrInvalidAge {
+		t.Errorf("MaxHeartRate(0) error = %v, want ErrInvalidAge", err)
+	}
+}
+
+func TestHeartRateZones(t *testing.T) {
+	tests := []struct {
+		name     string
+		max      int
+		resting  int
+		expected [][2]int
+	}{
+		{"percent of max", 180, 0, [][2]int{{90, 107}, {108, 125}, {126, 143}, {144, 161}, {162, 180}}},
+		{"Karvonen", 180, 60, [][2]int{{120, 131}, {132, 143}, {144, 155}, {156, 167}, {168, 180}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zones, err := HeartRateZones(tt.max, tt.resting)
+			if err != nil {
+				t.Fatalf("HeartRateZones error = %v", err)
+			}
+			got := make([][2]int, len(zones))
+			for i, z := range zones {
+				got[i] = [2]int{z.Min, z.Max}
+				if z.Number != i+1 {
+					t.Errorf("zone %d numbered %d", i+1, z.Number)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("HeartRateZones(%d, %d) = %v, want %v", tt.max, tt.resting, got, tt.expected)
+			}
+		})
+	}
+
+	for _, rates := range [][2]int{{90, 0}, {180, 20}, {180, 180}} {
+		if _, err := HeartRateZones(rates[0], rates[1]); err != ErrInvalidHeartRate {
+			t.Errorf("HeartRateZones(%d, %d) error = %v, want ErrInvalidHeartRate", rates[0], rates[1], err)
+		}
+	}
+}
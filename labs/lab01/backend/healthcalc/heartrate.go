@@ -0,0 +1,92 @@
+package healthcalc
+
+import (
+	"errors"
+	"math"
+
+	"lab01/calculator"
+)
+
+// ErrInvalidHeartRate is returned for heart rates outside physiological ranges
+var ErrInvalidHeartRate = errors.New("invalid heart rate: maximum must be between 100 and 230 bpm, resting between 30 and 120 bpm and below maximum")
+
+// MaxHRFormula selects an age-predicted maximum heart rate equation
+type MaxHRFormula int
+
+const (
+	// Tanaka is 208 - 0.7 × age (Tanaka, Monahan and Seals, 2001)
+	Tanaka MaxHRFormula = iota
+	// Fox is the traditional 220 - age
+	Fox
+)
+
+var maxHRFormulas = map[MaxHRFormula]*calculator.Expr{
+	Tanaka: mustCompile("208 - 0.7*age"),
+	Fox:    mustCompile("220 - age"),
+}
+
+// MaxHeartRate returns the age-predicted maximum heart rate in bpm, rounded
+func MaxHeartRate(age int, formula MaxHRFormula) (int, error) {
+	if !validAge(age) {
+		return 0, ErrInvalidAge
+	}
+	expr, ok := maxHRFormulas[formula]
+	if !ok {
+		return 0, ErrUnknownFormula
+	}
+	v, err := evaluate(expr, map[string]float64{"age": float64(age)})
+	return int(math.Round(v)), err
+}
+
+// Zone is a training zone as an inclusive bpm range
+type Zone struct {
+	Number int
+	Name   string
+	Min    int
+	Max    int
+}
+
+// zoneBounds are the five-zone model's lower bounds as a fraction of max
+// heart rate, or of heart-rate reserve with a resting rate
+var zoneBounds = []struct {
+	name string
+	low  float64
+}{
+	{"recovery", 0.5},
+	{"endurance", 0.6},
+	{"aerobic", 0.7},
+	{"threshold", 0.8},
+	{"maximum", 0.9},
+}
+
+// karvonen is resting + pct × (maximum - resting); with resting 0 it is pct × maximum
+var karvonen = mustCompile("resting + pct*(maximum - resting)")
+
+// HeartRateZones returns the five training zones for maxHR. With a resting
+// rate the zones use heart-rate reserve (the Karvonen method); pass 0 to
+// use plain percentages of maxHR. Each zone ends one bpm below the next.
+func HeartRateZones(maxHR, restingHR int) ([]Zone, error) {
+	if maxHR < 100 || maxHR > 230 {
+		return nil, ErrInvalidHeartRate
+	}
+	if restingHR != 0 && (restingHR < 30 || restingHR > 120 || restingHR >= maxHR) {
+		return nil, ErrInvalidHeartRate
+	}
+
+	bound := func(pct float64) (int, error) {
+		v, err := evaluate(karvonen, map[string]float64{"resting": float64(restingHR), "pct": pct, "maximum": float64(maxHR)})
+		return int(math.Round(v)), err
+	}
+	zones := make([]Zone, len(zoneBounds))
+	for i, z := range zoneBounds {
+		low, err := bound(z.low)
+		if err != nil {
+			return nil, err
+		}
+		zones[i] = Zone{Number: i + 1, Name: z.name, Min: low, Max: maxHR}
+		if i > 0 {
+			zones[i-1].Max = low - 1
+		}
+	}
+	return zones, nil
+}
@@ -0,0 +1,32 @@
+package healthcalc
+
+import "errors"
+
+// ErrInvalidExercise is returned for negative or implausible exercise time
+var ErrInvalidExercise = errors.New("invalid exercise time: must be between 0 and 1440 minutes")
+
+// Water intake guideline: 35 ml per kg of body weight, plus 350 ml for
+// every 30 minutes of exercise (about 12 oz, as ACSM suggests)
+const (
+	waterPerKilogram      = 35
+	waterPer30MinExercise = 350
+)
+
+var waterFormula = mustCompile("weight*perKg + exercise/30*perSession")
+
+// WaterIntake returns a daily drinking water target
+func WaterIntake(weight Kilograms, exerciseMinutes int) (Milliliters, error) {
+	if !validWeight(weight) {
+		return 0, ErrInvalidWeight
+	}
+	if exerciseMinutes < 0 || exerciseMinutes > 24*60 {
+		return 0, ErrInvalidExercise
+	}
+	v, err := evaluate(waterFormula, map[string]float64{
+		"weight":     float64(weight),
+		"exercise":   float64(exerciseMinutes),
+		"perKg":      waterPerKilogram,
+		"perSession": waterPer30MinExercise,
+	})
+	return Milliliters(v), err
+}
@@ -0,0 +1,179 @@
+package healthcalc
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"lab01/calculator"
+)
+
+// Exact conversion factors: the international pound and inch, and the US
+// customary fluid ounce
+const (
+	kilogramsPerPound     = 0.45359237
+	centimetersPerInch    = 2.54
+	millilitersPerFluidOz = 29.5735295625
+)
+
+// Typed amounts. Each is its own type, so passing Pounds where Kilograms
+// are expected does not compile; convert explicitly instead.
+type (
+	Kilograms   float64
+	Pounds      float64
+	Centimeters float64
+	Inches      float64
+	Milliliters float64
+	FluidOunces float64
+)
+
+// Kilograms converts pounds to kilograms
+func (p Pounds) Kilograms() Kilograms {
+	return Kilograms(calculator.Multiply(float64(p), kilogramsPerPound))
+}
+
+// Pounds converts kilograms to pounds
+func (k Kilograms) Pounds() Pounds {
+	v, _ := calculator.Divide(float64(k), kilogramsPerPound)
+	return Pounds(v)
+}
+
+// Centimeters converts inches to centimeters
+func (i Inches) Centimeters() Centimeters {
+	return Centimeters(calculator.Multiply(float64(i), centimetersPerInch))
+}
+
+// Inches converts centimeters to inches
+func (c Centimeters) Inches() Inches {
+	v, _ := calculator.Divide(float64(c), centimetersPerInch)
+	return Inches(v)
+}
+
+// Milliliters converts US fluid ounces to milliliters
+func (f FluidOunces) Milliliters() Milliliters {
+	return Milliliters(calculator.Multiply(float64(f), millilitersPerFluidOz))
+}
+
+// FluidOunces converts milliliters to US fluid ounces
+func (m Milliliters) FluidOunces() FluidOunces {
+	v, _ := calculator.Divide(float64(m), millilitersPerFluidOz)
+	return FluidOunces(v)
+}
+
+// Predefined unit errors
+var (
+	ErrUnknownUnit       = errors.New("unknown unit")
+	ErrIncompatibleUnits = errors.New("incompatible units")
+)
+
+// Unit is a unit of a Quantity known only at runtime, e.g. from user input
+type Unit string
+
+// Supported units
+const (
+	Kilogram   Unit = "kg"
+	Pound      Unit = "lb"
+	Centimeter Unit = "cm"
+	Inch       Unit = "in"
+	Milliliter Unit = "ml"
+	FluidOunce Unit = "fl oz"
+)
+
+// Dimension is what a unit measures
+type Dimension string
+
+// Dimensions of the supported units
+const (
+	Mass   Dimension = "mass"
+	Length Dimension = "length"
+	Volume Dimension = "volume"
+)
+
+// units maps each unit to its dimension and its size in the dimension's
+// base unit (kg, cm or ml)
+var units = map[Unit]struct {
+	dim    Dimension
+	factor float64
+}{
+	Kilogram:   {Mass, 1},
+	Pound:      {Mass, kilogramsPerPound},
+	Centimeter: {Length, 1},
+	Inch:       {Length, centimetersPerInch},
+	Milliliter: {Volume, 1},
+	FluidOunce: {Volume, millilitersPerFluidOz},
+}
+
+// Dimension returns what u measures, or "" for an unknown unit
+func (u Unit) Dimension() Dimension {
+	return units[u].dim
+}
+
+// Quantity is a value with a unit chosen at runtime
+type Quantity struct {
+	Value float64
+	Unit  Unit
+}
+
+func (q Quantity) String() string {
+	return strconv.FormatFloat(q.Value, 'f', -1, 64) + " " + string(q.Unit)
+}
+
+// ParseQuantity parses "70 kg", "5.5in" or "8 fl oz"
+func ParseQuantity(s string) (Quantity, error) {
+	s = strings.TrimSpace(s)
+	end := strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= '0' && r <= '9' || r == '.' || r == '-' || r == '+')
+	})
+	if end <= 0 {
+		return Quantity{}, fmt.Errorf("invalid quantity %q: missing number or unit", s)
+	}
+	value, err := calculator.StringToFloat(s[:end])
+	if err != nil {
+		return Quantity{}, fmt.Errorf("invalid quantity %q: %w", s, err)
+	}
+	unit := Unit(strings.Join(strings.Fields(strings.ToLower(s[end:])), " "))
+	if _, ok := units[unit]; !ok {
+		return Quantity{}, fmt.Errorf("%w %q", ErrUnknownUnit, unit)
+	}
+	return Quantity{Value: value, Unit: unit}, nil
+}
+
+// Convert returns q in unit to. Converting between dimensions, such as
+// kg to cm, fails with ErrIncompatibleUnits.
+func (q Quantity) Convert(to Unit) (Quantity, error) {
+	from, ok := units[q.Unit]
+	if !ok {
+		return Quantity{}, fmt.Errorf("%w %q", ErrUnknownUnit, q.Unit)
+	}
+	target, ok := units[to]
+	if !ok {
+		return Quantity{}, fmt.Errorf("%w %q", ErrUnknownUnit, to)
+	}
+	if from.dim != target.dim {
+		return Quantity{}, fmt.Errorf("%w: %s (%s) to %s (%s)", ErrIncompatibleUnits, q.Unit, from.dim, to, target.dim)
+	}
+	if q.Unit == to {
+		return q, nil
+	}
+	v, _ := calculator.Divide(calculator.Multiply(q.Value, from.factor), target.factor)
+	return Quantity{Value: v, Unit: to}, nil
+}
+
+// Kilograms returns a mass quantity as Kilograms
+func (q Quantity) Kilograms() (Kilograms, error) {
+	v, err := q.Convert(Kilogram)
+	return Kilograms(v.Value), err
+}
+
+// Centimeters returns a length quantity as Centimeters
+func (q Quantity) Centimeters() (Centimeters, error) {
+	v, err := q.Convert(Centimeter)
+	return Centimeters(v.Value), err
+}
+
+// Milliliters returns a volume quantity as Milliliters
+func (q Quantity) Milliliters() (Milliliters, error) {
+	v, err := q.Convert(Milliliter)
+	return Milliliters(v.Value), err
+}
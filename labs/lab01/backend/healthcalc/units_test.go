@@ -0,0 +1,85 @@
+package healthcalc
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestTypedConversions(t *testing.T) {
+	tests := []struct {
+		name     string
+		got      float64
+		expected float64
+	}{
+		{"1 lb in kg", float64(Pounds(1).Kilograms()), 0.45359237},
+		{"100 kg in lb", float64(Kilograms(100).Pounds()), 220.46226218},
+		{"12 in in cm", float64(Inches(12).Centimeters()), 30.48},
+		{"180 cm in in", float64(Centimeters(180).Inches()), 70.86614173},
+		{"1 fl oz in ml", float64(FluidOunces(1).Milliliters()), 29.5735295625},
+		{"1000 ml in fl oz", float64(Milliliters(1000).FluidOunces()), 33.81402270},
+		{"round trip", float64(Kilograms(72.5).Pounds().Kilograms()), 72.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if math.Abs(tt.got-tt.expected) > 1e-8 {
+				t.Errorf("got %v, want %v", tt.got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Quantity
+		wantErr  error
+	}{
+		{"70 kg", Quantity{70, Kilogram}, nil},
+		{"154.5lb", Quantity{154.5, Pound}, nil},
+		{" 8  FL  OZ ", Quantity{8, FluidOunce}, nil},
+		{"180 cm", Quantity{180, Centimeter}, nil},
+		{"70 stone", Quantity{}, ErrUnknownUnit},
+		{"kg", Quantity{}, nil},
+		{"70", Quantity{}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseQuantity(tt.input)
+			if tt.expected == (Quantity{}) {
+				if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+					t.Errorf("ParseQuantity(%q) error = %v, want %v", tt.input, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.expected {
+				t.Errorf("ParseQuantity(%q) = %v, %v, want %v", tt.input, got, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestQuantityConvert(t *testing.T) {
+	q := Quantity{Value: 154, Unit: Pound}
+	kg, err := q.Kilograms()
+	if err != nil || math.Abs(float64(kg)-69.85322498) > 1e-8 {
+		t.Errorf("154 lb = %v kg, %v", kg, err)
+	}
+
+	ml, err := Quantity{Value: 16, Unit: FluidOunce}.Convert(Milliliter)
+	if err != nil || math.Abs(ml.Value-473.176473) > 1e-6 || ml.Unit != Milliliter {
+		t.Errorf("16 fl oz = %v, %v", ml, err)
+	}
+
+	if _, err := q.Centimeters(); !errors.Is(err, ErrIncompatibleUnits) {
+		t.Errorf("lb to cm error = %v, want ErrIncompatibleUnits", err)
+	}
+	if _, err := (Quantity{Value: 1, Unit: "stone"}).Convert(Kilogram); !errors.Is(err, ErrUnknownUnit) {
+		t.Errorf("stone to kg error = %v, want ErrUnknownUnit", err)
+	}
+	if Inch.Dimension() != Length || Unit("x").Dimension() != "" {
+		t.Error("Dimension() mismatch")
+	}
+}
@@ -0,0 +1,20 @@
+#!/bin/bash
+
+# Vendors swagger-ui-dist into the backend, where it is embedded in the
+# binary and served by /api/v1/docs. Usage: scripts/vendor-swagger-ui.sh [version]
+
+set -euo pipefail
+
+VERSION="${1:-5.17.14}"
+DEST="$(cd "$(dirname "$0")/.." && pwd)/backend/internal/openapi/ui/dist"
+
+tmp="$(mktemp -d)"
+trap 'rm -rf "$tmp"' EXIT
+
+# npm pack verifies the tarball against the registry's integrity hash
+(cd "$tmp" && npm pack --silent "swagger-ui-dist@${VERSION}" >/dev/null && tar xzf swagger-ui-dist-*.tgz)
+
+mkdir -p "$DEST"
+cp "$tmp/package/swagger-ui.css" "$tmp/package/swagger-ui-bundle.js" "$tmp/package/LICENSE" "$DEST/"
+echo "$VERSION" > "$DEST/VERSION"
+echo "✅ Vendored swagger-ui-dist ${VERSION} into ${DEST}"